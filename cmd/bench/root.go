@@ -0,0 +1,119 @@
+package bench
+
+import (
+	"draethos.io.com/internal"
+	"draethos.io.com/internal/bench"
+	context2 "draethos.io.com/internal/context"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/logging"
+	"draethos.io.com/internal/target"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"draethos.io.com/pkg/color"
+	"github.com/spf13/cobra"
+)
+
+type benchCommand struct {
+}
+
+func NewBenchCommand() interfaces.BuildCommand {
+	return benchCommand{}
+}
+
+func (b benchCommand) Build() *cobra.Command {
+	var benchCommand = &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark pipeline target",
+		Long:  "Run a pipeline with a generator source against its target, reporting throughput, flush latency percentiles and memory usage",
+		Example: `./draethos bench -f pipeline.yaml
+./draethos bench -f bench.yaml --profile prod --duration 5m`,
+		RunE: b.runE,
+	}
+
+	benchCommand.
+		PersistentFlags().
+		StringP(
+			"file",
+			"f",
+			"",
+			"pipeline file to be benchmarked")
+
+	benchCommand.
+		PersistentFlags().
+		StringP(
+			"profile",
+			"",
+			"",
+			"profile merged on top of the pipeline file")
+
+	benchCommand.
+		PersistentFlags().
+		DurationP(
+			"duration",
+			"d",
+			bench.DurationDefault,
+			"stop the benchmark after the duration when the generator has no count")
+
+	return benchCommand
+}
+
+func (benchCommand) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	configBuilder := internal.NewConfigBuilder()
+
+	if value, err := cmd.Flags().GetString("file"); err == nil {
+		configBuilder.SetFile(value)
+	}
+
+	if value, err := cmd.Flags().GetString("profile"); err == nil {
+		configBuilder.SetProfile(value)
+	}
+
+	config, err := configBuilder.Build()
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to load pipeline: %s", err.Error()))
+	}
+
+	if err = logging.Configure(*config, configBuilder.GetFile()); err != nil {
+		return err
+	}
+
+	if config.Stream.Instance.Source.Type != context2.GeneratorSource {
+		return errors.New(fmt.Sprintf("bench requires a %s source, got %s",
+			context2.GeneratorSource, config.Stream.Instance.Source.Type))
+	}
+
+	duration, _ := cmd.Flags().GetDuration("duration")
+
+	fmt.Println(fmt.Sprintf("%sbenchmarking target %s for up to %s%s", color.Green, config.Stream.Instance.Target.Type, duration, color.Reset))
+
+	report, err := bench.Run(*config, duration)
+	printReport(config.Stream.Instance.Target.Type, report)
+
+	return err
+}
+
+func printReport(targetType string, report bench.Report) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "target\t%s\n", targetType)
+	fmt.Fprintf(writer, "elapsed\t%s\n", report.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(writer, "events\t%d\n", report.Events)
+	fmt.Fprintf(writer, "throughput\t%.1f events/s\n", report.Throughput())
+	fmt.Fprintf(writer, "flushes\t%d, %.1f events per flush, %d failed\n", len(report.Flushes), report.AverageFlushSize(), report.FlushErrors)
+	fmt.Fprintf(writer, "flush latency\tp50 %s, p90 %s, p99 %s, max %s\n",
+		latency(report.Percentile(50)), latency(report.Percentile(90)), latency(report.Percentile(99)), latency(report.Percentile(100)))
+	fmt.Fprintf(writer, "memory\tpeak heap %s, peak sys %s, allocated %s, %d gc cycle(s)\n",
+		target.LenReadable(report.PeakHeap, 2), target.LenReadable(report.PeakSys, 2), target.LenReadable(report.TotalAlloc, 2), report.NumGC)
+
+	writer.Flush()
+}
+
+func latency(duration time.Duration) string {
+	return duration.Round(10 * time.Microsecond).String()
+}
@@ -0,0 +1,187 @@
+package connectors
+
+import (
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/schema"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"draethos.io.com/pkg/color"
+	"github.com/spf13/cobra"
+)
+
+var kinds = []schema.ConnectorKind{schema.SourceConnector, schema.TargetConnector, schema.CodecConnector, schema.EncodingConnector}
+
+type connectorsCommand struct {
+}
+
+func NewConnectorsCommand() interfaces.BuildCommand {
+	return connectorsCommand{}
+}
+
+func (c connectorsCommand) Build() *cobra.Command {
+	var connectorsCommand = &cobra.Command{
+		Use:   "connectors [name]",
+		Short: "Describe connectors",
+		Long:  "List available sources, targets, codecs and encodings, or describe the settings, defaults and an example of the connector informed",
+		Example: `./draethos connectors
+./draethos connectors pgsql
+./draethos connectors kafka --kind target`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: c.runE,
+	}
+
+	connectorsCommand.
+		PersistentFlags().
+		StringP(
+			"kind",
+			"k",
+			"",
+			"connector kind: source, target, codec or encoding")
+
+	return connectorsCommand
+}
+
+func (c connectorsCommand) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	selected := kinds
+	if value, _ := cmd.Flags().GetString("kind"); value != "" {
+		kind := schema.ConnectorKind(value)
+		if _, ok := kindTitle(kind); !ok {
+			return errors.New(fmt.Sprintf("invalid kind %s, use source, target, codec or encoding", value))
+		}
+		selected = []schema.ConnectorKind{kind}
+	}
+
+	if len(args) == 0 {
+		c.list(selected)
+		return nil
+	}
+
+	found := 0
+	for _, kind := range selected {
+		if connector, ok := schema.Lookup(kind, args[0]); ok {
+			if err := c.describe(connector); err != nil {
+				return err
+			}
+			found++
+		}
+	}
+
+	if found == 0 {
+		return errors.New(fmt.Sprintf("connector %s not found, run draethos connectors to list the available ones", args[0]))
+	}
+
+	return nil
+}
+
+func (connectorsCommand) list(selected []schema.ConnectorKind) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	for _, kind := range selected {
+		title, _ := kindTitle(kind)
+		fmt.Fprintln(writer, fmt.Sprintf("%s%s%s", color.Green, title, color.Reset))
+
+		for _, connector := range schema.Connectors(kind) {
+			fmt.Fprintln(writer, fmt.Sprintf("  %s\t%s", connector.Name, connector.Description))
+		}
+
+		fmt.Fprintln(writer)
+	}
+
+	writer.Flush()
+}
+
+func (connectorsCommand) describe(connector schema.Connector) error {
+	fmt.Println(fmt.Sprintf("%s%s %s%s", color.Green, connector.Name, connector.Kind, color.Reset))
+	fmt.Println(fmt.Sprintf("  %s", connector.Description))
+	fmt.Println()
+
+	var required, optional []schema.Setting
+	for _, setting := range connector.Settings() {
+		if setting.Field.Required {
+			required = append(required, setting)
+		} else {
+			optional = append(optional, setting)
+		}
+	}
+
+	printSettings("Required settings", required)
+	printSettings("Optional settings", optional)
+
+	example, err := connector.Example()
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to render %s example: %s", connector.Name, err.Error()))
+	}
+
+	fmt.Println(fmt.Sprintf("%sExample%s", color.Yellow, color.Reset))
+	for _, line := range strings.Split(strings.TrimRight(example, "\n"), "\n") {
+		fmt.Println(fmt.Sprintf("  %s", line))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func printSettings(title string, settings []schema.Setting) {
+	if len(settings) == 0 {
+		return
+	}
+
+	fmt.Println(fmt.Sprintf("%s%s%s", color.Yellow, title, color.Reset))
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	for _, setting := range settings {
+		fmt.Fprintln(writer, fmt.Sprintf("  %s\t%s\t%s", setting.Path, setting.Field.Kind, settingDetails(setting.Field)))
+	}
+	writer.Flush()
+
+	fmt.Println()
+}
+
+func settingDetails(field schema.Field) string {
+	details := []string{field.Description}
+
+	if len(field.Enum) > 0 {
+		details = append(details, fmt.Sprintf("one of: %s", strings.Join(field.Enum, ", ")))
+	}
+
+	if field.Minimum != nil && field.Maximum != nil {
+		details = append(details, fmt.Sprintf("range: %v-%v", *field.Minimum, *field.Maximum))
+	} else if field.Minimum != nil {
+		details = append(details, fmt.Sprintf("minimum: %v", *field.Minimum))
+	} else if field.Maximum != nil {
+		details = append(details, fmt.Sprintf("maximum: %v", *field.Maximum))
+	}
+
+	if value, ok := field.Default.(string); ok {
+		details = append(details, fmt.Sprintf("default: %q", value))
+	} else if field.Default != nil {
+		details = append(details, fmt.Sprintf("default: %v", field.Default))
+	}
+
+	if field.AllowUnknown {
+		details = append(details, "accepts additional keys")
+	}
+
+	return strings.Join(details, ", ")
+}
+
+func kindTitle(kind schema.ConnectorKind) (string, bool) {
+	switch kind {
+	case schema.SourceConnector:
+		return "Sources", true
+	case schema.TargetConnector:
+		return "Targets", true
+	case schema.CodecConnector:
+		return "Codecs", true
+	case schema.EncodingConnector:
+		return "Encodings", true
+	}
+
+	return "", false
+}
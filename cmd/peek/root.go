@@ -0,0 +1,198 @@
+package peek
+
+import (
+	"draethos.io.com/internal"
+	context2 "draethos.io.com/internal/context"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/logging"
+	"draethos.io.com/internal/peek"
+	"draethos.io.com/internal/sample"
+	"draethos.io.com/internal/target"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"draethos.io.com/pkg/color"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/spf13/cobra"
+)
+
+type peekCommand struct {
+}
+
+func NewPeekCommand() interfaces.BuildCommand {
+	return peekCommand{}
+}
+
+func (p peekCommand) Build() *cobra.Command {
+	var peekCommand = &cobra.Command{
+		Use:   "peek",
+		Short: "Sample the pipeline source",
+		Long:  "Read events from the pipeline source and print them decoded with the inferred type of every field, nothing is written to the target and kafka offsets are not committed",
+		Example: `./draethos peek -f pipeline.yaml -n 20
+./draethos peek -f pipeline.yaml --from-beginning --timeout 1m`,
+		RunE: p.runE,
+	}
+
+	peekCommand.
+		PersistentFlags().
+		StringP(
+			"file",
+			"f",
+			"",
+			"pipeline file whose source is sampled")
+
+	peekCommand.
+		PersistentFlags().
+		StringP(
+			"profile",
+			"",
+			"",
+			"profile merged on top of the pipeline file")
+
+	peekCommand.
+		PersistentFlags().
+		IntP(
+			"number",
+			"n",
+			peek.LimitDefault,
+			"number of events to read")
+
+	peekCommand.
+		PersistentFlags().
+		DurationP(
+			"timeout",
+			"t",
+			peek.TimeoutDefault,
+			"stop waiting for events after the duration")
+
+	peekCommand.
+		PersistentFlags().
+		BoolP(
+			"from-beginning",
+			"",
+			false,
+			"read kafka topics from the earliest offset instead of waiting for new events")
+
+	return peekCommand
+}
+
+func (peekCommand) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	configBuilder := internal.NewConfigBuilder()
+
+	if value, err := cmd.Flags().GetString("file"); err == nil {
+		configBuilder.SetFile(value)
+	}
+
+	if value, err := cmd.Flags().GetString("profile"); err == nil {
+		configBuilder.SetProfile(value)
+	}
+
+	config, err := configBuilder.Build()
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to load pipeline: %s", err.Error()))
+	}
+
+	if err = logging.Configure(*config, configBuilder.GetFile()); err != nil {
+		return err
+	}
+
+	options := peek.Options{}
+	options.Limit, _ = cmd.Flags().GetInt("number")
+	options.Timeout, _ = cmd.Flags().GetDuration("timeout")
+	options.FromBeginning, _ = cmd.Flags().GetBool("from-beginning")
+
+	events, err := peek.Peek(*config, options)
+	if len(events) > 0 {
+		if printErr := printEvents(os.Stdout, config.Stream.Instance.Source.Type, config.Stream.Instance.Target, events); printErr != nil {
+			return printErr
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		fmt.Println(fmt.Sprintf("%sno events received from source %s%s", color.Yellow, config.Stream.Instance.Source.Type, color.Reset))
+	}
+
+	return nil
+}
+
+func printEvents(writer io.Writer, sourceType string, targetSpec specs.Target, events []peek.Event) error {
+	format := sample.JsonLFormat
+	if sourceType == context2.CsvSource {
+		format = sample.CsvFormat
+	}
+
+	data := sample.New(sourceType, format)
+	for i, event := range events {
+		content, err := json.MarshalIndent(event.Payload, "", "  ")
+		if err != nil {
+			return errors.New(fmt.Sprintf("failed to print event %s: %s", event.Key, err.Error()))
+		}
+
+		fmt.Fprintf(writer, "%s#%d key: %s%s\n%s\n", color.Green, i+1, event.Key, color.Reset, content)
+		data.Add(event.Payload, nil)
+	}
+
+	data.Complete()
+
+	fmt.Fprintf(writer, "\n%s%d event(s), fields:%s\n", color.Green, data.Records, color.Reset)
+
+	tab := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	column := columnDefinition(targetSpec)
+	if column != nil {
+		fmt.Fprintf(tab, "FIELD\tTYPE\tSEEN\t%s COLUMN\n", targetSpec.Type)
+	} else {
+		fmt.Fprintf(tab, "FIELD\tTYPE\tSEEN\n")
+	}
+
+	for _, field := range data.Fields {
+		if column != nil {
+			fmt.Fprintf(tab, "%s\t%s\t%d/%d\t%s\n", field.Name, field.Kind(), field.Count, data.Records, column(field.Name, field.Value))
+			continue
+		}
+		fmt.Fprintf(tab, "%s\t%s\t%d/%d\n", field.Name, field.Kind(), field.Count, data.Records)
+	}
+	tab.Flush()
+
+	for _, field := range data.Fields {
+		for _, suggestion := range field.Suggestions {
+			fmt.Fprintf(writer, "%s%s: %s%s\n", color.Yellow, field.Name, suggestion, color.Reset)
+		}
+	}
+
+	return nil
+}
+
+// columnDefinition returns how sql targets create the column of a field from
+// the first value received.
+func columnDefinition(targetSpec specs.Target) func(name string, value interface{}) string {
+	var definition func(value interface{}, key bool) string
+	switch targetSpec.Type {
+	case context2.PgSqlTarget:
+		definition = target.PgsqlColumnDefinition
+	case context2.MySqlTarget:
+		definition = target.MysqlColumnDefinition
+	default:
+		return nil
+	}
+
+	// invalid specs are reported when the target is created
+	databaseSpecs, _ := targetSpec.TargetSpecs.Database()
+
+	return func(name string, value interface{}) string {
+		if name == databaseSpecs.KeyColumnName {
+			return target.KeyColumnDefinition
+		}
+
+		return definition(value, false)
+	}
+}
@@ -0,0 +1,243 @@
+package replay
+
+import (
+	"draethos.io.com/internal"
+	context2 "draethos.io.com/internal/context"
+	"draethos.io.com/internal/dlq"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/logging"
+	"draethos.io.com/internal/replay"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"draethos.io.com/pkg/color"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/spf13/cobra"
+)
+
+type replayCommand struct {
+}
+
+func NewReplayCommand() interfaces.BuildCommand {
+	return replayCommand{}
+}
+
+func (r replayCommand) Build() *cobra.Command {
+	var replayCommand = &cobra.Command{
+		Use:   "replay",
+		Short: "Replay dead-lettered events",
+		Long:  "Read the envelopes written to the pipeline dlq, or to a jsonl file, and send the original events to the pipeline target again",
+		Example: `./draethos replay -f pipeline.yaml
+./draethos replay -f pipeline.yaml --error-type target --since 24h --failed-output failed.jsonl
+./draethos replay -f pipeline.yaml --input failed.jsonl --dry-run`,
+		RunE: r.runE,
+	}
+
+	replayCommand.
+		PersistentFlags().
+		StringP(
+			"file",
+			"f",
+			"",
+			"pipeline file whose dlq is replayed")
+
+	replayCommand.
+		PersistentFlags().
+		StringP(
+			"profile",
+			"",
+			"",
+			"profile merged on top of the pipeline file")
+
+	replayCommand.
+		PersistentFlags().
+		StringP(
+			"input",
+			"i",
+			"",
+			"jsonl file with the envelopes to replay instead of the pipeline dlq")
+
+	replayCommand.
+		PersistentFlags().
+		StringP(
+			"error-type",
+			"",
+			"",
+			fmt.Sprintf("replay only events rejected with the error type (%s, %s)", dlq.DecodeError, dlq.TargetError))
+
+	replayCommand.
+		PersistentFlags().
+		StringP(
+			"since",
+			"",
+			"",
+			"replay only events failed after the time, RFC3339 or a duration ago such as 24h")
+
+	replayCommand.
+		PersistentFlags().
+		StringP(
+			"until",
+			"",
+			"",
+			"replay only events failed before the time, RFC3339 or a duration ago such as 1h")
+
+	replayCommand.
+		PersistentFlags().
+		BoolP(
+			"dry-run",
+			"",
+			false,
+			"read and filter the envelopes without sending them to the target")
+
+	replayCommand.
+		PersistentFlags().
+		StringP(
+			"failed-output",
+			"o",
+			"",
+			"jsonl file where the events failing again are written")
+
+	return replayCommand
+}
+
+func (replayCommand) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	configBuilder := internal.NewConfigBuilder()
+
+	if value, err := cmd.Flags().GetString("file"); err == nil {
+		configBuilder.SetFile(value)
+	}
+
+	if value, err := cmd.Flags().GetString("profile"); err == nil {
+		configBuilder.SetProfile(value)
+	}
+
+	config, err := configBuilder.Build()
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to load pipeline: %s", err.Error()))
+	}
+
+	if err = logging.Configure(*config, configBuilder.GetFile()); err != nil {
+		return err
+	}
+
+	options := replay.Options{}
+	options.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	options.ErrorType, _ = cmd.Flags().GetString("error-type")
+	if options.ErrorType != "" && options.ErrorType != dlq.DecodeError && options.ErrorType != dlq.TargetError {
+		return errors.New(fmt.Sprintf("invalid error type %s, expected one of: %s, %s", options.ErrorType, dlq.DecodeError, dlq.TargetError))
+	}
+
+	if options.Since, err = parseTime(cmd, "since"); err != nil {
+		return err
+	}
+
+	if options.Until, err = parseTime(cmd, "until"); err != nil {
+		return err
+	}
+
+	instance := config.Stream.Instance
+
+	sourceCodec, err := context2.NewCodecContext(instance.Source.Codec, instance.Source.CodecConfigurations)
+	if err != nil {
+		return err
+	}
+
+	var reader replay.Reader
+	var codec interfaces.CodecInterface
+	origin, _ := cmd.Flags().GetString("input")
+	if origin != "" {
+		codec, _ = context2.NewCodecContext(context2.JsonCodec, nil)
+		reader, err = replay.NewFileReader(origin)
+	} else {
+		if codec, err = context2.NewCodecContext(instance.Dlq.TargetSpecs.Codec, instance.Dlq.TargetSpecs.CodecConfigurations); err != nil {
+			return err
+		}
+		origin = replay.Describe(instance.Dlq)
+		reader, err = replay.NewReader(instance.Dlq, codec)
+		codec = replay.RecordCodec(instance.Dlq, codec)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	if failedOutput, _ := cmd.Flags().GetString("failed-output"); failedOutput != "" {
+		file, err := os.Create(failedOutput)
+		if err != nil {
+			return errors.New(fmt.Sprintf("failed to create %s: %s", failedOutput, err.Error()))
+		}
+
+		defer file.Close()
+		options.Failed = file
+	}
+
+	target, err := context2.NewTargetContext(instance.Target)
+	if err != nil {
+		return err
+	}
+
+	if !options.DryRun {
+		if err = target.Initialize(); err != nil {
+			return errors.New(fmt.Sprintf("failed to initialize target %s: %s", instance.Target.Type, err.Error()))
+		}
+
+		defer target.Close()
+	}
+
+	fmt.Println(fmt.Sprintf("%sreplaying events from %s to target %s%s", color.Green, origin, instance.Target.Type, color.Reset))
+
+	report, err := replay.NewReplayer(reader, codec, sourceCodec, target, options).Run()
+	printReport(report, options.DryRun)
+	if err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		cmd.SilenceErrors = true
+		return errors.New(fmt.Sprintf("%d event(s) failed again", report.Failed))
+	}
+
+	return nil
+}
+
+func printReport(report replay.Report, dryRun bool) {
+	replayed := "replayed"
+	if dryRun {
+		replayed = "would be replayed"
+	}
+
+	fmt.Println(fmt.Sprintf("%s%d read, %d skipped by filters, %d %s%s",
+		color.Green, report.Read, report.Skipped, report.Replayed, replayed, color.Reset))
+
+	if report.Failed == 0 {
+		return
+	}
+
+	fmt.Println(fmt.Sprintf("%s%d failed again:%s", color.Red, report.Failed, color.Reset))
+	for _, failure := range report.Failures() {
+		fmt.Println(fmt.Sprintf("%s  %d x %s%s", color.Yellow, failure.Count, failure.Error, color.Reset))
+	}
+}
+
+// parseTime accepts an RFC3339 time or a duration counted back from now.
+func parseTime(cmd *cobra.Command, name string) (time.Time, error) {
+	value, _ := cmd.Flags().GetString(name)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := specs.ParseTime(value, time.Now())
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("invalid --%s %q, expected RFC3339 such as 2006-01-02T15:04:05Z or a duration such as 24h", name, value))
+	}
+
+	return parsed, nil
+}
@@ -1,15 +1,23 @@
 package cmd
 
 import (
+	"draethos.io.com/cmd/bench"
+	"draethos.io.com/cmd/connectors"
+	"draethos.io.com/cmd/peek"
+	"draethos.io.com/cmd/replay"
 	"draethos.io.com/cmd/scaffold"
+	"draethos.io.com/cmd/schema"
 	"draethos.io.com/cmd/start"
+	"draethos.io.com/cmd/test"
+	"draethos.io.com/cmd/validate"
+	"draethos.io.com/internal/logging"
 	"fmt"
 	"os"
 	"syscall"
 
 	"draethos.io.com/pkg/color"
+	"draethos.io.com/pkg/streams/specs"
 	"github.com/spf13/cobra"
-	"go.uber.org/zap"
 )
 
 const (
@@ -22,8 +30,9 @@ const (
 )
 
 func Execute() {
-	logger, _ := zap.NewDevelopment()
-	zap.ReplaceGlobals(logger)
+	if err := logging.Configure(specs.Stream{}, ""); err != nil {
+		panic(err)
+	}
 
 	var release = "latest"
 	if value, ok := syscall.Getenv("VERSION"); ok {
@@ -38,6 +47,13 @@ func Execute() {
 
 	rootCmd.AddCommand(start.NewStartCommand().Build())
 	rootCmd.AddCommand(scaffold.NewScaffoldCommand().Build())
+	rootCmd.AddCommand(validate.NewValidateCommand().Build())
+	rootCmd.AddCommand(schema.NewSchemaCommand().Build())
+	rootCmd.AddCommand(connectors.NewConnectorsCommand().Build())
+	rootCmd.AddCommand(replay.NewReplayCommand().Build())
+	rootCmd.AddCommand(peek.NewPeekCommand().Build())
+	rootCmd.AddCommand(bench.NewBenchCommand().Build())
+	rootCmd.AddCommand(test.NewTestCommand().Build())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(fmt.Sprintf("%s%s%s", color.Red, err.Error(), color.Reset))
@@ -0,0 +1,368 @@
+package scaffold
+
+import (
+	"bufio"
+	"draethos.io.com/internal/schema"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"draethos.io.com/pkg/color"
+	"gopkg.in/yaml.v3"
+)
+
+// wizard asks the questions needed to build a pipeline, the connectors and
+// their settings are read from the schema catalog.
+type wizard struct {
+	reader *bufio.Reader
+	writer io.Writer
+}
+
+func newWizard(in io.Reader, out io.Writer) *wizard {
+	return &wizard{reader: bufio.NewReader(in), writer: out}
+}
+
+func (w *wizard) run() (*yaml.Node, error) {
+	document := schema.Document()
+	streamField, _ := document.Field("stream")
+	instanceField, _ := streamField.Field("instance")
+
+	stream := mapping()
+
+	for _, name := range []string{"port"} {
+		field, _ := streamField.Field(name)
+		value, err := w.field(name, field, true)
+		if err != nil {
+			return nil, err
+		}
+		appendNode(stream, name, value)
+	}
+
+	for _, name := range []string{"healthCheck", "metrics"} {
+		object, _ := streamField.Field(name)
+		endpoint, _ := object.Field("endpoint")
+		value, err := w.field(fmt.Sprintf("%s.endpoint", name), endpoint, true)
+		if err != nil {
+			return nil, err
+		}
+		appendNode(stream, name, mappingOf("endpoint", value))
+	}
+
+	instance := mapping()
+	for _, name := range []string{"source", "target", "dlq"} {
+		field, _ := instanceField.Field(name)
+
+		if !field.Required {
+			typeField, _ := field.Field("type")
+			enabled, err := w.confirm(fmt.Sprintf("configure %s (%s)", name, typeField.Description), false)
+			if err != nil {
+				return nil, err
+			}
+
+			if !enabled {
+				continue
+			}
+		}
+
+		connector, err := w.connector(name, field)
+		if err != nil {
+			return nil, err
+		}
+		appendNode(instance, name, connector)
+	}
+
+	appendNode(stream, "instance", instance)
+
+	root := mappingOf("stream", stream)
+	if problems := schema.ValidateNode(root, nil); len(problems) > 0 {
+		return nil, errors.New(fmt.Sprintf("generated pipeline is invalid:\n%s", problems.Error()))
+	}
+
+	return root, nil
+}
+
+func (w *wizard) connector(name string, field schema.Field) (*yaml.Node, error) {
+	typeField, _ := field.Field("type")
+	connectorType, err := w.choose(name, typeField.Description, typeField.Enum, "")
+	if err != nil {
+		return nil, err
+	}
+
+	node := mapping()
+	appendNode(node, "type", scalar(connectorType))
+
+	if codecField, ok := field.Field("codec"); ok {
+		codec, err := w.choose(fmt.Sprintf("%s.codec", name), codecField.Description, codecField.Enum, fmt.Sprint(codecField.Default))
+		if err != nil {
+			return nil, err
+		}
+		appendNode(node, "codec", scalar(codec))
+
+		configurations, err := w.fields(fmt.Sprintf("%s.%s", name, schema.CodecConfigurationsKey), schema.CodecConfigurations(codec).Fields, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(configurations.Content) > 0 {
+			appendNode(node, schema.CodecConfigurationsKey, configurations)
+		}
+	}
+
+	connector, _ := schema.Lookup(field.Connectors, connectorType)
+	w.printf("%s%s %s: %s%s\n", color.Green, connector.Name, connector.Kind, connector.Description, color.Reset)
+
+	optional, err := w.confirm(fmt.Sprintf("configure optional %s settings", connector.Name), false)
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := w.fields(fmt.Sprintf("%s.specs", name), connector.Specs, optional)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(specs.Content) > 0 {
+		appendNode(node, "specs", specs)
+	}
+
+	return node, nil
+}
+
+func (w *wizard) fields(path string, fields []schema.Field, optional bool) (*yaml.Node, error) {
+	node := mapping()
+	for _, field := range fields {
+		// the codec configurations follow the codec answered before them
+		if field.Name == schema.CodecConfigurationsKey {
+			field = schema.CodecConfigurations(scalarValue(node, "codec"))
+		}
+
+		if !field.Required && !optional && !hasRequired(field) {
+			continue
+		}
+
+		fieldPath := fmt.Sprintf("%s.%s", path, field.Name)
+		if field.Kind == schema.KindObject {
+			child, err := w.fields(fieldPath, field.Fields, optional)
+			if err != nil {
+				return nil, err
+			}
+
+			// objects made only of free keys, such as the generator template,
+			// are asked even when optional settings are skipped
+			if field.AllowUnknown && (optional || field.Required && len(child.Content) == 0) {
+				if err = w.additional(fieldPath, child); err != nil {
+					return nil, err
+				}
+			}
+
+			if len(child.Content) > 0 {
+				appendNode(node, field.Name, child)
+			}
+			continue
+		}
+
+		value, err := w.field(fieldPath, field, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if value != nil {
+			appendNode(node, field.Name, value)
+		}
+	}
+
+	return node, nil
+}
+
+// field asks for a single value until it is accepted. An empty answer keeps
+// the default, which is only written to the pipeline when explicit is set so
+// connectors keep applying their own defaults.
+func (w *wizard) field(path string, field schema.Field, explicit bool) (*yaml.Node, error) {
+	defaultValue := ""
+	if field.Default != nil {
+		defaultValue = fmt.Sprint(field.Default)
+	}
+
+	hints := make([]string, 0)
+	if len(field.Enum) > 0 {
+		hints = append(hints, fmt.Sprintf("one of: %s", strings.Join(field.Enum, ", ")))
+	} else if field.Example != nil {
+		hints = append(hints, fmt.Sprintf("e.g. %v", field.Example))
+	}
+
+	if !field.Required && defaultValue == "" {
+		hints = append(hints, "empty to skip")
+	}
+
+	prompt := fmt.Sprintf("%s, %s", path, field.Description)
+	if len(hints) > 0 {
+		prompt = fmt.Sprintf("%s (%s)", prompt, strings.Join(hints, ", "))
+	}
+
+	for {
+		answer, err := w.ask(prompt, defaultValue)
+		if err != nil {
+			return nil, err
+		}
+
+		if answer == "" && explicit {
+			answer = defaultValue
+		}
+
+		if answer == "" {
+			if field.Required && defaultValue == "" {
+				w.printf("%s%s is required%s\n", color.Yellow, path, color.Reset)
+				continue
+			}
+			return nil, nil
+		}
+
+		node, err := field.Parse(answer)
+		if err != nil {
+			w.printf("%s%s: %s%s\n", color.Yellow, path, err.Error(), color.Reset)
+			continue
+		}
+
+		return node, nil
+	}
+}
+
+func (w *wizard) additional(path string, node *yaml.Node) error {
+	for {
+		answer, err := w.ask(fmt.Sprintf("%s, additional key=value (empty to finish)", path), "")
+		if err != nil {
+			return err
+		}
+
+		if answer == "" {
+			return nil
+		}
+
+		data := strings.SplitN(answer, "=", 2)
+		if len(data) < 2 || strings.TrimSpace(data[0]) == "" {
+			w.printf("%sexpected key=value, got %s%s\n", color.Yellow, answer, color.Reset)
+			continue
+		}
+
+		appendNode(node, strings.TrimSpace(data[0]), scalar(strings.TrimSpace(data[1])))
+	}
+}
+
+func (w *wizard) choose(path string, description string, options []string, defaultValue string) (string, error) {
+	w.printf("%s, %s\n", path, description)
+	for i, option := range options {
+		w.printf("  %d) %s\n", i+1, option)
+	}
+
+	for {
+		answer, err := w.ask(path, defaultValue)
+		if err != nil {
+			return "", err
+		}
+
+		if answer == "" {
+			answer = defaultValue
+		}
+
+		if index, err := strconv.Atoi(answer); err == nil && index >= 1 && index <= len(options) {
+			return options[index-1], nil
+		}
+
+		for _, option := range options {
+			if option == answer {
+				return option, nil
+			}
+		}
+
+		w.printf("%sinvalid option %q, expected one of: %s%s\n", color.Yellow, answer, strings.Join(options, ", "), color.Reset)
+	}
+}
+
+func (w *wizard) confirm(question string, defaultValue bool) (bool, error) {
+	options := "y/N"
+	if defaultValue {
+		options = "Y/n"
+	}
+
+	for {
+		answer, err := w.ask(fmt.Sprintf("%s? [%s]", question, options), "")
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultValue, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+
+		w.printf("%sanswer y or n%s\n", color.Yellow, color.Reset)
+	}
+}
+
+func (w *wizard) ask(prompt string, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		w.printf("%s [%s]: ", prompt, strings.Trim(strconv.Quote(defaultValue), "\""))
+	} else {
+		w.printf("%s: ", prompt)
+	}
+
+	line, err := w.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errors.New("input closed before the pipeline was completed")
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+func (w *wizard) printf(format string, args ...interface{}) {
+	fmt.Fprintf(w.writer, format, args...)
+}
+
+func hasRequired(field schema.Field) bool {
+	if field.Required {
+		return true
+	}
+
+	for _, child := range field.Fields {
+		if hasRequired(child) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func scalarValue(node *yaml.Node, key string) string {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1].Value
+		}
+	}
+
+	return ""
+}
+
+func mapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func mappingOf(key string, value *yaml.Node) *yaml.Node {
+	node := mapping()
+	appendNode(node, key, value)
+
+	return node
+}
+
+func appendNode(node *yaml.Node, key string, value *yaml.Node) {
+	node.Content = append(node.Content, scalar(key), value)
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,70 @@
+package scaffold
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestShouldBuildPipelineFromAnswers(t *testing.T) {
+	answers := []string{
+		"", "", "",
+		"http", "", "n", "/events",
+		"sqs", "n", "https://sqs.us-east-1.amazonaws.com/000000000000/events",
+		"y", "pgsql", "y", "draethos", "events", "", "", "-1", "10", "", "127.0.0.1", "", "root", "secret", "disable",
+	}
+
+	root, err := newWizard(strings.NewReader(strings.Join(answers, "\n")+"\n"), ioutil.Discard).run()
+	if err != nil {
+		t.Fatalf("failed to run wizard: %v", err)
+	}
+
+	content, err := yaml.Marshal(root)
+	if err != nil {
+		t.Fatalf("failed to serialize pipeline: %v", err)
+	}
+
+	if string(content) != InteractivePipelineTest {
+		t.Errorf("unexpected pipeline:\n%s", content)
+	}
+}
+
+func TestShouldFailWhenInputEnds(t *testing.T) {
+	_, err := newWizard(strings.NewReader("9000\n"), ioutil.Discard).run()
+	if err == nil || !strings.Contains(err.Error(), "input closed") {
+		t.Errorf("expected input closed error, got: %v", err)
+	}
+}
+
+const (
+	InteractivePipelineTest = `stream:
+    port: 9000
+    healthCheck:
+        endpoint: /health
+    metrics:
+        endpoint: /metrics
+    instance:
+        source:
+            type: http
+            codec: json
+            specs:
+                endpoint: /events
+        target:
+            type: sqs
+            specs:
+                queueUrl: https://sqs.us-east-1.amazonaws.com/000000000000/events
+        dlq:
+            type: pgsql
+            specs:
+                database: draethos
+                table: events
+                batchSize: 10
+                configurations:
+                    host: 127.0.0.1
+                    user: root
+                    password: secret
+                    sslmode: disable
+`
+)
@@ -1,16 +1,20 @@
 package scaffold
 
 import (
+	"bytes"
 	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/sample"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 
 	"draethos.io.com/pkg/color"
 	"draethos.io.com/pkg/streams/specs"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 type scaffoldCommand struct {
@@ -39,6 +43,10 @@ func (s scaffoldCommand) Build() *cobra.Command {
 		RunE: s.runE,
 	}
 
+	scaffoldCommand.Example = fmt.Sprintf("%s\n\n./draethos generate --interactive --export-path ./share/pipeline.yaml"+
+		"\n\n./draethos generate --from-sample ./data/orders.jsonl --instance.target.type pgsql --ddl-path ./share/orders.sql",
+		scaffoldCommand.Example)
+
 	scaffoldCommand.
 		PersistentFlags().
 		StringP(
@@ -47,6 +55,38 @@ func (s scaffoldCommand) Build() *cobra.Command {
 			"pipeline.yaml",
 			"export path")
 
+	scaffoldCommand.
+		PersistentFlags().
+		BoolP(
+			"interactive",
+			"i",
+			false,
+			"prompt for the source, target and dlq settings instead of reading flags")
+
+	scaffoldCommand.
+		PersistentFlags().
+		StringP(
+			"from-sample",
+			"",
+			"",
+			"jsonl or csv file whose records are used to infer fields and the sql table")
+
+	scaffoldCommand.
+		PersistentFlags().
+		IntP(
+			"sample-size",
+			"",
+			sample.DefaultLimit,
+			"records read from the sample file")
+
+	scaffoldCommand.
+		PersistentFlags().
+		StringP(
+			"ddl-path",
+			"",
+			"",
+			"file where the suggested CREATE TABLE is written, printed when omitted")
+
 	scaffoldCommand.
 		PersistentFlags().
 		StringP(
@@ -245,6 +285,7 @@ func (s scaffoldCommand) runE(cmd *cobra.Command, args []string) error {
 				Target: specs.Target{
 					TargetSpecs: specs.TargetSpecs{
 						Configurations: make(map[string]interface{}),
+						Connector:      make(map[string]interface{}),
 					},
 				},
 				Dlq: specs.Target{
@@ -261,6 +302,14 @@ func (s scaffoldCommand) runE(cmd *cobra.Command, args []string) error {
 		path = value
 	}
 
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		return s.interactive(cmd, path)
+	}
+
+	if value, _ := cmd.Flags().GetString("from-sample"); value != "" {
+		return s.fromSample(cmd, path, value)
+	}
+
 	if value, err := cmd.Flags().GetString("port"); err == nil {
 		stream.Stream.Port = value
 	}
@@ -293,64 +342,64 @@ func (s scaffoldCommand) runE(cmd *cobra.Command, args []string) error {
 		stream.Stream.Instance.Target.Type = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.database"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.Database = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.database"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["database"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.bucket"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.Bucket = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.bucket"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["bucket"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.table"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.Table = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.table"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["table"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.topic"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.Topic = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.topic"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["topic"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.topicArn"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.TopicArn = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.topicArn"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["topicArn"] = value
 	}
 
-	if value, err := cmd.Flags().GetUint64("instance.target.specs.bufferSize"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.BufferSize = value
+	if value, err := cmd.Flags().GetUint64("instance.target.specs.bufferSize"); err == nil && value != 0 {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["bufferSize"] = value
 	}
 
 	if value, err := cmd.Flags().GetString("instance.target.specs.codec"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.Codec = value
+		stream.Stream.Instance.Target.TargetSpecs.Codec = specs.Codec(value)
 	}
 
-	if value, err := cmd.Flags().GetInt64("instance.target.specs.delaySeconds"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.DelaySeconds = value
+	if value, err := cmd.Flags().GetInt64("instance.target.specs.delaySeconds"); err == nil && value != 0 {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["delaySeconds"] = value
 	}
 
-	if value, err := cmd.Flags().GetInt("instance.target.specs.flushInMilliseconds"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.FlushInMilliseconds = value
+	if value, err := cmd.Flags().GetInt("instance.target.specs.flushInMilliseconds"); err == nil && value != 0 {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["flushInMilliseconds"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.keyColumnName"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.KeyColumnName = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.keyColumnName"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["keyColumnName"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.lineBreak"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.LineBreak = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.lineBreak"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["lineBreak"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.prefix"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.Prefix = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.prefix"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["prefix"] = value
 	}
 
-	if value, err := cmd.Flags().GetInt("instance.target.specs.batchSize"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.BatchSize = value
+	if value, err := cmd.Flags().GetInt("instance.target.specs.batchSize"); err == nil && value != 0 {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["batchSize"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.queue"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.Queue = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.queue"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["queue"] = value
 	}
 
-	if value, err := cmd.Flags().GetString("instance.target.specs.queueUrl"); err == nil {
-		stream.Stream.Instance.Target.TargetSpecs.QueueUrl = value
+	if value, err := cmd.Flags().GetString("instance.target.specs.queueUrl"); err == nil && value != "" {
+		stream.Stream.Instance.Target.TargetSpecs.Connector["queueUrl"] = value
 	}
 
 	if values, err := cmd.Flags().GetStringArray("instance.target.specs.configurations"); err == nil {
@@ -378,3 +427,87 @@ func (s scaffoldCommand) runE(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func (s scaffoldCommand) interactive(cmd *cobra.Command, path string) error {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	w := newWizard(cmd.InOrStdin(), cmd.OutOrStdout())
+
+	if _, err := os.Stat(path); err == nil {
+		overwrite, err := w.confirm(fmt.Sprintf("%s already exists, overwrite", path), false)
+		if err != nil {
+			return err
+		}
+
+		if !overwrite {
+			return errors.New(fmt.Sprintf("generate aborted, %s was kept", path))
+		}
+	}
+
+	root, err := w.run()
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(root); err != nil {
+		return errors.New(fmt.Sprintf("failed to serialize yaml: %s", err.Error()))
+	}
+
+	if err = encoder.Close(); err != nil {
+		return errors.New(fmt.Sprintf("failed to serialize yaml: %s", err.Error()))
+	}
+
+	if err := ioutil.WriteFile(path, buffer.Bytes(), 0644); err != nil {
+		return errors.New(fmt.Sprintf("failed to create file %s: %s", path, err.Error()))
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), fmt.Sprintf("%sgenerated scaffold \npath: %s\n-------\n%s-------%s", color.Green, path, buffer.String(), color.Reset))
+
+	return nil
+}
+
+func (s scaffoldCommand) fromSample(cmd *cobra.Command, path string, samplePath string) error {
+	cmd.SilenceUsage = true
+
+	options := sampleOptions{path: samplePath}
+	options.limit, _ = cmd.Flags().GetInt("sample-size")
+	options.port, _ = cmd.Flags().GetString("port")
+	options.targetType, _ = cmd.Flags().GetString("instance.target.type")
+	options.database, _ = cmd.Flags().GetString("instance.target.specs.database")
+	options.table, _ = cmd.Flags().GetString("instance.target.specs.table")
+	options.keyColumnName, _ = cmd.Flags().GetString("instance.target.specs.keyColumnName")
+
+	data, scaffold, ddl, err := fromSample(options)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, scaffold, 0644); err != nil {
+		return errors.New(fmt.Sprintf("failed to create file %s: %s", path, err.Error()))
+	}
+
+	fmt.Println(fmt.Sprintf("%sgenerated scaffold from %d records of %s \npath: %s\n-------\n%s-------%s",
+		color.Green, data.Records, samplePath, path, scaffold, color.Reset))
+
+	if ddl == "" {
+		return nil
+	}
+
+	ddlPath, _ := cmd.Flags().GetString("ddl-path")
+	if ddlPath == "" {
+		fmt.Println(fmt.Sprintf("%ssuggested table, review it before the first event lands\n-------\n%s-------%s", color.Green, ddl, color.Reset))
+		return nil
+	}
+
+	if err := ioutil.WriteFile(ddlPath, []byte(ddl), 0644); err != nil {
+		return errors.New(fmt.Sprintf("failed to create file %s: %s", ddlPath, err.Error()))
+	}
+
+	fmt.Println(fmt.Sprintf("%ssuggested table written to %s%s", color.Green, ddlPath, color.Reset))
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package scaffold
+
+import (
+	"bytes"
+	"draethos.io.com/internal/context"
+	"draethos.io.com/internal/sample"
+	"draethos.io.com/internal/schema"
+	"draethos.io.com/internal/target"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+var identifierPattern = regexp.MustCompile(`[^a-z0-9_]+`)
+
+type sampleOptions struct {
+	path          string
+	limit         int
+	port          string
+	targetType    string
+	database      string
+	table         string
+	keyColumnName string
+}
+
+// fromSample builds a pipeline reading the sample file and, for sql targets,
+// the table the target would create while the first events land.
+func fromSample(options sampleOptions) (*sample.Sample, []byte, string, error) {
+	data, err := sample.Read(options.path, options.limit)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if options.targetType == "" {
+		options.targetType = context.PgSqlTarget
+	}
+
+	if options.table == "" {
+		name := strings.TrimSuffix(filepath.Base(options.path), filepath.Ext(options.path))
+		options.table = strings.Trim(identifierPattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	}
+
+	if options.keyColumnName == "" {
+		options.keyColumnName = "id"
+	}
+
+	if options.port == "" {
+		options.port = "9000"
+	}
+
+	connector, ok := schema.Lookup(schema.TargetConnector, options.targetType)
+	if !ok {
+		return nil, nil, "", errors.New(fmt.Sprintf("invalid target %s, expected one of: %s",
+			options.targetType, strings.Join(schema.ConnectorNames(schema.TargetConnector), ", ")))
+	}
+
+	targetNode, err := exampleConnector(connector)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	sqlTarget := options.targetType == context.PgSqlTarget || options.targetType == context.MySqlTarget
+	if sqlTarget {
+		specs := mappingChild(targetNode, "specs")
+		setScalar(specs, "table", options.table)
+		setScalar(specs, "keyColumnName", options.keyColumnName)
+		if options.database != "" {
+			setScalar(specs, "database", options.database)
+		}
+	}
+
+	sourceType := context.JsonLSource
+	if data.Format == sample.CsvFormat {
+		sourceType = context.CsvSource
+	}
+
+	source := mapping()
+	appendNode(source, "type", scalar(sourceType))
+	appendNode(source, "specs", mappingOf("path", scalar(options.path)))
+
+	stream := mapping()
+	appendNode(stream, "port", &yaml.Node{Kind: yaml.ScalarNode, Value: options.port})
+	appendNode(stream, "healthCheck", mappingOf("endpoint", scalar("/health")))
+	appendNode(stream, "metrics", mappingOf("endpoint", scalar("/metrics")))
+
+	instance := mapping()
+	appendNode(instance, "source", source)
+	appendNode(instance, "target", targetNode)
+	appendNode(stream, "instance", instance)
+
+	root := mappingOf("stream", stream)
+	root.HeadComment = sampleComment(data)
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(root); err != nil {
+		return nil, nil, "", errors.New(fmt.Sprintf("failed to serialize yaml: %s", err.Error()))
+	}
+
+	if err = encoder.Close(); err != nil {
+		return nil, nil, "", errors.New(fmt.Sprintf("failed to serialize yaml: %s", err.Error()))
+	}
+
+	if problems := schema.Validate(buffer.Bytes()); len(problems) > 0 {
+		return nil, nil, "", errors.New(fmt.Sprintf("generated pipeline is invalid:\n%s", problems.Error()))
+	}
+
+	if !sqlTarget {
+		return data, buffer.Bytes(), "", nil
+	}
+
+	columns := make([]target.Column, 0, len(data.Fields))
+	for _, field := range data.Fields {
+		columns = append(columns, target.Column{Name: field.Name, Value: field.Value})
+	}
+
+	ddl := target.PgsqlCreateTable(options.table, options.keyColumnName, columns)
+	if options.targetType == context.MySqlTarget {
+		ddl = target.MysqlCreateTable(options.table, options.keyColumnName, columns)
+	}
+
+	return data, buffer.Bytes(), ddl, nil
+}
+
+func sampleComment(data *sample.Sample) string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "generated from %s, %d records sampled\nfields:\n", data.Path, data.Records)
+
+	writer := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	for _, field := range data.Fields {
+		fmt.Fprintf(writer, "  %s\t%s\t%d/%d\n", field.Name, field.Kind(), field.Count, data.Records)
+	}
+	writer.Flush()
+
+	suggestions := make([]string, 0)
+	for _, field := range data.Fields {
+		for _, suggestion := range field.Suggestions {
+			suggestions = append(suggestions, fmt.Sprintf("  %s: %s", field.Name, suggestion))
+		}
+	}
+
+	if len(suggestions) > 0 {
+		fmt.Fprintf(&buffer, "suggestions, fields to convert before ingestion:\n%s\n", strings.Join(suggestions, "\n"))
+	}
+
+	return strings.TrimRight(buffer.String(), "\n")
+}
+
+func exampleConnector(connector schema.Connector) (*yaml.Node, error) {
+	example, err := connector.Example()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to render %s example: %s", connector.Name, err.Error()))
+	}
+
+	var document yaml.Node
+	if err = yaml.Unmarshal([]byte(example), &document); err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to parse %s example: %s", connector.Name, err.Error()))
+	}
+
+	return document.Content[0].Content[1], nil
+}
+
+func mappingChild(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	child := mapping()
+	appendNode(node, key, child)
+
+	return child
+}
+
+func setScalar(node *yaml.Node, key string, value string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = scalar(value)
+			return
+		}
+	}
+
+	appendNode(node, key, scalar(value))
+}
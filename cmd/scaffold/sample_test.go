@@ -0,0 +1,59 @@
+package scaffold
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShouldScaffoldPipelineFromSample(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Orders.jsonl")
+	if err := ioutil.WriteFile(path, []byte(SampleOrdersTest), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+
+	data, pipeline, ddl, err := fromSample(sampleOptions{path: path, targetType: "pgsql"})
+	if err != nil {
+		t.Fatalf("failed to scaffold from sample: %v", err)
+	}
+
+	if data.Records != 2 || len(data.Fields) != 6 {
+		t.Errorf("unexpected sample: %d records, %d fields", data.Records, len(data.Fields))
+	}
+
+	for _, expected := range []string{
+		"type: jsonl",
+		"table: orders",
+		"#   amount    number   2/2",
+		"#   qty: integers stored as strings",
+		"#   shipped: dates stored as strings",
+		"#   paid: null in 1 of 2 records",
+	} {
+		if !strings.Contains(string(pipeline), expected) {
+			t.Errorf("expected %q in pipeline:\n%s", expected, pipeline)
+		}
+	}
+
+	if ddl != SampleOrdersDdlTest {
+		t.Errorf("unexpected ddl:\n%s", ddl)
+	}
+}
+
+const (
+	SampleOrdersTest = `{"order_id": 10, "amount": 10.5, "paid": true, "shipped": "03/02/2021", "qty": "3", "meta": {"a": 1}}
+{"order_id": 11, "amount": 12, "paid": null, "shipped": "04/02/2021", "qty": "4", "meta": {"a": 2}}
+`
+
+	SampleOrdersDdlTest = `CREATE TABLE IF NOT EXISTS orders (
+  id varchar(90) NOT NULL,
+  "order_id" NUMERIC NOT NULL DEFAULT 0,
+  "amount" NUMERIC NOT NULL DEFAULT 0,
+  "paid" BOOL NOT NULL DEFAULT false,
+  "shipped" VARCHAR(255) NULL,
+  "qty" VARCHAR(255) NULL,
+  "meta" JSONB NULL,
+  PRIMARY KEY (id)
+);
+`
+)
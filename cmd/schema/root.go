@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/schema"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"draethos.io.com/pkg/color"
+	"github.com/spf13/cobra"
+)
+
+type schemaCommand struct {
+}
+
+func NewSchemaCommand() interfaces.BuildCommand {
+	return schemaCommand{}
+}
+
+func (s schemaCommand) Build() *cobra.Command {
+	var schemaCommand = &cobra.Command{
+		Use:   "schema",
+		Short: "Export pipeline JSON Schema",
+		Long:  "Print the JSON Schema of pipeline files, including the specs and configurations of every connector, for editor completion and validation",
+		Example: `./draethos schema > pipeline.schema.json
+./draethos schema --export-path ./share/pipeline.schema.json`,
+		RunE: s.runE,
+	}
+
+	schemaCommand.
+		PersistentFlags().
+		StringP(
+			"export-path",
+			"",
+			"",
+			"file where the schema will be written, printed when omitted")
+
+	return schemaCommand
+}
+
+func (schemaCommand) runE(cmd *cobra.Command, args []string) error {
+	content, err := schema.JsonSchema()
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to render schema: %s", err.Error()))
+	}
+
+	exportPath, _ := cmd.Flags().GetString("export-path")
+	if exportPath == "" {
+		fmt.Println(string(content))
+		return nil
+	}
+
+	if err = ioutil.WriteFile(exportPath, append(content, '\n'), 0644); err != nil {
+		return errors.New(fmt.Sprintf("failed to write schema to %s: %s", exportPath, err.Error()))
+	}
+
+	fmt.Println(fmt.Sprintf("%sschema exported to %s%s", color.Green, exportPath, color.Reset))
+
+	return nil
+}
@@ -3,9 +3,12 @@ package start
 import (
 	"draethos.io.com/internal"
 	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/logging"
 	"errors"
 	"fmt"
+	"strings"
 
+	"draethos.io.com/pkg/streams/specs"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -34,6 +37,14 @@ func (s startCommand) Build() *cobra.Command {
 			"",
 			"file pipelines to be initialized")
 
+	startCommand.
+		PersistentFlags().
+		StringP(
+			"profile",
+			"",
+			"",
+			"profile merged on top of the pipeline file")
+
 	startCommand.
 		PersistentFlags().
 		BoolP(
@@ -56,7 +67,55 @@ func (s startCommand) Build() *cobra.Command {
 			"verbose",
 			"v",
 			false,
-			"display verbose mode")
+			"display verbose mode, same as --log-level debug")
+
+	startCommand.
+		PersistentFlags().
+		StringP(
+			"log-level",
+			"",
+			"",
+			fmt.Sprintf("minimum level logged (%s), overrides the pipeline file", strings.Join(logging.Levels, ", ")))
+
+	startCommand.
+		PersistentFlags().
+		StringP(
+			"log-format",
+			"",
+			"",
+			fmt.Sprintf("log line encoding (%s), overrides the pipeline file", strings.Join(logging.Formats, ", ")))
+
+	startCommand.
+		PersistentFlags().
+		StringP(
+			"log-payload",
+			"",
+			"",
+			fmt.Sprintf("how event contents are logged at debug level (%s), overrides the pipeline file", strings.Join(logging.Payloads, ", ")))
+
+	startCommand.
+		PersistentFlags().
+		IntP(
+			"log-sampling-initial",
+			"",
+			0,
+			"lines logged per second with the same level and message before sampling, overrides the pipeline file")
+
+	startCommand.
+		PersistentFlags().
+		IntP(
+			"log-sampling-thereafter",
+			"",
+			0,
+			"once sampling, one of every thereafter lines is logged, overrides the pipeline file")
+
+	startCommand.
+		PersistentFlags().
+		BoolP(
+			"watch",
+			"w",
+			false,
+			"reload pipeline when file changes (SIGHUP always triggers a reload)")
 
 	startCommand.
 		PersistentFlags().
@@ -76,6 +135,10 @@ func (startCommand) runE(cmd *cobra.Command, args []string) error {
 		configBuilder.SetFile(value)
 	}
 
+	if value, err := cmd.Flags().GetString("profile"); err == nil {
+		configBuilder.SetProfile(value)
+	}
+
 	if value, err := cmd.Flags().GetString("port"); err == nil {
 		configBuilder.SetPort(value)
 	}
@@ -88,6 +151,12 @@ func (startCommand) runE(cmd *cobra.Command, args []string) error {
 		configBuilder.EnableMetrics()
 	}
 
+	if value, err := cmd.Flags().GetBool("watch"); err == nil && value {
+		configBuilder.EnableWatch()
+	}
+
+	configBuilder.SetLogging(loggingFlags(cmd))
+
 	config, err := configBuilder.Build()
 	if err != nil {
 		zap.S().Error(err.Error())
@@ -95,6 +164,10 @@ func (startCommand) runE(cmd *cobra.Command, args []string) error {
 		return errors.New(fmt.Sprintf("failed to initialize stream: %s", err.Error()))
 	}
 
+	if err = logging.Configure(*config, configBuilder.GetFile()); err != nil {
+		return errors.New(fmt.Sprintf("failed to initialize stream: %s", err.Error()))
+	}
+
 	if err = internal.NewWorker(*config, configBuilder).Start(); err != nil {
 		zap.S().Error(err.Error())
 
@@ -103,3 +176,33 @@ func (startCommand) runE(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func loggingFlags(cmd *cobra.Command) specs.Logging {
+	flags := specs.Logging{}
+
+	if value, err := cmd.Flags().GetBool("verbose"); err == nil && value {
+		flags.Level = logging.LevelDebug
+	}
+
+	if value, err := cmd.Flags().GetString("log-level"); err == nil && value != "" {
+		flags.Level = value
+	}
+
+	if value, err := cmd.Flags().GetString("log-format"); err == nil {
+		flags.Format = value
+	}
+
+	if value, err := cmd.Flags().GetString("log-payload"); err == nil {
+		flags.Payload = value
+	}
+
+	if value, err := cmd.Flags().GetInt("log-sampling-initial"); err == nil {
+		flags.Sampling.Initial = value
+	}
+
+	if value, err := cmd.Flags().GetInt("log-sampling-thereafter"); err == nil {
+		flags.Sampling.Thereafter = value
+	}
+
+	return flags
+}
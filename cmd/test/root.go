@@ -0,0 +1,152 @@
+package test
+
+import (
+	"draethos.io.com/internal"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/logging"
+	"draethos.io.com/internal/pipetest"
+	"errors"
+	"fmt"
+	"os"
+
+	"draethos.io.com/pkg/color"
+	"github.com/spf13/cobra"
+)
+
+type testCommand struct {
+}
+
+func NewTestCommand() interfaces.BuildCommand {
+	return testCommand{}
+}
+
+func (t testCommand) Build() *cobra.Command {
+	var testCommand = &cobra.Command{
+		Use:   "test",
+		Short: "Test pipeline against golden files",
+		Long:  "Run a fixture through the pipeline source and codec into an in-memory target, comparing the events with the expected output file",
+		Example: `./draethos test -f pipeline.yaml -i fixtures/orders.jsonl -e fixtures/orders.expected.jsonl
+./draethos test -f pipeline.yaml -i fixtures/orders.jsonl -e fixtures/orders.expected.jsonl --update`,
+		RunE: t.runE,
+	}
+
+	testCommand.
+		PersistentFlags().
+		StringP(
+			"file",
+			"f",
+			"",
+			"pipeline file to be tested")
+
+	testCommand.
+		PersistentFlags().
+		StringP(
+			"profile",
+			"",
+			"",
+			"profile merged on top of the pipeline file")
+
+	testCommand.
+		PersistentFlags().
+		StringP(
+			"input",
+			"i",
+			"",
+			"fixture read by the source, one message per line for kafka and http sources")
+
+	testCommand.
+		PersistentFlags().
+		StringP(
+			"expected",
+			"e",
+			"",
+			"jsonl file with the events expected on target and dlq")
+
+	testCommand.
+		PersistentFlags().
+		DurationP(
+			"timeout",
+			"t",
+			pipetest.TimeoutDefault,
+			"fail when the source does not finish reading the fixture in time")
+
+	testCommand.
+		PersistentFlags().
+		BoolP(
+			"update",
+			"u",
+			false,
+			"write the actual events to the expected output file")
+
+	return testCommand
+}
+
+func (testCommand) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	configBuilder := internal.NewConfigBuilder()
+
+	if value, err := cmd.Flags().GetString("file"); err == nil {
+		configBuilder.SetFile(value)
+	}
+
+	if value, err := cmd.Flags().GetString("profile"); err == nil {
+		configBuilder.SetProfile(value)
+	}
+
+	config, err := configBuilder.Build()
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to load pipeline: %s", err.Error()))
+	}
+
+	if err = logging.Configure(*config, configBuilder.GetFile()); err != nil {
+		return err
+	}
+
+	input, _ := cmd.Flags().GetString("input")
+	expectedPath, _ := cmd.Flags().GetString("expected")
+	if expectedPath == "" {
+		return errors.New("expected output file not informed, use --expected")
+	}
+
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	actual, err := pipetest.Run(*config, input, timeout)
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to run pipeline: %s", err.Error()))
+	}
+
+	if update, _ := cmd.Flags().GetBool("update"); update {
+		file, err := os.Create(expectedPath)
+		if err != nil {
+			return errors.New(fmt.Sprintf("failed to create %s: %s", expectedPath, err.Error()))
+		}
+
+		defer file.Close()
+
+		if err = pipetest.WriteRecords(file, actual); err != nil {
+			return err
+		}
+
+		fmt.Println(fmt.Sprintf("%s%d event(s) written to %s%s", color.Green, len(actual), expectedPath, color.Reset))
+		return nil
+	}
+
+	expected, err := pipetest.ReadRecords(expectedPath)
+	if err != nil {
+		return err
+	}
+
+	differences := pipetest.Diff(expected, actual)
+	if len(differences) == 0 {
+		fmt.Println(fmt.Sprintf("%s%d event(s) matched %s%s", color.Green, len(actual), expectedPath, color.Reset))
+		return nil
+	}
+
+	for _, difference := range differences {
+		fmt.Println(fmt.Sprintf("%s- %s%s", color.Yellow, difference, color.Reset))
+	}
+
+	cmd.SilenceErrors = true
+
+	return errors.New(fmt.Sprintf("%d difference(s) against %s", len(differences), expectedPath))
+}
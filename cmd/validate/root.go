@@ -0,0 +1,85 @@
+package validate
+
+import (
+	"draethos.io.com/internal"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/schema"
+	"errors"
+	"fmt"
+
+	"draethos.io.com/pkg/color"
+	"github.com/spf13/cobra"
+)
+
+type validateCommand struct {
+}
+
+func NewValidateCommand() interfaces.BuildCommand {
+	return validateCommand{}
+}
+
+func (v validateCommand) Build() *cobra.Command {
+	var validateCommand = &cobra.Command{
+		Use:     "validate",
+		Short:   "Validate pipeline",
+		Long:    "Check pipeline file informed by parameter, reporting every problem found with its line number",
+		Example: "./draethos validate -f pipeline.yaml",
+		RunE:    v.runE,
+	}
+
+	validateCommand.
+		PersistentFlags().
+		StringP(
+			"file",
+			"f",
+			"",
+			"pipeline file to be validated")
+
+	validateCommand.
+		PersistentFlags().
+		StringP(
+			"profile",
+			"",
+			"",
+			"profile merged on top of the pipeline file")
+
+	return validateCommand
+}
+
+func (validateCommand) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	configBuilder := internal.NewConfigBuilder()
+
+	if value, err := cmd.Flags().GetString("file"); err == nil {
+		configBuilder.SetFile(value)
+	}
+
+	if value, err := cmd.Flags().GetString("profile"); err == nil {
+		configBuilder.SetProfile(value)
+	}
+
+	err := configBuilder.Validate()
+	if err == nil {
+		fmt.Println(fmt.Sprintf("%s%s is valid%s", color.Green, configBuilder.GetFile(), color.Reset))
+		return nil
+	}
+
+	var problems schema.Problems
+	if !errors.As(err, &problems) {
+		return err
+	}
+
+	for _, problem := range problems {
+		file := problem.File
+		if file == "" {
+			file = configBuilder.GetFile()
+		}
+
+		fmt.Println(fmt.Sprintf("%s%s %s%s", color.Yellow, file, problem.String(), color.Reset))
+	}
+
+	cmd.SilenceErrors = true
+
+	return errors.New(fmt.Sprintf("%s is invalid, %d problem(s) found", configBuilder.GetFile(), len(problems)))
+}
@@ -3,17 +3,25 @@ module draethos.io.com
 go 1.18
 
 require (
+	github.com/DataDog/zstd v1.5.0
 	github.com/aws/aws-sdk-go v1.41.14
 	github.com/confluentinc/confluent-kafka-go v1.9.1
+	github.com/fxamacker/cbor/v2 v2.7.0
 	github.com/go-sql-driver/mysql v1.6.0
+	github.com/golang/snappy v0.0.4
 	github.com/gorilla/mux v1.8.0
 	github.com/heptiolabs/healthcheck v0.0.0-20211123025425-613501dd5deb
 	github.com/lib/pq v1.10.4
+	github.com/linkedin/goavro/v2 v2.12.0
+	github.com/pierrec/lz4/v4 v4.1.25
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.11.0
 	github.com/spf13/cobra v1.1.3
+	github.com/vmihailenco/msgpack/v5 v5.3.5
 	go.uber.org/zap v1.19.1
+	google.golang.org/protobuf v1.28.0
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -30,12 +38,13 @@ require (
 	github.com/prometheus/common v0.30.0 // indirect
 	github.com/prometheus/procfs v0.7.3 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/multierr v1.7.0 // indirect
 	golang.org/x/net v0.0.0-20210917221730-978cfadd31cf // indirect
 	golang.org/x/sys v0.0.0-20211007075335-d3039528d8ac // indirect
 	golang.org/x/text v0.3.7 // indirect
-	google.golang.org/protobuf v1.28.0 // indirect
 	gopkg.in/DATA-DOG/go-sqlmock.v1 v1.3.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 )
@@ -0,0 +1,243 @@
+package bench
+
+import (
+	context2 "draethos.io.com/internal/context"
+	interfaces2 "draethos.io.com/internal/interfaces"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	DurationDefault = time.Minute
+	memoryInterval  = 100 * time.Millisecond
+)
+
+type Report struct {
+	Events      int
+	Elapsed     time.Duration
+	Flushes     []time.Duration
+	FlushSizes  []int
+	FlushErrors int
+	PeakHeap    uint64
+	PeakSys     uint64
+	TotalAlloc  uint64
+	NumGC       uint32
+}
+
+// Throughput returns the events attached to the target per second.
+func (r Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(r.Events) / r.Elapsed.Seconds()
+}
+
+// Percentile returns the flush latency below which p percent of the flushes
+// completed, using the nearest rank.
+func (r Report) Percentile(p float64) time.Duration {
+	if len(r.Flushes) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), r.Flushes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
+
+// AverageFlushSize returns the events sent per flush.
+func (r Report) AverageFlushSize() float64 {
+	if len(r.FlushSizes) == 0 {
+		return 0
+	}
+
+	total := 0
+	for _, size := range r.FlushSizes {
+		total += size
+	}
+
+	return float64(total) / float64(len(r.FlushSizes))
+}
+
+// measuredTarget forwards to the pipeline target, timing every flush that
+// had events pending.
+type measuredTarget struct {
+	sync.Mutex
+	target  interfaces2.TargetInterface
+	events  int
+	pending int
+	flushes []time.Duration
+	sizes   []int
+	errors  int
+}
+
+func newMeasuredTarget(target interfaces2.TargetInterface) *measuredTarget {
+	return &measuredTarget{target: target}
+}
+
+func (m *measuredTarget) Initialize() error {
+	return m.target.Initialize()
+}
+
+func (m *measuredTarget) Attach(key string, data map[string]interface{}) error {
+	if err := m.target.Attach(key, data); err != nil {
+		return err
+	}
+
+	m.Lock()
+	m.events++
+	m.pending++
+	m.Unlock()
+
+	return nil
+}
+
+func (m *measuredTarget) CanFlush() bool {
+	return m.target.CanFlush()
+}
+
+func (m *measuredTarget) Flush() error {
+	m.Lock()
+	pending := m.pending
+	m.pending = 0
+	m.Unlock()
+
+	start := time.Now()
+	err := m.target.Flush()
+	elapsed := time.Since(start)
+
+	m.Lock()
+	defer m.Unlock()
+
+	if err != nil {
+		m.errors++
+		return err
+	}
+
+	if pending > 0 {
+		m.flushes = append(m.flushes, elapsed)
+		m.sizes = append(m.sizes, pending)
+	}
+
+	return nil
+}
+
+func (m *measuredTarget) Close() error {
+	return m.target.Close()
+}
+
+// Run starts the pipeline and stops it after the duration, or earlier when
+// the source ends such as a generator with a count, measuring the target.
+func Run(stream specs.Stream, duration time.Duration) (Report, error) {
+	if duration <= 0 {
+		duration = DurationDefault
+	}
+
+	target, err := context2.NewTargetContext(stream.Stream.Instance.Target)
+	if err != nil {
+		return Report{}, err
+	}
+
+	measured := newMeasuredTarget(target)
+
+	var dlq interfaces2.TargetInterface
+	if stream.Stream.Instance.Dlq.Type != "" {
+		if dlq, err = context2.NewTargetContext(stream.Stream.Instance.Dlq); err != nil {
+			return Report{}, err
+		}
+	}
+
+	source, err := context2.NewSourceContext(stream, measured, dlq, &mux.Router{}, stream.Stream.Port)
+	if err != nil {
+		return Report{}, err
+	}
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	report := Report{}
+	sampling := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(memoryInterval)
+		defer ticker.Stop()
+
+		for {
+			report.sample()
+			select {
+			case <-sampling:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Worker()
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(duration):
+		zap.S().Infof("bench duration %s reached, stopping source", duration)
+		source.Stop()
+		err = <-done
+	}
+
+	report.Elapsed = time.Since(start)
+	close(sampling)
+	<-sampled
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	report.TotalAlloc = after.TotalAlloc - before.TotalAlloc
+	report.NumGC = after.NumGC - before.NumGC
+
+	measured.Lock()
+	report.Events = measured.events
+	report.Flushes = measured.flushes
+	report.FlushSizes = measured.sizes
+	report.FlushErrors = measured.errors
+	measured.Unlock()
+
+	if closeErr := measured.Close(); closeErr != nil {
+		zap.S().Warnf("failed to close target: %s", closeErr.Error())
+	}
+
+	if err != nil {
+		return report, errors.Errorf("source %s stopped: %s", stream.Stream.Instance.Source.Type, err.Error())
+	}
+
+	return report, nil
+}
+
+func (r *Report) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	if stats.HeapAlloc > r.PeakHeap {
+		r.PeakHeap = stats.HeapAlloc
+	}
+
+	if stats.Sys > r.PeakSys {
+		r.PeakSys = stats.Sys
+	}
+}
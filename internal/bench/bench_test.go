@@ -0,0 +1,70 @@
+package bench
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type slowTarget struct {
+	fail bool
+}
+
+func (s *slowTarget) Initialize() error                               { return nil }
+func (s *slowTarget) Attach(_ string, _ map[string]interface{}) error { return nil }
+func (s *slowTarget) CanFlush() bool                                  { return true }
+func (s *slowTarget) Close() error                                    { return nil }
+
+func (s *slowTarget) Flush() error {
+	time.Sleep(time.Millisecond)
+	if s.fail {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestShouldMeasureFlushesWithEvents(t *testing.T) {
+	target := &slowTarget{}
+	measured := newMeasuredTarget(target)
+
+	for i := 0; i < 3; i++ {
+		_ = measured.Attach("key", map[string]interface{}{})
+	}
+	_ = measured.Flush()
+	_ = measured.Flush()
+
+	target.fail = true
+	_ = measured.Attach("key", map[string]interface{}{})
+	if err := measured.Flush(); err == nil {
+		t.Errorf("expected flush error to be returned")
+	}
+
+	if measured.events != 4 || len(measured.flushes) != 1 || measured.sizes[0] != 3 || measured.errors != 1 {
+		t.Errorf("unexpected measures: %d events, %v flushes, %v sizes, %d errors",
+			measured.events, measured.flushes, measured.sizes, measured.errors)
+	}
+
+	if measured.flushes[0] < time.Millisecond {
+		t.Errorf("expected flush latency of at least 1ms, got %s", measured.flushes[0])
+	}
+}
+
+func TestShouldReportPercentiles(t *testing.T) {
+	report := Report{Events: 1000, Elapsed: 2 * time.Second, FlushSizes: []int{100, 300}}
+	for i := 10; i >= 1; i-- {
+		report.Flushes = append(report.Flushes, time.Duration(i)*time.Millisecond)
+	}
+
+	if report.Percentile(50) != 5*time.Millisecond || report.Percentile(90) != 9*time.Millisecond ||
+		report.Percentile(99) != 10*time.Millisecond || report.Percentile(100) != 10*time.Millisecond {
+		t.Errorf("unexpected percentiles: p50 %s, p90 %s, p99 %s", report.Percentile(50), report.Percentile(90), report.Percentile(99))
+	}
+
+	if report.Throughput() != 500 || report.AverageFlushSize() != 200 {
+		t.Errorf("unexpected throughput %v or flush size %v", report.Throughput(), report.AverageFlushSize())
+	}
+
+	if (Report{}).Percentile(50) != 0 {
+		t.Errorf("expected no latency without flushes")
+	}
+}
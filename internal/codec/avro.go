@@ -0,0 +1,230 @@
+package target
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/linkedin/goavro/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// avroMagicByte starts every message of the schema registry wire format,
+	// followed by the schema id in 4 bytes big-endian and the avro binary.
+	avroMagicByte  byte = 0
+	avroHeaderSize      = 5
+)
+
+type avroCodec struct {
+	sync.Mutex
+	registry       *schemaRegistry
+	configurations avroConfigurations
+	codecs         map[int]*goavro.Codec
+	writerId       int
+	writer         *goavro.Codec
+}
+
+type avroConfigurations struct {
+	RegistryUrl      string        `config:"registry.url"`
+	RegistryUsername string        `config:"registry.username"`
+	RegistryPassword string        `config:"registry.password"`
+	RegistryTimeout  time.Duration `config:"registry.timeout"`
+	Subject          string        `config:"subject"`
+	Schema           string        `config:"schema"`
+	SchemaFile       string        `config:"schema.file"`
+	AutoRegister     bool          `config:"auto.register"`
+}
+
+// NewAvroCodec reads and writes avro in the schema registry wire format.
+// Writer schemas are fetched from the registry by the id of each message and
+// cached, events are written with the schema configured, or the latest one
+// of the subject.
+func NewAvroCodec(configurations map[string]interface{}) (interfaces.CodecInterface, error) {
+	config := avroConfigurations{RegistryTimeout: SchemaRegistryTimeout}
+	if err := specs.DecodeConfigurations(configurations, &config); err != nil {
+		return nil, errors.Errorf("avro codec: %s", err.Error())
+	}
+
+	if config.RegistryUrl == "" {
+		return nil, errors.Errorf("avro codec: registry.url not defined")
+	}
+
+	if config.SchemaFile != "" {
+		content, err := ioutil.ReadFile(config.SchemaFile)
+		if err != nil {
+			return nil, errors.Errorf("avro codec: failed to read schema.file: %s", err.Error())
+		}
+		config.Schema = string(content)
+	}
+
+	if config.Schema != "" {
+		if _, err := goavro.NewCodecForStandardJSONFull(config.Schema); err != nil {
+			return nil, errors.Errorf("avro codec: invalid schema: %s", err.Error())
+		}
+	}
+
+	return &avroCodec{
+		registry:       newSchemaRegistry(config.RegistryUrl, config.RegistryUsername, config.RegistryPassword, config.RegistryTimeout),
+		configurations: config,
+		codecs:         map[int]*goavro.Codec{},
+	}, nil
+}
+
+func (a *avroCodec) Deserialize(content []byte) (map[string]interface{}, error) {
+	if len(content) < avroHeaderSize || content[0] != avroMagicByte {
+		return nil, errors.Errorf("avro codec: missing schema registry header")
+	}
+
+	id := int(binary.BigEndian.Uint32(content[1:avroHeaderSize]))
+	codec, err := a.codec(id)
+	if err != nil {
+		return nil, err
+	}
+
+	native, _, err := codec.NativeFromBinary(content[avroHeaderSize:])
+	if err != nil {
+		return nil, errors.Errorf("avro codec: failed to decode with schema %d: %s", id, err.Error())
+	}
+
+	// the json form has unions unwrapped, numbers are kept as written so
+	// longs above 2^53 don't lose precision as float64
+	textual, err := codec.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, errors.Errorf("avro codec: %s", err.Error())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(textual))
+	decoder.UseNumber()
+
+	var data map[string]interface{}
+	if err = decoder.Decode(&data); err != nil {
+		return nil, errors.Errorf("avro codec: schema %d is not a record: %s", id, err.Error())
+	}
+
+	return parseNumbers(data).(map[string]interface{}), nil
+}
+
+func (a *avroCodec) Serialize(content map[string]interface{}) ([]byte, error) {
+	id, codec, err := a.writerCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	textual, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	native, _, err := codec.NativeFromTextual(textual)
+	if err != nil {
+		return nil, errors.Errorf("avro codec: event does not match schema %d: %s", id, err.Error())
+	}
+
+	data := make([]byte, avroHeaderSize, avroHeaderSize+len(textual))
+	data[0] = avroMagicByte
+	binary.BigEndian.PutUint32(data[1:avroHeaderSize], uint32(id))
+
+	data, err = codec.BinaryFromNative(data, native)
+	if err != nil {
+		return nil, errors.Errorf("avro codec: event does not match schema %d: %s", id, err.Error())
+	}
+
+	return data, nil
+}
+
+// parseNumbers converts the numbers decoded to int64 when they are integers
+// and to float64 otherwise.
+func parseNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = parseNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = parseNumbers(item)
+		}
+	case json.Number:
+		if integer, err := v.Int64(); err == nil {
+			return integer
+		}
+		if float, err := v.Float64(); err == nil {
+			return float
+		}
+	}
+
+	return value
+}
+
+func (a *avroCodec) codec(id int) (*goavro.Codec, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	if codec, ok := a.codecs[id]; ok {
+		return codec, nil
+	}
+
+	schema, err := a.registry.SchemaById(id)
+	if err != nil {
+		return nil, errors.Errorf("avro codec: %s", err.Error())
+	}
+
+	codec, err := goavro.NewCodecForStandardJSONFull(schema)
+	if err != nil {
+		return nil, errors.Errorf("avro codec: invalid schema %d: %s", id, err.Error())
+	}
+
+	a.codecs[id] = codec
+
+	return codec, nil
+}
+
+// writerCodec resolves once the schema events are written with: the schema
+// configured, registered when auto.register is enabled, or the latest version
+// of the subject.
+func (a *avroCodec) writerCodec() (int, *goavro.Codec, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.writer != nil {
+		return a.writerId, a.writer, nil
+	}
+
+	if a.configurations.Subject == "" {
+		return 0, nil, errors.Errorf("avro codec: subject not defined, required to serialize events")
+	}
+
+	var id int
+	var err error
+	schema := a.configurations.Schema
+	switch {
+	case schema != "" && a.configurations.AutoRegister:
+		id, err = a.registry.Register(a.configurations.Subject, schema)
+	case schema != "":
+		id, err = a.registry.Lookup(a.configurations.Subject, schema)
+	default:
+		var latest registrySchema
+		latest, err = a.registry.Latest(a.configurations.Subject)
+		id, schema = latest.Id, latest.Schema
+	}
+
+	if err != nil {
+		return 0, nil, errors.Errorf("avro codec: %s", err.Error())
+	}
+
+	codec, err := goavro.NewCodecForStandardJSONFull(schema)
+	if err != nil {
+		return 0, nil, errors.Errorf("avro codec: invalid schema %d: %s", id, err.Error())
+	}
+
+	a.writerId, a.writer = id, codec
+	a.codecs[id] = codec
+
+	return id, codec, nil
+}
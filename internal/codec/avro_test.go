@@ -0,0 +1,184 @@
+package target
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const orderSchemaTest = `{"type":"record","name":"Order","fields":[
+	{"name":"id","type":"long"},
+	{"name":"customer","type":["null","string"],"default":null}
+]}`
+
+// mockRegistry serves the subset of the schema registry api the codec uses.
+type mockRegistry struct {
+	sync.Mutex
+	schemas  map[int]string
+	subjects map[string][]int
+	requests map[string]int
+}
+
+func newMockRegistry() (*mockRegistry, *httptest.Server) {
+	registry := &mockRegistry{schemas: map[int]string{}, subjects: map[string][]int{}, requests: map[string]int{}}
+	return registry, httptest.NewServer(registry)
+}
+
+func (m *mockRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.requests[r.Method+" "+r.URL.Path]++
+
+	var body registrySchema
+	if r.Method == http.MethodPost {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "schemas" && parts[1] == "ids":
+		var id int
+		fmt.Sscanf(parts[2], "%d", &id)
+		if schema, ok := m.schemas[id]; ok {
+			_ = json.NewEncoder(w).Encode(registrySchema{Schema: schema})
+			return
+		}
+	case len(parts) == 4 && parts[2] == "versions" && parts[3] == "latest":
+		if ids := m.subjects[parts[1]]; len(ids) > 0 {
+			id := ids[len(ids)-1]
+			_ = json.NewEncoder(w).Encode(registrySchema{Id: id, Schema: m.schemas[id]})
+			return
+		}
+	case len(parts) == 3 && parts[2] == "versions":
+		id := len(m.schemas) + 1
+		m.schemas[id] = body.Schema
+		m.subjects[parts[1]] = append(m.subjects[parts[1]], id)
+		_ = json.NewEncoder(w).Encode(registrySchema{Id: id})
+		return
+	case len(parts) == 2:
+		for _, id := range m.subjects[parts[1]] {
+			if m.schemas[id] == body.Schema {
+				_ = json.NewEncoder(w).Encode(registrySchema{Id: id, Schema: body.Schema})
+				return
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	_ = json.NewEncoder(w).Encode(registryError{ErrorCode: 40403, Message: "Schema not found"})
+}
+
+func TestShouldRoundTripAvroRegisteringSchema(t *testing.T) {
+	registry, server := newMockRegistry()
+	defer server.Close()
+
+	writer, err := NewAvroCodec(map[string]interface{}{
+		"registry.url":  server.URL,
+		"subject":       "orders-value",
+		"schema":        orderSchemaTest,
+		"auto.register": true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, event := range []map[string]interface{}{{"id": 1, "customer": "ana"}, {"id": 2}} {
+		content, err := writer.Serialize(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if content[0] != avroMagicByte || content[4] != 1 {
+			t.Errorf("expected wire format with schema id 1, got %v", content[:avroHeaderSize])
+		}
+
+		reader, _ := NewAvroCodec(map[string]interface{}{"registry.url": server.URL})
+		decoded, err := reader.Deserialize(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if fmt.Sprint(decoded["id"]) != fmt.Sprint(event["id"]) || decoded["customer"] != event["customer"] {
+			t.Errorf("expected %v, got %v", event, decoded)
+		}
+	}
+
+	if registry.requests["POST /subjects/orders-value/versions"] != 1 {
+		t.Errorf("expected the schema registered once, got %v", registry.requests)
+	}
+}
+
+func TestShouldCacheWriterSchemas(t *testing.T) {
+	registry, server := newMockRegistry()
+	defer server.Close()
+
+	registry.schemas[7] = orderSchemaTest
+	registry.subjects["orders-value"] = []int{7}
+
+	writer, _ := NewAvroCodec(map[string]interface{}{"registry.url": server.URL, "subject": "orders-value"})
+	content, err := writer.Serialize(map[string]interface{}{"id": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, _ := NewAvroCodec(map[string]interface{}{"registry.url": server.URL})
+	for i := 0; i < 3; i++ {
+		if _, err := reader.Deserialize(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if registry.requests["GET /schemas/ids/7"] != 1 {
+		t.Errorf("expected schema 7 fetched once, got %v", registry.requests)
+	}
+}
+
+func TestShouldRejectUnregisteredSchemaAndInvalidMessages(t *testing.T) {
+	_, server := newMockRegistry()
+	defer server.Close()
+
+	writer, _ := NewAvroCodec(map[string]interface{}{"registry.url": server.URL, "subject": "orders-value", "schema": orderSchemaTest})
+	if _, err := writer.Serialize(map[string]interface{}{"id": 1}); err == nil || !strings.Contains(err.Error(), "Schema not found") {
+		t.Errorf("expected lookup failure, got %v", err)
+	}
+
+	if _, err := writer.Deserialize([]byte(`{"id":1}`)); err == nil {
+		t.Error("expected message without header to be rejected")
+	}
+
+	if _, err := NewAvroCodec(map[string]interface{}{"subject": "orders-value"}); err == nil {
+		t.Error("expected missing registry.url to be rejected")
+	}
+}
+
+func TestShouldKeepPrecisionOfLongs(t *testing.T) {
+	_, server := newMockRegistry()
+	defer server.Close()
+
+	codec, _ := NewAvroCodec(map[string]interface{}{
+		"registry.url":  server.URL,
+		"subject":       "orders-value",
+		"schema":        orderSchemaTest,
+		"auto.register": true,
+	})
+
+	id := int64(1<<53 + 1)
+	content, err := codec.Serialize(map[string]interface{}{"id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := codec.Deserialize(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded["id"] != id {
+		t.Errorf("expected id %d, got %v (%T)", id, decoded["id"], decoded["id"])
+	}
+}
@@ -0,0 +1,87 @@
+package target
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"draethos.io.com/internal/interfaces"
+)
+
+func TestShouldRoundTripBinaryCodecsKeepingTypes(t *testing.T) {
+	cbor, err := NewCborCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := map[string]interface{}{
+		"id":        int64(-42),
+		"counter":   uint64(1 << 63),
+		"ratio":     1.5,
+		"payload":   []byte{0, 1, 2, 255},
+		"createdAt": time.Date(2026, 10, 19, 12, 30, 0, 123456789, time.UTC),
+		"device":    map[string]interface{}{"tags": []interface{}{"a", int64(7)}, "on": true},
+		"missing":   nil,
+	}
+
+	for name, codec := range map[string]interfaces.CodecInterface{"msgpack": NewMsgpackCodec(), "cbor": cbor} {
+		content, err := codec.Serialize(event)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		decoded, err := codec.Deserialize(content)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		createdAt, ok := decoded["createdAt"].(time.Time)
+		if !ok || !createdAt.Equal(event["createdAt"].(time.Time)) {
+			t.Errorf("%s: expected timestamp %v, got %#v", name, event["createdAt"], decoded["createdAt"])
+		}
+
+		delete(decoded, "createdAt")
+		expected := make(map[string]interface{}, len(event))
+		for key, value := range event {
+			expected[key] = value
+		}
+		delete(expected, "createdAt")
+
+		if !reflect.DeepEqual(decoded, expected) {
+			t.Errorf("%s: expected %#v, got %#v", name, expected, decoded)
+		}
+	}
+}
+
+func TestShouldRejectBinaryPayloadsThatAreNotMaps(t *testing.T) {
+	cbor, _ := NewCborCodec()
+	msgpack := NewMsgpackCodec()
+
+	list, _ := msgpack.Serialize(map[string]interface{}{"a": 1})
+	for name, codec := range map[string]interfaces.CodecInterface{"msgpack": msgpack, "cbor": cbor} {
+		if _, err := codec.Deserialize([]byte{0x93, 0x01, 0x02}); err == nil {
+			t.Errorf("%s: expected list payload to be rejected", name)
+		}
+
+		if _, err := codec.Deserialize(list[:len(list)-1]); err == nil {
+			t.Errorf("%s: expected truncated payload to be rejected", name)
+		}
+	}
+}
+
+func TestShouldReadCborIntegerKeysAsStrings(t *testing.T) {
+	cbor, _ := NewCborCodec()
+
+	// {1: "a", "device": {-2: true}}
+	content := []byte{0xa2, 0x01, 0x61, 'a', 0x66, 'd', 'e', 'v', 'i', 'c', 'e', 0xa1, 0x21, 0xf5}
+
+	decoded, err := cbor.Deserialize(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{"1": "a", "device": map[string]interface{}{"-2": true}}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %#v, got %#v", expected, decoded)
+	}
+}
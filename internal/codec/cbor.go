@@ -0,0 +1,104 @@
+package target
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"draethos.io.com/internal/interfaces"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/pkg/errors"
+)
+
+type cborCodec struct {
+	encoder cbor.EncMode
+	decoder cbor.DecMode
+}
+
+// NewCborCodec reads and writes CBOR maps. Integers are read as int64, or
+// uint64 when they do not fit, byte strings as []byte, tagged timestamps as
+// time.Time and map keys other than strings, such as integers, as their text
+// form. Timestamps are written as tagged RFC3339 strings keeping their
+// nanoseconds and time zone.
+func NewCborCodec() (interfaces.CodecInterface, error) {
+	encoder, err := cbor.EncOptions{
+		Sort:          cbor.SortCoreDeterministic,
+		ShortestFloat: cbor.ShortestFloat16,
+		Time:          cbor.TimeRFC3339Nano,
+		TimeTag:       cbor.EncTagRequired,
+	}.EncMode()
+	if err != nil {
+		return nil, errors.Errorf("cbor codec: %s", err.Error())
+	}
+
+	decoder, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[interface{}]interface{}{}),
+	}.DecMode()
+	if err != nil {
+		return nil, errors.Errorf("cbor codec: %s", err.Error())
+	}
+
+	return cborCodec{encoder: encoder, decoder: decoder}, nil
+}
+
+func (c cborCodec) Deserialize(content []byte) (map[string]interface{}, error) {
+	var data map[interface{}]interface{}
+	if err := c.decoder.Unmarshal(content, &data); err != nil {
+		return nil, errors.Errorf("cbor codec: %s", err.Error())
+	}
+
+	return widenIntegers(data).(map[string]interface{}), nil
+}
+
+func (c cborCodec) Serialize(content map[string]interface{}) ([]byte, error) {
+	data, err := c.encoder.Marshal(content)
+	if err != nil {
+		return nil, errors.Errorf("cbor codec: %s", err.Error())
+	}
+
+	return data, nil
+}
+
+// widenIntegers converts the integers decoded to int64, or uint64 when they do
+// not fit, whatever the width they were encoded with, and the keys of maps
+// decoded with keys of any type to strings.
+func widenIntegers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		data := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			data[fmt.Sprint(widenIntegers(key))] = widenIntegers(item)
+		}
+		return data
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = widenIntegers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = widenIntegers(item)
+		}
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint:
+		return widenIntegers(uint64(v))
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+	}
+
+	return value
+}
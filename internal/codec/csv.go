@@ -0,0 +1,217 @@
+package target
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/pkg/errors"
+)
+
+const (
+	CsvQuoteMinimal = "minimal"
+	CsvQuoteAll     = "all"
+
+	CsvExtension = "csv"
+	TsvExtension = "tsv"
+)
+
+type csvCodec struct {
+	delimiter rune
+	extension string
+	columns   []string
+	header    bool
+	quoteAll  bool
+}
+
+type csvConfigurations struct {
+	Delimiter string   `config:"delimiter"`
+	Columns   []string `config:"columns"`
+	Header    bool     `config:"header"`
+	Quote     string   `config:"quote"`
+}
+
+// NewCsvCodec reads and writes events as a delimited line. Columns name the
+// values read and order the values written, when a header is enabled payloads
+// start with a header line naming the columns and files written by targets
+// start with the columns.
+func NewCsvCodec(configurations map[string]interface{}) (interfaces.CodecInterface, error) {
+	return newCsvCodec(configurations, ",", CsvExtension)
+}
+
+// NewTsvCodec is the csv codec delimited by tabs.
+func NewTsvCodec(configurations map[string]interface{}) (interfaces.CodecInterface, error) {
+	return newCsvCodec(configurations, "\t", TsvExtension)
+}
+
+func newCsvCodec(configurations map[string]interface{}, delimiter string, extension string) (interfaces.CodecInterface, error) {
+	config := csvConfigurations{Delimiter: delimiter, Quote: CsvQuoteMinimal}
+	if err := specs.DecodeConfigurations(configurations, &config); err != nil {
+		return nil, errors.Errorf("%s codec: %s", extension, err.Error())
+	}
+
+	comma, size := utf8.DecodeRuneInString(config.Delimiter)
+	if size == 0 || size != len(config.Delimiter) || comma == '"' || comma == '\r' || comma == '\n' || comma == utf8.RuneError {
+		return nil, errors.Errorf("%s codec: invalid delimiter %q, expected a single character", extension, config.Delimiter)
+	}
+
+	if config.Quote != CsvQuoteMinimal && config.Quote != CsvQuoteAll {
+		return nil, errors.Errorf("%s codec: invalid quote %s, expected one of: %s, %s", extension, config.Quote, CsvQuoteMinimal, CsvQuoteAll)
+	}
+
+	if len(config.Columns) == 0 && !config.Header {
+		return nil, errors.Errorf("%s codec: columns not defined, required unless header is enabled", extension)
+	}
+
+	return csvCodec{
+		delimiter: comma,
+		extension: extension,
+		columns:   config.Columns,
+		header:    config.Header,
+		quoteAll:  config.Quote == CsvQuoteAll,
+	}, nil
+}
+
+func (c csvCodec) Deserialize(content []byte) (map[string]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = c.delimiter
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.Errorf("%s codec: %s", c.extension, err.Error())
+	}
+
+	columns := c.columns
+	if c.header {
+		if len(records) == 0 {
+			return nil, errors.Errorf("%s codec: header not found", c.extension)
+		}
+
+		if len(columns) == 0 {
+			columns = records[0]
+		}
+		records = records[1:]
+	}
+
+	if len(records) != 1 {
+		return nil, errors.Errorf("%s codec: expected a single line of values, got %d", c.extension, len(records))
+	}
+
+	values := records[0]
+	if len(values) != len(columns) {
+		return nil, errors.Errorf("%s codec: expected %d values, got %d", c.extension, len(columns), len(values))
+	}
+
+	data := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		data[column] = values[i]
+	}
+
+	return data, nil
+}
+
+func (c csvCodec) Serialize(content map[string]interface{}) ([]byte, error) {
+	if len(c.columns) == 0 {
+		return nil, errors.Errorf("%s codec: columns not defined, required to serialize events", c.extension)
+	}
+
+	values := make([]string, 0, len(c.columns))
+	for _, column := range c.columns {
+		value, err := csvValue(content[column])
+		if err != nil {
+			return nil, errors.Errorf("%s codec: column %s: %s", c.extension, column, err.Error())
+		}
+		values = append(values, value)
+	}
+
+	return c.line(values), nil
+}
+
+// Extension names the files targets write events serialized by the codec.
+func (c csvCodec) Extension() string {
+	return c.extension
+}
+
+func (c csvCodec) ContentType() string {
+	if c.extension == "tsv" {
+		return "text/tab-separated-values"
+	}
+
+	return "text/csv"
+}
+
+// Header is written once on top of every file when the header is enabled.
+func (c csvCodec) Header() []byte {
+	if !c.header || len(c.columns) == 0 {
+		return nil
+	}
+
+	return c.line(c.columns)
+}
+
+// line joins the values quoting them as encoding/csv does, or every one of
+// them when quote is all.
+func (c csvCodec) line(values []string) []byte {
+	var buffer bytes.Buffer
+	for i, value := range values {
+		if i > 0 {
+			buffer.WriteRune(c.delimiter)
+		}
+
+		if !c.quoteAll && !c.needsQuotes(value) {
+			buffer.WriteString(value)
+			continue
+		}
+
+		buffer.WriteByte('"')
+		buffer.WriteString(strings.ReplaceAll(value, `"`, `""`))
+		buffer.WriteByte('"')
+	}
+
+	return buffer.Bytes()
+}
+
+func (c csvCodec) needsQuotes(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	if value == `\.` || strings.ContainsRune(value, c.delimiter) || strings.ContainsAny(value, "\"\r\n") {
+		return true
+	}
+
+	r, _ := utf8.DecodeRuneInString(value)
+	return r == ' ' || r == '\t'
+}
+
+func csvValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		return fmt.Sprint(v), nil
+	}
+
+	// lists and objects are written as json
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(buffer.String(), "\n"), nil
+}
@@ -0,0 +1,86 @@
+package target
+
+import (
+	"reflect"
+	"testing"
+
+	"draethos.io.com/internal/interfaces"
+)
+
+func TestShouldDeserializeCsvWithColumnsOrHeader(t *testing.T) {
+	withColumns, err := NewCsvCodec(map[string]interface{}{"columns": []interface{}{"id", "name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := withColumns.Deserialize([]byte("1,\"Doe, Jane\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(data, map[string]interface{}{"id": "1", "name": "Doe, Jane"}) {
+		t.Errorf("unexpected event: %v", data)
+	}
+
+	withHeader, _ := NewTsvCodec(map[string]interface{}{"header": true})
+	data, err = withHeader.Deserialize([]byte("id\tname\n2\tJohn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(data, map[string]interface{}{"id": "2", "name": "John"}) {
+		t.Errorf("unexpected event: %v", data)
+	}
+
+	for _, content := range []string{"1", "1,a\n2,b", ""} {
+		if _, err := withColumns.Deserialize([]byte(content)); err == nil {
+			t.Errorf("expected %q to be rejected", content)
+		}
+	}
+}
+
+func TestShouldSerializeCsvInColumnOrder(t *testing.T) {
+	codec, _ := NewCsvCodec(map[string]interface{}{"columns": "id, amount, note, tags, missing", "header": true})
+
+	content, err := codec.Serialize(map[string]interface{}{
+		"amount": 1500000.5,
+		"id":     float64(7),
+		"note":   `said "hi", left`,
+		"tags":   []interface{}{"a", "b"},
+		"extra":  "ignored",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `7,1500000.5,"said ""hi"", left","[""a"",""b""]",`; string(content) != expected {
+		t.Errorf("expected %s, got %s", expected, content)
+	}
+
+	file, ok := codec.(interfaces.FileCodecInterface)
+	if !ok || file.Extension() != "csv" || string(file.Header()) != "id,amount,note,tags,missing" {
+		t.Errorf("expected csv file codec with header")
+	}
+
+	quoted, _ := NewCsvCodec(map[string]interface{}{"columns": []interface{}{"id", "name"}, "quote": "all", "delimiter": ";"})
+	if content, _ := quoted.Serialize(map[string]interface{}{"id": 1, "name": "x"}); string(content) != `"1";"x"` {
+		t.Errorf("expected every value quoted, got %s", content)
+	}
+}
+
+func TestShouldRejectInvalidCsvConfigurations(t *testing.T) {
+	for _, configurations := range []map[string]interface{}{
+		{},
+		{"columns": "id", "delimiter": "||"},
+		{"columns": "id", "quote": "never"},
+	} {
+		if _, err := NewCsvCodec(configurations); err == nil {
+			t.Errorf("expected %v to be rejected", configurations)
+		}
+	}
+
+	codec, _ := NewCsvCodec(map[string]interface{}{"header": true})
+	if _, err := codec.Serialize(map[string]interface{}{"id": 1}); err == nil {
+		t.Error("expected serialize without columns to be rejected")
+	}
+}
@@ -0,0 +1,245 @@
+package target
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
+
+	"draethos.io.com/internal/interfaces"
+	"github.com/DataDog/zstd"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+)
+
+// snappyStreamMagic starts payloads written in the snappy framing format
+// instead of a single snappy block.
+var snappyStreamMagic = []byte("\xff\x06\x00\x00sNaPpY")
+
+type gzipEncoding struct{}
+
+type zstdEncoding struct{}
+
+type snappyEncoding struct{}
+
+type base64Encoding struct{}
+
+// NewGzipEncoding compresses payloads as gzip, payloads made of several gzip
+// members are read as a whole.
+func NewGzipEncoding() interfaces.EncodingInterface {
+	return gzipEncoding{}
+}
+
+// NewZstdEncoding compresses payloads as a zstd frame.
+func NewZstdEncoding() interfaces.EncodingInterface {
+	return zstdEncoding{}
+}
+
+// NewSnappyEncoding compresses payloads as a single snappy block, payloads in
+// the snappy framing format are read as well.
+func NewSnappyEncoding() interfaces.EncodingInterface {
+	return snappyEncoding{}
+}
+
+// NewBase64Encoding writes payloads as standard base64 and reads both the
+// standard and the url alphabet, padded or not.
+func NewBase64Encoding() interfaces.EncodingInterface {
+	return base64Encoding{}
+}
+
+func (gzipEncoding) Decode(content []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Errorf("gzip encoding: %s", err.Error())
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Errorf("gzip encoding: %s", err.Error())
+	}
+
+	return data, nil
+}
+
+func (gzipEncoding) Encode(content []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(content); err != nil {
+		return nil, errors.Errorf("gzip encoding: %s", err.Error())
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, errors.Errorf("gzip encoding: %s", err.Error())
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (gzipEncoding) Extension() string {
+	return "gz"
+}
+
+func (zstdEncoding) Decode(content []byte) ([]byte, error) {
+	data, err := zstd.Decompress(nil, content)
+	if err != nil {
+		return nil, errors.Errorf("zstd encoding: %s", err.Error())
+	}
+
+	return data, nil
+}
+
+func (zstdEncoding) Encode(content []byte) ([]byte, error) {
+	data, err := zstd.Compress(nil, content)
+	if err != nil {
+		return nil, errors.Errorf("zstd encoding: %s", err.Error())
+	}
+
+	return data, nil
+}
+
+func (zstdEncoding) Extension() string {
+	return "zst"
+}
+
+func (snappyEncoding) Decode(content []byte) ([]byte, error) {
+	if bytes.HasPrefix(content, snappyStreamMagic) {
+		data, err := ioutil.ReadAll(snappy.NewReader(bytes.NewReader(content)))
+		if err != nil {
+			return nil, errors.Errorf("snappy encoding: %s", err.Error())
+		}
+
+		return data, nil
+	}
+
+	data, err := snappy.Decode(nil, content)
+	if err != nil {
+		return nil, errors.Errorf("snappy encoding: %s", err.Error())
+	}
+
+	return data, nil
+}
+
+func (snappyEncoding) Encode(content []byte) ([]byte, error) {
+	return snappy.Encode(nil, content), nil
+}
+
+func (snappyEncoding) Extension() string {
+	return "sz"
+}
+
+func (base64Encoding) Decode(content []byte) ([]byte, error) {
+	content = bytes.TrimSpace(content)
+
+	var err error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		data := make([]byte, encoding.DecodedLen(len(content)))
+
+		var n int
+		if n, err = encoding.Decode(data, content); err == nil {
+			return data[:n], nil
+		}
+	}
+
+	return nil, errors.Errorf("base64 encoding: %s", err.Error())
+}
+
+func (base64Encoding) Encode(content []byte) ([]byte, error) {
+	data := make([]byte, base64.StdEncoding.EncodedLen(len(content)))
+	base64.StdEncoding.Encode(data, content)
+
+	return data, nil
+}
+
+func (base64Encoding) Extension() string {
+	return "b64"
+}
+
+type chainCodec struct {
+	encodings []interfaces.EncodingInterface
+	codec     interfaces.CodecInterface
+}
+
+// fileChainCodec forwards the extension and the header of codecs writing files
+// of their own.
+type fileChainCodec struct {
+	chainCodec
+}
+
+// NewChainCodec decodes payloads through the encodings in order before the
+// codec reads them, and encodes what the codec writes in the reverse order.
+func NewChainCodec(encodings []interfaces.EncodingInterface, codec interfaces.CodecInterface) interfaces.EncodedCodecInterface {
+	chain := chainCodec{encodings: encodings, codec: codec}
+	if _, ok := codec.(interfaces.FileCodecInterface); ok {
+		return fileChainCodec{chain}
+	}
+
+	return chain
+}
+
+func (c chainCodec) Deserialize(content []byte) (map[string]interface{}, error) {
+	data, err := c.Decode(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.codec.Deserialize(data)
+}
+
+func (c chainCodec) Serialize(content map[string]interface{}) ([]byte, error) {
+	data, err := c.codec.Serialize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Encode(data)
+}
+
+// Codec returns the codec ending the chain.
+func (c chainCodec) Codec() interfaces.CodecInterface {
+	return c.codec
+}
+
+// Decode applies the encodings in the order of the chain.
+func (c chainCodec) Decode(content []byte) ([]byte, error) {
+	var err error
+	for _, encoding := range c.encodings {
+		if content, err = encoding.Decode(content); err != nil {
+			return nil, err
+		}
+	}
+
+	return content, nil
+}
+
+// Encode applies the encodings in the reverse order of the chain.
+func (c chainCodec) Encode(content []byte) ([]byte, error) {
+	var err error
+	for i := len(c.encodings) - 1; i >= 0; i-- {
+		if content, err = c.encodings[i].Encode(content); err != nil {
+			return nil, err
+		}
+	}
+
+	return content, nil
+}
+
+// Suffix joins the extensions of the encodings in the order they are applied,
+// such as .gz.b64 for base64|gzip.
+func (c chainCodec) Suffix() string {
+	var suffix strings.Builder
+	for i := len(c.encodings) - 1; i >= 0; i-- {
+		suffix.WriteString("." + c.encodings[i].Extension())
+	}
+
+	return suffix.String()
+}
+
+func (c fileChainCodec) Extension() string {
+	return c.codec.(interfaces.FileCodecInterface).Extension() + c.Suffix()
+}
+
+func (c fileChainCodec) Header() []byte {
+	return c.codec.(interfaces.FileCodecInterface).Header()
+}
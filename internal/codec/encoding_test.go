@@ -0,0 +1,140 @@
+package target
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"draethos.io.com/internal/interfaces"
+	"github.com/golang/snappy"
+)
+
+// lz4FrameTest was written by the lz4 command line with block checksums.
+const lz4FrameTest = "04224d187440bd130000009f6472616574686f732009000b506574686f731777da770000000001585c19"
+
+func TestShouldRoundTripEncodings(t *testing.T) {
+	payload := []byte(strings.Repeat(`{"id":"o-1","status":"created"}`, 5000))
+
+	encodings := map[string]interfaces.EncodingInterface{
+		"gzip":   NewGzipEncoding(),
+		"zstd":   NewZstdEncoding(),
+		"snappy": NewSnappyEncoding(),
+		"lz4":    NewLz4Encoding(),
+		"base64": NewBase64Encoding(),
+	}
+
+	for name, encoding := range encodings {
+		for _, content := range [][]byte{payload, []byte("{}"), {}} {
+			encoded, err := encoding.Encode(content)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			decoded, err := encoding.Decode(encoded)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			if !bytes.Equal(decoded, content) {
+				t.Errorf("%s: expected %d bytes, got %d", name, len(content), len(decoded))
+			}
+		}
+	}
+}
+
+func TestShouldDecodeLz4FramesOfOtherWriters(t *testing.T) {
+	frame, _ := hex.DecodeString(lz4FrameTest)
+
+	decoded, err := NewLz4Encoding().Decode(append(append([]byte{}, frame...), frame...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Repeat("draethos draethos draethos draethos draethos", 2)
+	if string(decoded) != expected {
+		t.Errorf("expected %q, got %q", expected, decoded)
+	}
+
+	frame[len(frame)-1] ^= 0xFF
+	if _, err = NewLz4Encoding().Decode(frame); err == nil {
+		t.Error("expected content checksum mismatch to be rejected")
+	}
+}
+
+func TestShouldDecodeAlternativeFormats(t *testing.T) {
+	for _, content := range []string{"eyJpZCI6MX0=", "eyJpZCI6MX0", " eyJpZCI6MX0=\n"} {
+		decoded, err := NewBase64Encoding().Decode([]byte(content))
+		if err != nil || string(decoded) != `{"id":1}` {
+			t.Errorf("base64 %q: expected {\"id\":1}, got %q, %v", content, decoded, err)
+		}
+	}
+
+	var stream bytes.Buffer
+	writer := snappy.NewBufferedWriter(&stream)
+	_, _ = writer.Write([]byte(`{"id":1}`))
+	_ = writer.Close()
+
+	decoded, err := NewSnappyEncoding().Decode(stream.Bytes())
+	if err != nil || string(decoded) != `{"id":1}` {
+		t.Errorf("snappy framing: expected {\"id\":1}, got %q, %v", decoded, err)
+	}
+}
+
+func TestShouldApplyChainInOrder(t *testing.T) {
+	codec := NewChainCodec([]interfaces.EncodingInterface{NewBase64Encoding(), NewGzipEncoding()}, NewJsonCodec())
+
+	content, err := codec.Serialize(map[string]interface{}{"id": "o-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the outer layer is the first one of the chain
+	compressed, err := NewBase64Encoding().Decode(content)
+	if err != nil {
+		t.Fatalf("expected base64 payload: %v", err)
+	}
+
+	if _, err = NewGzipEncoding().Decode(compressed); err != nil {
+		t.Fatalf("expected gzip inside base64: %v", err)
+	}
+
+	decoded, err := codec.Deserialize(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded["id"] != "o-1" {
+		t.Errorf("unexpected event: %v", decoded)
+	}
+
+	if _, err = codec.Deserialize([]byte(`{"id":"o-1"}`)); err == nil {
+		t.Error("expected payload missing the encodings to be rejected")
+	}
+}
+
+func TestShouldForwardFileCodecThroughChain(t *testing.T) {
+	csv, err := NewCsvCodec(map[string]interface{}{"columns": []interface{}{"id", "name"}, "header": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := NewChainCodec([]interfaces.EncodingInterface{NewBase64Encoding(), NewGzipEncoding()}, csv)
+	file, ok := chain.(interfaces.FileCodecInterface)
+	if !ok {
+		t.Fatal("expected chain to forward the file codec")
+	}
+
+	if file.Extension() != "csv.gz.b64" || string(file.Header()) != "id,name" {
+		t.Errorf("expected extension csv.gz.b64 and header id,name, got %s and %q", file.Extension(), file.Header())
+	}
+
+	jsonChain := NewChainCodec([]interfaces.EncodingInterface{NewZstdEncoding()}, NewJsonCodec())
+	if _, ok := jsonChain.(interfaces.FileCodecInterface); ok {
+		t.Error("expected chain of a codec without files not to be a file codec")
+	}
+
+	if jsonChain.Suffix() != ".zst" || jsonChain.Codec() == nil {
+		t.Errorf("expected suffix .zst, got %s", jsonChain.Suffix())
+	}
+}
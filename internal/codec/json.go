@@ -31,3 +31,7 @@ func (jsonCodec) Serialize(content map[string]interface{}) ([]byte, error) {
 
 	return data, nil
 }
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
@@ -0,0 +1,46 @@
+package target
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"draethos.io.com/internal/interfaces"
+	"github.com/pierrec/lz4/v4"
+	"github.com/pkg/errors"
+)
+
+type lz4Encoding struct{}
+
+// NewLz4Encoding compresses payloads as a lz4 frame, the format written by the
+// lz4 command line and by kafka clients. Frames with dependent blocks,
+// checksums and concatenated frames are read as well.
+func NewLz4Encoding() interfaces.EncodingInterface {
+	return lz4Encoding{}
+}
+
+func (lz4Encoding) Encode(content []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := lz4.NewWriter(&buffer)
+	if _, err := writer.Write(content); err != nil {
+		return nil, errors.Errorf("lz4 encoding: %s", err.Error())
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, errors.Errorf("lz4 encoding: %s", err.Error())
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (lz4Encoding) Decode(content []byte) ([]byte, error) {
+	data, err := ioutil.ReadAll(lz4.NewReader(bytes.NewReader(content)))
+	if err != nil {
+		return nil, errors.Errorf("lz4 encoding: %s", err.Error())
+	}
+
+	return data, nil
+}
+
+func (lz4Encoding) Extension() string {
+	return "lz4"
+}
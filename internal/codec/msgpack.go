@@ -0,0 +1,40 @@
+package target
+
+import (
+	"bytes"
+
+	"draethos.io.com/internal/interfaces"
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type msgpackCodec struct{}
+
+// NewMsgpackCodec reads and writes MessagePack maps. Integers are read as
+// int64, or uint64 when they do not fit, binary as []byte and timestamps as
+// time.Time, so events are written back with the same types.
+func NewMsgpackCodec() interfaces.CodecInterface {
+	return msgpackCodec{}
+}
+
+func (msgpackCodec) Deserialize(content []byte) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := msgpack.Unmarshal(content, &data); err != nil {
+		return nil, errors.Errorf("msgpack codec: %s", err.Error())
+	}
+
+	return widenIntegers(data).(map[string]interface{}), nil
+}
+
+func (msgpackCodec) Serialize(content map[string]interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := msgpack.NewEncoder(&buffer)
+	encoder.UseCompactInts(true)
+	encoder.SetSortMapKeys(true)
+
+	if err := encoder.Encode(content); err != nil {
+		return nil, errors.Errorf("msgpack codec: %s", err.Error())
+	}
+
+	return buffer.Bytes(), nil
+}
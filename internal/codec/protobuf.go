@@ -0,0 +1,157 @@
+package target
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type protobufCodec struct {
+	descriptor protoreflect.MessageDescriptor
+	marshal    protojson.MarshalOptions
+	unmarshal  protojson.UnmarshalOptions
+}
+
+type protobufConfigurations struct {
+	DescriptorFile string `config:"descriptor.file"`
+	Message        string `config:"message"`
+	UseProtoNames  bool   `config:"use.proto.names"`
+	EmitDefaults   bool   `config:"emit.defaults"`
+	DiscardUnknown bool   `config:"discard.unknown"`
+}
+
+// NewProtobufCodec reads and writes binary protobuf messages of the type
+// informed, described by a FileDescriptorSet such as the one generated by
+// protoc --descriptor_set_out --include_imports. Events follow the protojson
+// mapping of the message.
+func NewProtobufCodec(configurations map[string]interface{}) (interfaces.CodecInterface, error) {
+	var config protobufConfigurations
+	if err := specs.DecodeConfigurations(configurations, &config); err != nil {
+		return nil, errors.Errorf("protobuf codec: %s", err.Error())
+	}
+
+	if config.DescriptorFile == "" {
+		return nil, errors.Errorf("protobuf codec: descriptor.file not defined")
+	}
+
+	if config.Message == "" {
+		return nil, errors.Errorf("protobuf codec: message not defined")
+	}
+
+	files, err := loadDescriptorSet(config.DescriptorFile)
+	if err != nil {
+		return nil, errors.Errorf("protobuf codec: %s", err.Error())
+	}
+
+	found, err := files.FindDescriptorByName(protoreflect.FullName(config.Message))
+	if err != nil {
+		return nil, errors.Errorf("protobuf codec: message %s not found in %s", config.Message, config.DescriptorFile)
+	}
+
+	descriptor, ok := found.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, errors.Errorf("protobuf codec: %s is not a message", config.Message)
+	}
+
+	// google.protobuf.Any fields are resolved against the messages of the set
+	types := messageTypes(files)
+
+	return protobufCodec{
+		descriptor: descriptor,
+		marshal: protojson.MarshalOptions{
+			UseProtoNames:   config.UseProtoNames,
+			EmitUnpopulated: config.EmitDefaults,
+			Resolver:        types,
+		},
+		unmarshal: protojson.UnmarshalOptions{
+			DiscardUnknown: config.DiscardUnknown,
+			Resolver:       types,
+		},
+	}, nil
+}
+
+func (p protobufCodec) Deserialize(content []byte) (map[string]interface{}, error) {
+	message := dynamicpb.NewMessage(p.descriptor)
+	if err := proto.Unmarshal(content, message); err != nil {
+		return nil, errors.Errorf("protobuf codec: failed to decode %s: %s", p.descriptor.FullName(), err.Error())
+	}
+
+	textual, err := p.marshal.Marshal(message)
+	if err != nil {
+		return nil, errors.Errorf("protobuf codec: %s", err.Error())
+	}
+
+	var data map[string]interface{}
+	if err = json.Unmarshal(textual, &data); err != nil {
+		return nil, errors.Errorf("protobuf codec: %s", err.Error())
+	}
+
+	return data, nil
+}
+
+func (p protobufCodec) Serialize(content map[string]interface{}) ([]byte, error) {
+	textual, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	message := dynamicpb.NewMessage(p.descriptor)
+	if err = p.unmarshal.Unmarshal(textual, message); err != nil {
+		return nil, errors.Errorf("protobuf codec: event does not match %s: %s", p.descriptor.FullName(), err.Error())
+	}
+
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return nil, errors.Errorf("protobuf codec: %s", err.Error())
+	}
+
+	return data, nil
+}
+
+func loadDescriptorSet(path string) (*protoregistry.Files, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Errorf("failed to read descriptor.file: %s", err.Error())
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err = proto.Unmarshal(content, &set); err != nil {
+		return nil, errors.Errorf("%s is not a FileDescriptorSet: %s", path, err.Error())
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, errors.Errorf("invalid descriptor set %s, generate it with --include_imports: %s", path, err.Error())
+	}
+
+	return files, nil
+}
+
+func messageTypes(files *protoregistry.Files) *protoregistry.Types {
+	types := new(protoregistry.Types)
+
+	var register func(messages protoreflect.MessageDescriptors)
+	register = func(messages protoreflect.MessageDescriptors) {
+		for i := 0; i < messages.Len(); i++ {
+			_ = types.RegisterMessage(dynamicpb.NewMessageType(messages.Get(i)))
+			register(messages.Get(i).Messages())
+		}
+	}
+
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		register(file.Messages())
+		return true
+	})
+
+	return types
+}
@@ -0,0 +1,126 @@
+package target
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func writeDescriptorSetTest(t *testing.T) string {
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   kind.Enum(),
+			Label:  label.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("shop/v1/order.proto"),
+		Package: proto.String("shop.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Order"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("order_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+				field("total", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, optional, ""),
+				field("items", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_LABEL_REPEATED, ".shop.v1.Order.Item"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name:  proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("sku", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, "")},
+			}},
+		}},
+	}}}
+
+	content, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "order.pb")
+	if err = ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestShouldRoundTripProtobufWithProtojsonNames(t *testing.T) {
+	codec, err := NewProtobufCodec(map[string]interface{}{
+		"descriptor.file": writeDescriptorSetTest(t),
+		"message":         "shop.v1.Order",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := codec.Serialize(map[string]interface{}{
+		"orderId": "o-1",
+		"total":   10.5,
+		"items":   []interface{}{map[string]interface{}{"sku": "a"}, map[string]interface{}{"sku": "b"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := codec.Deserialize(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, _ := decoded["items"].([]interface{})
+	if decoded["orderId"] != "o-1" || decoded["total"] != 10.5 || len(items) != 2 {
+		t.Errorf("unexpected event: %v", decoded)
+	}
+}
+
+func TestShouldFollowProtobufOptions(t *testing.T) {
+	path := writeDescriptorSetTest(t)
+
+	strict, _ := NewProtobufCodec(map[string]interface{}{"descriptor.file": path, "message": "shop.v1.Order"})
+	if _, err := strict.Serialize(map[string]interface{}{"orderId": "o-1", "topic": "orders"}); err == nil {
+		t.Error("expected unknown field to be rejected")
+	}
+
+	lenient, _ := NewProtobufCodec(map[string]interface{}{
+		"descriptor.file": path,
+		"message":         "shop.v1.Order",
+		"use.proto.names": true,
+		"emit.defaults":   "true",
+		"discard.unknown": true,
+	})
+
+	content, err := lenient.Serialize(map[string]interface{}{"order_id": "o-1", "topic": "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := lenient.Deserialize(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded["order_id"] != "o-1" || decoded["total"] != 0.0 || decoded["topic"] != nil {
+		t.Errorf("unexpected event: %v", decoded)
+	}
+}
+
+func TestShouldRejectUnknownProtobufMessage(t *testing.T) {
+	if _, err := NewProtobufCodec(map[string]interface{}{"descriptor.file": writeDescriptorSetTest(t), "message": "shop.v1.Missing"}); err == nil {
+		t.Error("expected unknown message to be rejected")
+	}
+
+	if _, err := NewProtobufCodec(map[string]interface{}{"message": "shop.v1.Order"}); err == nil {
+		t.Error("expected missing descriptor.file to be rejected")
+	}
+}
@@ -0,0 +1,145 @@
+package target
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	schemaRegistryContentType = "application/vnd.schemaregistry.v1+json"
+	SchemaRegistryTimeout     = 10 * time.Second
+)
+
+// schemaRegistry is a client of the Confluent Schema Registry REST API.
+type schemaRegistry struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+type registrySchema struct {
+	Id         int    `json:"id"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+type registryError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func newSchemaRegistry(registryUrl string, username string, password string, timeout time.Duration) *schemaRegistry {
+	if timeout <= 0 {
+		timeout = SchemaRegistryTimeout
+	}
+
+	return &schemaRegistry{
+		url:      strings.TrimRight(registryUrl, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// SchemaById fetches the schema registered with the id.
+func (r *schemaRegistry) SchemaById(id int) (string, error) {
+	var schema registrySchema
+	if err := r.do(http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &schema); err != nil {
+		return "", errors.Errorf("failed to fetch schema %d: %s", id, err.Error())
+	}
+
+	return schema.Schema, nil
+}
+
+// Latest fetches the latest schema version of the subject.
+func (r *schemaRegistry) Latest(subject string) (registrySchema, error) {
+	var schema registrySchema
+	if err := r.do(http.MethodGet, fmt.Sprintf("/subjects/%s/versions/latest", url.PathEscape(subject)), nil, &schema); err != nil {
+		return registrySchema{}, errors.Errorf("failed to fetch latest schema of subject %s: %s", subject, err.Error())
+	}
+
+	return schema, nil
+}
+
+// Lookup returns the id of the schema under the subject, it fails when the
+// schema is not registered.
+func (r *schemaRegistry) Lookup(subject string, schema string) (int, error) {
+	var registered registrySchema
+	if err := r.do(http.MethodPost, fmt.Sprintf("/subjects/%s", url.PathEscape(subject)), registrySchema{Schema: schema}, &registered); err != nil {
+		return 0, errors.Errorf("schema not found under subject %s: %s", subject, err.Error())
+	}
+
+	return registered.Id, nil
+}
+
+// Register adds the schema to the subject, returning the id of the schema
+// already registered when it is the same.
+func (r *schemaRegistry) Register(subject string, schema string) (int, error) {
+	var registered registrySchema
+	if err := r.do(http.MethodPost, fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject)), registrySchema{Schema: schema}, &registered); err != nil {
+		return 0, errors.Errorf("failed to register schema under subject %s: %s", subject, err.Error())
+	}
+
+	return registered.Id, nil
+}
+
+func (r *schemaRegistry) do(method string, path string, body interface{}, out interface{}) error {
+	reader := bytes.NewReader(nil)
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	request, err := http.NewRequest(method, r.url+path, reader)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", schemaRegistryContentType)
+	if body != nil {
+		request.Header.Set("Content-Type", schemaRegistryContentType)
+	}
+
+	if r.username != "" {
+		request.SetBasicAuth(r.username, r.password)
+	}
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		var registryErr registryError
+		if json.Unmarshal(content, &registryErr) == nil && registryErr.Message != "" {
+			return errors.Errorf("%s (%d)", registryErr.Message, registryErr.ErrorCode)
+		}
+
+		return errors.Errorf("unexpected status %s", response.Status)
+	}
+
+	if err = json.Unmarshal(content, out); err != nil {
+		return errors.Errorf("invalid response: %s", err.Error())
+	}
+
+	return nil
+}
@@ -1,33 +1,271 @@
 package target
 
 import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
 	"draethos.io.com/internal/interfaces"
 	"draethos.io.com/pkg/streams/specs"
-	"encoding/xml"
+	"github.com/pkg/errors"
+)
+
+const (
+	XmlDefaultRoot            = "root"
+	XmlDefaultAttributePrefix = "@"
+	XmlDefaultTextKey         = "#text"
 )
 
 type xmlCodec struct {
-	targetSpec specs.Target
+	configurations xmlConfigurations
+	// prefixes maps the namespace uris to the prefix their elements are named
+	// with, elements of other namespaces keep only their local name.
+	prefixes map[string]string
+}
+
+type xmlConfigurations struct {
+	Root            string            `config:"root"`
+	AttributePrefix string            `config:"attribute.prefix"`
+	TextKey         string            `config:"text.key"`
+	Namespace       string            `config:"namespace"`
+	Namespaces      map[string]string `config:"namespaces"`
+}
+
+// NewXmlCodec converts xml documents to events and back. The root element is
+// not part of the event, attributes are keys starting with the attribute
+// prefix, repeated elements are lists and the text of elements that also have
+// attributes or children is kept under the text key.
+func NewXmlCodec(configurations map[string]interface{}) (interfaces.CodecInterface, error) {
+	config := xmlConfigurations{
+		Root:            XmlDefaultRoot,
+		AttributePrefix: XmlDefaultAttributePrefix,
+		TextKey:         XmlDefaultTextKey,
+	}
+
+	if err := specs.DecodeConfigurations(configurations, &config); err != nil {
+		return nil, errors.Errorf("xml codec: %s", err.Error())
+	}
+
+	if config.Root == "" {
+		return nil, errors.Errorf("xml codec: root must not be empty")
+	}
+
+	if config.TextKey == "" {
+		return nil, errors.Errorf("xml codec: text.key must not be empty")
+	}
+
+	prefixes := make(map[string]string, len(config.Namespaces))
+	for prefix, uri := range config.Namespaces {
+		prefixes[uri] = prefix
+	}
+
+	return xmlCodec{configurations: config, prefixes: prefixes}, nil
 }
 
-func NewXmlCodec() interfaces.CodecInterface {
-	return xmlCodec{}
+type xmlElement struct {
+	fields map[string]interface{}
+	text   strings.Builder
+}
+
+func (x xmlCodec) Deserialize(content []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+
+	stack := make([]*xmlElement, 0)
+	names := make([]string, 0)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, errors.Errorf("xml codec: %s", err.Error())
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{fields: map[string]interface{}{}}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				element.fields[x.configurations.AttributePrefix+x.name(attr.Name)] = attr.Value
+			}
+
+			stack = append(stack, element)
+			names = append(names, x.name(t.Name))
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			element, name := stack[len(stack)-1], names[len(names)-1]
+			stack, names = stack[:len(stack)-1], names[:len(names)-1]
+
+			if len(stack) == 0 {
+				if data, ok := x.value(element).(map[string]interface{}); ok {
+					return data, nil
+				}
+
+				return map[string]interface{}{x.configurations.TextKey: strings.TrimSpace(element.text.String())}, nil
+			}
+
+			appendXmlValue(stack[len(stack)-1].fields, name, x.value(element))
+		}
+	}
+
+	return nil, errors.Errorf("xml codec: document has no root element")
+}
+
+func (xmlCodec) ContentType() string {
+	return "application/xml"
+}
+
+func (x xmlCodec) value(element *xmlElement) interface{} {
+	text := strings.TrimSpace(element.text.String())
+	if len(element.fields) == 0 {
+		return text
+	}
+
+	if text != "" {
+		element.fields[x.configurations.TextKey] = text
+	}
+
+	return element.fields
 }
 
-func (xmlCodec) Deserialize(content []byte) (map[string]interface{}, error) {
-	var data map[string]interface{}
-	if err := xml.Unmarshal(content, &data); err != nil {
-		return nil, err
+func (x xmlCodec) name(name xml.Name) string {
+	if prefix, ok := x.prefixes[name.Space]; ok && prefix != "" {
+		return prefix + ":" + name.Local
+	}
+
+	return name.Local
+}
+
+// appendXmlValue turns the key into a list once the element is repeated.
+func appendXmlValue(fields map[string]interface{}, name string, value interface{}) {
+	current, ok := fields[name]
+	if !ok {
+		fields[name] = value
+		return
+	}
+
+	if list, ok := current.([]interface{}); ok {
+		fields[name] = append(list, value)
+		return
+	}
+
+	fields[name] = []interface{}{current, value}
+}
+
+func (x xmlCodec) Serialize(content map[string]interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := xml.NewEncoder(&buffer)
+
+	root := xml.StartElement{Name: xml.Name{Local: x.configurations.Root}}
+	if x.configurations.Namespace != "" {
+		root.Attr = append(root.Attr, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: x.configurations.Namespace})
+	}
+
+	prefixes := make([]string, 0, len(x.configurations.Namespaces))
+	for prefix := range x.configurations.Namespaces {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		root.Attr = append(root.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: x.configurations.Namespaces[prefix]})
+	}
+
+	if err := x.encode(encoder, root, content); err != nil {
+		return nil, errors.Errorf("xml codec: %s", err.Error())
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, errors.Errorf("xml codec: %s", err.Error())
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (x xmlCodec) encode(encoder *xml.Encoder, start xml.StartElement, value interface{}) error {
+	fields, ok := toXmlFields(value)
+	if !ok {
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+
+		if value != nil {
+			if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(value))); err != nil {
+				return err
+			}
+		}
+
+		return encoder.EncodeToken(start.End())
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	children := make([]string, 0, len(keys))
+	for _, key := range keys {
+		switch {
+		case key == x.configurations.TextKey:
+		case x.configurations.AttributePrefix != "" && strings.HasPrefix(key, x.configurations.AttributePrefix):
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: strings.TrimPrefix(key, x.configurations.AttributePrefix)},
+				Value: fmt.Sprint(fields[key]),
+			})
+		default:
+			children = append(children, key)
+		}
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if text, ok := fields[x.configurations.TextKey]; ok && text != nil {
+		if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(text))); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range children {
+		child := xml.StartElement{Name: xml.Name{Local: key}}
+
+		items, ok := fields[key].([]interface{})
+		if !ok {
+			items = []interface{}{fields[key]}
+		}
+
+		for _, item := range items {
+			if err := x.encode(encoder, child, item); err != nil {
+				return err
+			}
+		}
 	}
 
-	return data, nil
+	return encoder.EncodeToken(start.End())
 }
 
-func (xmlCodec) Serialize(content map[string]interface{}) ([]byte, error) {
-	data, err := xml.Marshal(content)
-	if err != nil {
-		return nil, err
+func toXmlFields(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			fields[fmt.Sprint(key)] = item
+		}
+		return fields, true
 	}
 
-	return data, nil
+	return nil, false
 }
@@ -0,0 +1,85 @@
+package target
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShouldDeserializeXmlWithAttributesAndLists(t *testing.T) {
+	codec, err := NewXmlCodec(map[string]interface{}{
+		"namespaces": map[interface{}]interface{}{"soap": "http://schemas.xmlsoap.org/soap/envelope/"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := codec.Deserialize([]byte(`<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:o="urn:orders">
+  <soap:Body>
+    <o:order id="42" status="paid">
+      <o:item sku="a">first</o:item>
+      <o:item sku="b"/>
+      <o:note>handle with care</o:note>
+    </o:order>
+  </soap:Body>
+</soap:Envelope>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"soap:Body": map[string]interface{}{
+			"order": map[string]interface{}{
+				"@id":     "42",
+				"@status": "paid",
+				"item": []interface{}{
+					map[string]interface{}{"@sku": "a", "#text": "first"},
+					map[string]interface{}{"@sku": "b"},
+				},
+				"note": "handle with care",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected %v, got %v", expected, data)
+	}
+}
+
+func TestShouldRoundTripXml(t *testing.T) {
+	codec, _ := NewXmlCodec(map[string]interface{}{"root": "order", "attribute.prefix": "-", "text.key": "value", "namespace": "urn:orders"})
+
+	event := map[string]interface{}{
+		"-id":   "42",
+		"items": map[string]interface{}{"item": []interface{}{"a", "b"}},
+		"note":  map[string]interface{}{"-lang": "en", "value": "fragile"},
+	}
+
+	content, err := codec.Serialize(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(content), `<order xmlns="urn:orders" id="42"><items><item>a</item><item>b</item></items>`) {
+		t.Errorf("unexpected document %s", content)
+	}
+
+	decoded, err := codec.Deserialize(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, event) {
+		t.Errorf("expected %v, got %v", event, decoded)
+	}
+}
+
+func TestShouldRejectInvalidXml(t *testing.T) {
+	codec, _ := NewXmlCodec(nil)
+	for _, content := range []string{`<order><id>1</order>`, ``, `plain text`} {
+		if _, err := codec.Deserialize([]byte(content)); err == nil {
+			t.Errorf("expected %q to be rejected", content)
+		}
+	}
+}
@@ -31,3 +31,7 @@ func (yamlCodec) Serialize(content map[string]interface{}) ([]byte, error) {
 
 	return data, nil
 }
+
+func (yamlCodec) ContentType() string {
+	return "application/yaml"
+}
@@ -3,11 +3,11 @@ package internal
 import (
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"strings"
 
+	"draethos.io.com/internal/logging"
+	"draethos.io.com/internal/schema"
 	"draethos.io.com/pkg/streams/specs"
-	"gopkg.in/yaml.v2"
 )
 
 type ConfigBuilder interface {
@@ -15,20 +15,30 @@ type ConfigBuilder interface {
 	validateExtension() error
 	SetPort(port string) ConfigBuilder
 	SetFile(filePath string) ConfigBuilder
+	SetProfile(profile string) ConfigBuilder
+	SetLogging(logging specs.Logging) ConfigBuilder
 	IsEnabledLiveness() bool
 	IsEnabledMetrics() bool
+	IsEnabledWatch() bool
 	EnableLiveness() ConfigBuilder
 	EnableMetrics() ConfigBuilder
+	EnableWatch() ConfigBuilder
 	GetHttpPort() string
+	GetFile() string
+	GetFiles() []string
+	Validate() error
 	Build() (*specs.Stream, error)
 }
 
 type configBuilder struct {
 	filePath       string
+	profile        string
 	enableLiveness bool
 	enableMetrics  bool
+	enableWatch    bool
 	httpPort       string
-	file           []byte
+	logging        specs.Logging
+	document       *layeredDocument
 }
 
 func NewConfigBuilder() ConfigBuilder {
@@ -36,11 +46,7 @@ func NewConfigBuilder() ConfigBuilder {
 }
 
 func (c *configBuilder) Build() (*specs.Stream, error) {
-	if err := c.readFile(); err != nil {
-		return nil, err
-	}
-
-	if err := c.validateExtension(); err != nil {
+	if err := c.Validate(); err != nil {
 		return nil, err
 	}
 
@@ -52,10 +58,41 @@ func (c *configBuilder) Build() (*specs.Stream, error) {
 	return stream, nil
 }
 
+// Validate reads the pipeline file, its includes and profile and checks the
+// result against the schema, the returned error is a schema.Problems when the
+// content is invalid.
+func (c *configBuilder) Validate() error {
+	if err := c.validateExtension(); err != nil {
+		return err
+	}
+
+	if err := c.readFile(); err != nil {
+		return err
+	}
+
+	if problems := schema.ValidateNode(c.document.root, c.document.origins); len(problems) > 0 {
+		return problems
+	}
+
+	return nil
+}
+
 func (c *configBuilder) GetHttpPort() string {
 	return c.httpPort
 }
 
+func (c *configBuilder) GetFile() string {
+	return c.filePath
+}
+
+func (c *configBuilder) GetFiles() []string {
+	if c.document == nil {
+		return []string{c.filePath}
+	}
+
+	return c.document.files
+}
+
 func (c *configBuilder) IsEnabledLiveness() bool {
 	return c.enableLiveness
 }
@@ -64,6 +101,10 @@ func (c *configBuilder) IsEnabledMetrics() bool {
 	return c.enableMetrics
 }
 
+func (c *configBuilder) IsEnabledWatch() bool {
+	return c.enableWatch
+}
+
 func (c *configBuilder) EnableLiveness() ConfigBuilder {
 	c.enableLiveness = true
 	return c
@@ -74,6 +115,11 @@ func (c *configBuilder) EnableMetrics() ConfigBuilder {
 	return c
 }
 
+func (c *configBuilder) EnableWatch() ConfigBuilder {
+	c.enableWatch = true
+	return c
+}
+
 func (c *configBuilder) SetPort(port string) ConfigBuilder {
 	c.httpPort = port
 	return c
@@ -84,6 +130,18 @@ func (c *configBuilder) SetFile(filePath string) ConfigBuilder {
 	return c
 }
 
+func (c *configBuilder) SetProfile(profile string) ConfigBuilder {
+	c.profile = profile
+	return c
+}
+
+// SetLogging overrides the logging configuration of the pipeline file, such
+// as the levels informed by flag.
+func (c *configBuilder) SetLogging(logging specs.Logging) ConfigBuilder {
+	c.logging = logging
+	return c
+}
+
 func (c *configBuilder) validateExtension() error {
 	if strings.LastIndex(c.filePath, ".yml") > 0 {
 		return nil
@@ -97,20 +155,22 @@ func (c *configBuilder) validateExtension() error {
 }
 
 func (c *configBuilder) readFile() error {
-	file, err := ioutil.ReadFile(c.filePath)
+	document, err := loadLayeredDocument(c.filePath, c.profile)
 	if err != nil {
-		return errors.New(fmt.Sprintf("failed to load %s file, make sure the path was passed correctly", c.filePath))
+		return err
 	}
 
-	c.file = file
+	c.document = document
 	return nil
 }
 
 func (c *configBuilder) deserializeYaml() (*specs.Stream, error) {
 	var stream specs.Stream
-	if err := yaml.Unmarshal(c.file, &stream); err != nil {
-		return nil, errors.New(fmt.Sprintf("failed to deserialize %s file, make sure the path was passed correctly", c.filePath))
+	if err := c.document.root.Decode(&stream); err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to deserialize %s file: %s", c.filePath, err.Error()))
 	}
 
+	stream.Stream.Logging = logging.Merge(stream.Stream.Logging, c.logging)
+
 	return &stream, nil
 }
@@ -3,25 +3,126 @@ package context
 import (
 	target2 "draethos.io.com/internal/codec"
 	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
 const (
-	JsonCodec = "json"
-	YamlCodec = "yaml"
-	XmlCodec  = "xml"
+	JsonCodec     = "json"
+	YamlCodec     = "yaml"
+	XmlCodec      = "xml"
+	AvroCodec     = "avro"
+	ProtobufCodec = "protobuf"
+	CsvCodec      = "csv"
+	TsvCodec      = "tsv"
+	MsgpackCodec  = "msgpack"
+	CborCodec     = "cbor"
 )
 
-func NewCodecContext(codec string) interfaces.CodecInterface {
+const (
+	GzipEncoding   = "gzip"
+	ZstdEncoding   = "zstd"
+	SnappyEncoding = "snappy"
+	Lz4Encoding    = "lz4"
+	Base64Encoding = "base64"
+)
+
+// binaryCodecs write payloads that are not text, queues and topics only carry
+// them once the chain starts with base64.
+var binaryCodecs = map[string]bool{
+	AvroCodec:      true,
+	ProtobufCodec:  true,
+	MsgpackCodec:   true,
+	CborCodec:      true,
+	GzipEncoding:   true,
+	ZstdEncoding:   true,
+	SnappyEncoding: true,
+	Lz4Encoding:    true,
+}
+
+// NewCodecContext creates the codec ending the chain, the configurations are
+// the ones of that codec, wrapped by the encodings that precede it.
+func NewCodecContext(codec specs.Codec, configurations map[string]interface{}) (interfaces.CodecInterface, error) {
+	if _, err := NewEncodingContext(codec.Format()); err == nil {
+		return nil, errors.Errorf("codec %s: %s is an encoding, the chain must end with a codec such as %s", codec, codec.Format(), JsonCodec)
+	}
+
+	chain := codec.Chain()
+	if len(chain) <= 1 {
+		return newFormatCodec(codec.Format(), configurations)
+	}
+
+	encodings := make([]interfaces.EncodingInterface, 0, len(chain)-1)
+	for _, name := range chain[:len(chain)-1] {
+		encoding, err := NewEncodingContext(name)
+		if err != nil {
+			return nil, errors.Errorf("codec %s: %s", codec, err.Error())
+		}
+		encodings = append(encodings, encoding)
+	}
+
+	format, err := newFormatCodec(codec.Format(), configurations)
+	if err != nil {
+		return nil, err
+	}
+
+	return target2.NewChainCodec(encodings, format), nil
+}
+
+func NewEncodingContext(encoding string) (interfaces.EncodingInterface, error) {
+	switch encoding {
+	case GzipEncoding:
+		return target2.NewGzipEncoding(), nil
+	case ZstdEncoding:
+		return target2.NewZstdEncoding(), nil
+	case SnappyEncoding:
+		return target2.NewSnappyEncoding(), nil
+	case Lz4Encoding:
+		return target2.NewLz4Encoding(), nil
+	case Base64Encoding:
+		return target2.NewBase64Encoding(), nil
+	default:
+		return nil, errors.Errorf("encoding %s not supported", encoding)
+	}
+}
+
+func newFormatCodec(codec string, configurations map[string]interface{}) (interfaces.CodecInterface, error) {
 	switch codec {
 	case JsonCodec:
-		return target2.NewJsonCodec()
+		return target2.NewJsonCodec(), nil
 	case YamlCodec:
-		return target2.NewYamlCodec()
+		return target2.NewYamlCodec(), nil
 	case XmlCodec:
-		return target2.NewYamlCodec()
+		return target2.NewXmlCodec(configurations)
+	case AvroCodec:
+		return target2.NewAvroCodec(configurations)
+	case ProtobufCodec:
+		return target2.NewProtobufCodec(configurations)
+	case CsvCodec:
+		return target2.NewCsvCodec(configurations)
+	case TsvCodec:
+		return target2.NewTsvCodec(configurations)
+	case MsgpackCodec:
+		return target2.NewMsgpackCodec(), nil
+	case CborCodec:
+		return target2.NewCborCodec()
 	default:
 		zap.S().Infof("%s codec not defined, using %s as standard", codec, JsonCodec)
-		return target2.NewJsonCodec()
+		return target2.NewJsonCodec(), nil
 	}
 }
+
+// isTextCodec tells whether the payloads written through the chain are text,
+// which depends on the first name of the chain as it is applied last.
+func isTextCodec(codec specs.Codec) bool {
+	chain := codec.Chain()
+
+	return len(chain) == 0 || !binaryCodecs[chain[0]]
+}
+
+// isTextRecord tells whether the codec ending the chain writes text records,
+// which files joining records by line can be split back into.
+func isTextRecord(codec specs.Codec) bool {
+	return !binaryCodecs[codec.Format()]
+}
@@ -11,10 +11,11 @@ import (
 )
 
 const (
-	KafkaSource = "kafka"
-	HttpSource  = "http"
-	CsvSource   = "csv"
-	JsonLSource = "jsonl"
+	KafkaSource     = "kafka"
+	HttpSource      = "http"
+	CsvSource       = "csv"
+	JsonLSource     = "jsonl"
+	GeneratorSource = "generator"
 )
 
 func NewSourceContext(stream specs.Stream,
@@ -22,29 +23,39 @@ func NewSourceContext(stream specs.Stream,
 	dlq interfaces2.TargetInterface,
 	router *mux.Router,
 	port string) (interfaces2.SourceInterface, error) {
-	switch stream.Stream.Instance.Source.Type {
+	source := stream.Stream.Instance.Source
+	if source.Type == GeneratorSource {
+		return source2.NewGeneratorSource(source, target, dlq)
+	}
+
+	codec, err := NewCodecContext(source.Codec, source.CodecConfigurations)
+	if err != nil {
+		return nil, err
+	}
+
+	switch source.Type {
 	case KafkaSource:
 		return source2.NewKafkaSource(stream.Stream.Instance.Source,
 			target,
 			dlq,
-			NewCodecContext(stream.Stream.Instance.Source.Codec))
+			codec)
 	case HttpSource:
 		return source2.NewHttpSource(stream.Stream.Instance.Source,
 			target,
 			dlq,
-			NewCodecContext(stream.Stream.Instance.Source.Codec),
+			codec,
 			router,
 			port)
 	case CsvSource:
 		return source2.NewCsvSource(stream.Stream.Instance.Source,
 			target,
 			dlq,
-			NewCodecContext(stream.Stream.Instance.Source.Codec))
+			codec)
 	case JsonLSource:
 		return source2.NewJsonLSource(stream.Stream.Instance.Source,
 			target,
 			dlq,
-			NewCodecContext(stream.Stream.Instance.Source.Codec))
+			codec)
 	default:
 		return nil, errors.New(fmt.Sprintf("source %s is invalid", stream.Stream.Instance.Target.Type))
 	}
@@ -19,19 +19,34 @@ const (
 )
 
 func NewTargetContext(targetSpec specs.Target) (interfaces.TargetInterface, error) {
+	if (targetSpec.Type == SqsTarget || targetSpec.Type == SnsTarget) && !isTextCodec(targetSpec.TargetSpecs.Codec) {
+		return nil, errors.New(fmt.Sprintf("%s target: codec %s writes binary payloads, start the chain with %s such as %s|%s",
+			targetSpec.Type, targetSpec.TargetSpecs.Codec, Base64Encoding, Base64Encoding, targetSpec.TargetSpecs.Codec))
+	}
+
+	if targetSpec.Type == S3Target && !isTextRecord(targetSpec.TargetSpecs.Codec) {
+		return nil, errors.New(fmt.Sprintf("%s target: codec %s writes binary records, which files joined by line breaks can't be split back into, use a text codec such as %s",
+			targetSpec.Type, targetSpec.TargetSpecs.Codec, JsonCodec))
+	}
+
+	codec, err := NewCodecContext(targetSpec.TargetSpecs.Codec, targetSpec.TargetSpecs.CodecConfigurations)
+	if err != nil {
+		return nil, err
+	}
+
 	switch targetSpec.Type {
 	case KafkaTarget:
-		return target2.NewKafkaTarget(targetSpec, NewCodecContext(targetSpec.TargetSpecs.Codec))
+		return target2.NewKafkaTarget(targetSpec, codec)
 	case S3Target:
-		return target2.NewS3Target(targetSpec, NewCodecContext(targetSpec.TargetSpecs.Codec))
+		return target2.NewS3Target(targetSpec, codec)
 	case PgSqlTarget:
-		return target2.NewPgsqlTarget(targetSpec, NewCodecContext(targetSpec.TargetSpecs.Codec))
+		return target2.NewPgsqlTarget(targetSpec, codec)
 	case MySqlTarget:
-		return target2.NewMysqlTarget(targetSpec, NewCodecContext(targetSpec.TargetSpecs.Codec))
+		return target2.NewMysqlTarget(targetSpec, codec)
 	case SqsTarget:
-		return target2.NewSqsTarget(targetSpec, NewCodecContext(targetSpec.TargetSpecs.Codec))
+		return target2.NewSqsTarget(targetSpec, codec)
 	case SnsTarget:
-		return target2.NewSnsTarget(targetSpec, NewCodecContext(targetSpec.TargetSpecs.Codec))
+		return target2.NewSnsTarget(targetSpec, codec)
 	default:
 		return nil, errors.New(fmt.Sprintf("target %s is invalid", targetSpec.Type))
 	}
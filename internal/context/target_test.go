@@ -0,0 +1,43 @@
+package context
+
+import (
+	"strings"
+	"testing"
+
+	"draethos.io.com/pkg/streams/specs"
+)
+
+func TestShouldRejectBinaryCodecsOnQueuesTopicsAndFiles(t *testing.T) {
+	tests := []struct {
+		target string
+		codec  specs.Codec
+		valid  bool
+	}{
+		{SqsTarget, "json", true},
+		{SqsTarget, "", true},
+		{SqsTarget, "msgpack", false},
+		{SqsTarget, "base64|msgpack", true},
+		{SqsTarget, "protobuf", false},
+		{SnsTarget, "cbor", false},
+		{SnsTarget, "base64|cbor", true},
+		{SnsTarget, "base64|gzip|json", true},
+		{SnsTarget, "gzip|base64|json", false},
+		{SnsTarget, "zstd|json", false},
+		{S3Target, "gzip|json", true},
+		{S3Target, "yaml", true},
+		{S3Target, "msgpack", false},
+		{S3Target, "base64|msgpack", false},
+		{S3Target, "zstd|avro", false},
+	}
+
+	for _, test := range tests {
+		_, err := NewTargetContext(specs.Target{Type: test.target, TargetSpecs: specs.TargetSpecs{Codec: test.codec}})
+		if test.valid && err != nil {
+			t.Errorf("%s %q: unexpected error %v", test.target, test.codec, err)
+		}
+
+		if !test.valid && (err == nil || !strings.Contains(err.Error(), "start the chain with base64") && !strings.Contains(err.Error(), "writes binary records")) {
+			t.Errorf("%s %q: expected binary codec to be rejected, got %v", test.target, test.codec, err)
+		}
+	}
+}
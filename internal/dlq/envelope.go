@@ -0,0 +1,138 @@
+package dlq
+
+import (
+	interfaces2 "draethos.io.com/internal/interfaces"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DecodeError = "decode"
+	TargetError = "target"
+)
+
+// Envelope wraps an event rejected by the pipeline with the reason it was
+// rejected. Payload holds the decoded event, Raw the original content when the
+// event could not be decoded.
+type Envelope struct {
+	ErrorType string
+	Error     string
+	Source    string
+	Key       string
+	FailedAt  time.Time
+	Payload   map[string]interface{}
+	Raw       string
+}
+
+func NewEnvelope(errorType string, source string, key string, err error) Envelope {
+	return Envelope{
+		ErrorType: errorType,
+		Error:     err.Error(),
+		Source:    source,
+		Key:       key,
+		FailedAt:  time.Now().UTC(),
+	}
+}
+
+func (e Envelope) WithPayload(payload map[string]interface{}) Envelope {
+	e.Payload = payload
+	return e
+}
+
+func (e Envelope) WithRaw(raw []byte) Envelope {
+	e.Raw = string(raw)
+	return e
+}
+
+// Map returns the content attached to the dlq target.
+func (e Envelope) Map() map[string]interface{} {
+	content := map[string]interface{}{
+		"error": map[string]interface{}{
+			"type":    e.ErrorType,
+			"message": e.Error,
+		},
+		"source":   e.Source,
+		"key":      e.Key,
+		"failedAt": e.FailedAt.Format(time.RFC3339Nano),
+	}
+
+	if e.Payload != nil {
+		content["payload"] = e.Payload
+	} else {
+		content["raw"] = e.Raw
+	}
+
+	return content
+}
+
+// Parse reads an envelope back from the content written by Map.
+func Parse(content map[string]interface{}) (Envelope, error) {
+	var envelope Envelope
+
+	errorContent, ok := content["error"].(map[string]interface{})
+	if !ok {
+		return envelope, errors.Errorf("not a dlq envelope, error not defined")
+	}
+
+	envelope.ErrorType = fmt.Sprint(errorContent["type"])
+	envelope.Error = fmt.Sprint(errorContent["message"])
+	envelope.Source, _ = content["source"].(string)
+	envelope.Key, _ = content["key"].(string)
+
+	if value, ok := content["failedAt"].(string); ok {
+		failedAt, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return envelope, errors.Errorf("invalid failedAt %q: %s", value, err.Error())
+		}
+		envelope.FailedAt = failedAt
+	}
+
+	switch payload := content["payload"].(type) {
+	case map[string]interface{}:
+		envelope.Payload = payload
+	case nil:
+		raw, ok := content["raw"].(string)
+		if !ok {
+			return envelope, errors.Errorf("not a dlq envelope, payload not defined")
+		}
+		envelope.Raw = raw
+	default:
+		return envelope, errors.Errorf("invalid payload, expected object, got %T", payload)
+	}
+
+	return envelope, nil
+}
+
+// Send attaches the envelope to the dlq, flushing when the dlq buffer is full.
+// Without a dlq the rejection error is returned so the source handles it as
+// it did before.
+func Send(dlq interfaces2.TargetInterface, envelope Envelope) error {
+	if dlq == nil {
+		return errors.New(envelope.Error)
+	}
+
+	if err := dlq.Attach(envelope.Key, envelope.Map()); err != nil {
+		return errors.Errorf("failed to attach event to dlq: %s", err.Error())
+	}
+
+	if !dlq.CanFlush() {
+		return nil
+	}
+
+	return Flush(dlq)
+}
+
+// Flush sends the envelopes buffered by the dlq, if any.
+func Flush(dlq interfaces2.TargetInterface) error {
+	if dlq == nil {
+		return nil
+	}
+
+	if err := dlq.Flush(); err != nil {
+		return errors.Errorf("failed to flush dlq: %s", err.Error())
+	}
+
+	return nil
+}
@@ -4,3 +4,35 @@ type CodecInterface interface {
 	Deserialize(content []byte) (map[string]interface{}, error)
 	Serialize(content map[string]interface{}) ([]byte, error)
 }
+
+// FileCodecInterface is implemented by codecs whose events are written to files
+// with an extension of their own and, optionally, a header line.
+type FileCodecInterface interface {
+	Extension() string
+	Header() []byte
+}
+
+// ContentTypeInterface is implemented by codecs that know the media type of
+// the events they write, used by targets storing files.
+type ContentTypeInterface interface {
+	ContentType() string
+}
+
+// EncodingInterface is implemented by the layers chained before a codec, such
+// as compressions, that turn payloads into other payloads.
+type EncodingInterface interface {
+	Decode(content []byte) ([]byte, error)
+	Encode(content []byte) ([]byte, error)
+	Extension() string
+}
+
+// EncodedCodecInterface is implemented by codecs preceded by encodings. Targets
+// writing files serialize events with Codec and encode the whole file instead
+// of every event, appending Suffix to the file extension.
+type EncodedCodecInterface interface {
+	CodecInterface
+	Codec() CodecInterface
+	Decode(content []byte) ([]byte, error)
+	Encode(content []byte) ([]byte, error)
+	Suffix() string
+}
@@ -2,4 +2,5 @@ package interfaces
 
 type SourceInterface interface {
 	Worker() error
+	Stop()
 }
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	InterpolationFilePrefix     = "file:"
+	InterpolationDefaultMarker  = ":-"
+	InterpolationEscapeSequence = "$${"
+)
+
+var interpolationPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// Interpolate expands ${ENV_VAR}, ${ENV_VAR:-default} and ${file:/path/to/secret}
+// references in the scalars of a parsed document, trailing new lines of secret
+// files are dropped. Values are never parsed as yaml, quotes, colons and new
+// lines in secrets are kept as they are, while unquoted scalars are resolved
+// again so ${PORT:-5432} is still a number. Literal "${" can be written as
+// "$${". Comments are left untouched, every unresolved reference is reported
+// in a single error, along with the file of the node when origins tell it.
+func Interpolate(node *yaml.Node, origins map[*yaml.Node]string) error {
+	var unresolved []string
+	interpolateNode(node, origins, &unresolved)
+
+	if len(unresolved) > 0 {
+		return errors.Errorf("unresolved references:\n\t%s", strings.Join(unresolved, "\n\t"))
+	}
+
+	return nil
+}
+
+func interpolateNode(node *yaml.Node, origins map[*yaml.Node]string, unresolved *[]string) {
+	for _, child := range node.Content {
+		interpolateNode(child, origins, unresolved)
+	}
+
+	if node.Kind != yaml.ScalarNode || !strings.Contains(node.Value, "${") {
+		return
+	}
+
+	var output strings.Builder
+	for i, part := range strings.Split(node.Value, InterpolationEscapeSequence) {
+		if i > 0 {
+			output.WriteString("${")
+		}
+
+		output.WriteString(interpolationPattern.ReplaceAllStringFunc(part, func(reference string) string {
+			value, err := resolveReference(reference[2 : len(reference)-1])
+			if err != nil {
+				location := fmt.Sprintf("line %d", node.Line)
+				if origin, ok := origins[node]; ok {
+					location = fmt.Sprintf("%s line %d", origin, node.Line)
+				}
+
+				*unresolved = append(*unresolved, fmt.Sprintf("%s: %s", location, err.Error()))
+				return reference
+			}
+
+			return value
+		}))
+	}
+
+	node.Value = output.String()
+	if node.Style == 0 {
+		node.Tag = ""
+		node.Tag = node.ShortTag()
+	}
+}
+
+func resolveReference(reference string) (string, error) {
+	if strings.HasPrefix(reference, InterpolationFilePrefix) {
+		path := strings.TrimPrefix(reference, InterpolationFilePrefix)
+		if path == "" {
+			return "", errors.Errorf("${%s} secret file path not defined", reference)
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", errors.Errorf("${%s} failed to read secret file %s", reference, path)
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	name, fallback, hasDefault := reference, "", false
+	if index := strings.Index(reference, InterpolationDefaultMarker); index >= 0 {
+		name, fallback, hasDefault = reference[:index], reference[index+len(InterpolationDefaultMarker):], true
+	}
+
+	if name == "" {
+		return "", errors.Errorf("${%s} variable name not defined", reference)
+	}
+
+	if value, ok := os.LookupEnv(name); ok && (value != "" || !hasDefault) {
+		return value, nil
+	}
+
+	if hasDefault {
+		return fallback, nil
+	}
+
+	return "", errors.Errorf("${%s} environment variable %s not defined", reference, name)
+}
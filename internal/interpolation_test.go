@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func interpolate(t *testing.T, content string) (map[string]interface{}, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &node); err != nil {
+		t.Fatalf("failed to parse content: %v", err)
+	}
+
+	if err := Interpolate(&node, nil); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	if err := node.Decode(&values); err != nil {
+		t.Fatalf("failed to decode content: %v", err)
+	}
+
+	return values, nil
+}
+
+func TestShouldInterpolateEnvironmentAndSecretFiles(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := ioutil.WriteFile(secret, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	os.Setenv("DRAETHOS_TEST_HOST", "db.internal")
+	os.Setenv("DRAETHOS_TEST_EMPTY", "")
+	defer os.Unsetenv("DRAETHOS_TEST_HOST")
+	defer os.Unsetenv("DRAETHOS_TEST_EMPTY")
+
+	content := "host: '${DRAETHOS_TEST_HOST}'\n" +
+		"port: ${DRAETHOS_TEST_PORT:-5432}\n" +
+		"sslmode: '${DRAETHOS_TEST_EMPTY:-disable}'\n" +
+		"password: '${file:" + secret + "}'\n" +
+		"literal: '$${NOT_EXPANDED}'\n" +
+		"# user: ${DRAETHOS_TEST_COMMENTED}\n"
+
+	values, err := interpolate(t, content)
+	if err != nil {
+		t.Fatalf("failed to interpolate: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"host":     "db.internal",
+		"port":     5432,
+		"sslmode":  "disable",
+		"password": "s3cr3t",
+		"literal":  "${NOT_EXPANDED}",
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestShouldKeepQuotesAndNewLinesOfSecrets(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "key")
+	key := "-----BEGIN KEY-----\nabc: 'def\"\n-----END KEY-----"
+	if err := ioutil.WriteFile(secret, []byte(key+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	os.Setenv("DRAETHOS_TEST_PASSWORD", `p'a"ss: #word`)
+	defer os.Unsetenv("DRAETHOS_TEST_PASSWORD")
+
+	content := "password: '${DRAETHOS_TEST_PASSWORD}'\n" +
+		"plain: ${DRAETHOS_TEST_PASSWORD}\n" +
+		"key: \"${file:" + secret + "}\"\n" +
+		"next: value\n"
+
+	values, err := interpolate(t, content)
+	if err != nil {
+		t.Fatalf("failed to interpolate: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"password": `p'a"ss: #word`,
+		"plain":    `p'a"ss: #word`,
+		"key":      key,
+		"next":     "value",
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestShouldReportEveryUnresolvedReference(t *testing.T) {
+	content := "user: '${DRAETHOS_TEST_UNDEFINED}'\n" +
+		"password: '${file:/nonexistent/draethos/secret}'\n"
+
+	_, err := interpolate(t, content)
+	if err == nil {
+		t.Fatalf("expected unresolved references error")
+	}
+
+	if !strings.Contains(err.Error(), "line 1: ${DRAETHOS_TEST_UNDEFINED}") {
+		t.Errorf("failed to report environment variable: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "line 2: ${file:/nonexistent/draethos/secret}") {
+		t.Errorf("failed to report secret file: %v", err)
+	}
+}
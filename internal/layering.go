@@ -0,0 +1,234 @@
+package internal
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"draethos.io.com/internal/schema"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type layeredDocument struct {
+	root    *yaml.Node
+	files   []string
+	origins map[*yaml.Node]string
+}
+
+// loadLayeredDocument reads a pipeline file resolving its includes, which are
+// deep-merged in order below the including file, and then merges the selected
+// profile on top. Mappings are merged key by key, any other value is replaced.
+// References are interpolated last, so only the selected profile is.
+func loadLayeredDocument(filePath string, profile string) (*layeredDocument, error) {
+	document := &layeredDocument{origins: make(map[*yaml.Node]string)}
+
+	root, err := document.load(filePath, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := removeKey(root, schema.ProfilesKey)
+	if profile != "" {
+		overlay := mappingValue(profiles, profile)
+		if overlay == nil && profiles == nil {
+			return nil, errors.Errorf("profile %s not defined, %s has no profiles", profile, filePath)
+		}
+
+		if overlay == nil {
+			return nil, errors.Errorf("profile %s not defined in %s, available profiles: %s",
+				profile, filePath, strings.Join(mappingKeys(profiles), ", "))
+		}
+
+		if overlay.Kind != yaml.MappingNode {
+			return nil, errors.Errorf("profile %s in %s must be an object", profile, filePath)
+		}
+
+		mergeNodes(root, overlay)
+	}
+
+	// profiles not selected may reference variables only defined where they are
+	if err = Interpolate(root, document.origins); err != nil {
+		return nil, errors.Errorf("failed to interpolate %s file: %s", filePath, err.Error())
+	}
+
+	document.root = root
+
+	return document, nil
+}
+
+func (d *layeredDocument) load(filePath string, visiting map[string]bool) (*yaml.Node, error) {
+	absolute, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, errors.Errorf("failed to resolve %s: %s", filePath, err.Error())
+	}
+
+	if visiting[absolute] {
+		return nil, errors.Errorf("include cycle detected on %s", filePath)
+	}
+
+	visiting[absolute] = true
+	defer delete(visiting, absolute)
+
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Errorf("failed to load %s file, make sure the path was passed correctly", filePath)
+	}
+
+	var node yaml.Node
+	if err = yaml.Unmarshal(content, &node); err != nil {
+		return nil, errors.Errorf("failed to deserialize %s file: %s", filePath, err.Error())
+	}
+
+	d.files = append(d.files, filePath)
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		root = node.Content[0]
+	}
+
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.Errorf("%s file must contain an object", filePath)
+	}
+
+	d.track(root, filePath)
+
+	// includes are resolved while loading, the rest of the document once the
+	// profile is merged
+	include := removeKey(root, schema.IncludeKey)
+	if include != nil {
+		if err = Interpolate(include, nil); err != nil {
+			return nil, errors.Errorf("failed to interpolate %s file: %s", filePath, err.Error())
+		}
+	}
+
+	includes, err := includePaths(include, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(includes) == 0 {
+		return root, nil
+	}
+
+	base := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: root.Line, Column: root.Column}
+	for _, include := range includes {
+		fragment, err := d.load(include, visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		mergeNodes(base, fragment)
+	}
+
+	mergeNodes(base, root)
+	d.origins[base] = filePath
+
+	return base, nil
+}
+
+func (d *layeredDocument) track(node *yaml.Node, filePath string) {
+	d.origins[node] = filePath
+	for _, child := range node.Content {
+		d.track(child, filePath)
+	}
+}
+
+func includePaths(node *yaml.Node, filePath string) ([]string, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	var includes []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		includes = []string{node.Value}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, errors.Errorf("%s line %d: include must be a list of paths", filePath, item.Line)
+			}
+			includes = append(includes, item.Value)
+		}
+	default:
+		return nil, errors.Errorf("%s line %d: include must be a path or a list of paths", filePath, node.Line)
+	}
+
+	for i, include := range includes {
+		if !filepath.IsAbs(include) {
+			includes[i] = filepath.Join(filepath.Dir(filePath), include)
+		}
+	}
+
+	return includes, nil
+}
+
+func mergeNodes(base *yaml.Node, overlay *yaml.Node) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+
+		index := keyIndex(base, key.Value)
+		if index < 0 {
+			base.Content = append(base.Content, key, value)
+			continue
+		}
+
+		current := base.Content[index+1]
+		if current.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeNodes(current, value)
+			continue
+		}
+
+		base.Content[index+1] = value
+	}
+}
+
+func keyIndex(node *yaml.Node, key string) int {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return -1
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if index := keyIndex(node, key); index >= 0 {
+		return node.Content[index+1]
+	}
+
+	return nil
+}
+
+func mappingKeys(node *yaml.Node) []string {
+	keys := make([]string, 0)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return keys
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func removeKey(node *yaml.Node, key string) *yaml.Node {
+	index := keyIndex(node, key)
+	if index < 0 {
+		return nil
+	}
+
+	value := node.Content[index+1]
+	node.Content = append(node.Content[:index], node.Content[index+2:]...)
+
+	return value
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShouldMergeIncludesAndProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeLayeringFile(t, dir, "common/kafka.yaml", LayeringKafkaFragmentTest)
+	writeLayeringFile(t, dir, "common/pgsql.yaml", LayeringPgsqlFragmentTest)
+	pipeline := writeLayeringFile(t, dir, "pipeline.yaml", LayeringPipelineTest)
+
+	stream, err := NewConfigBuilder().SetFile(pipeline).SetProfile("prod").Build()
+	if err != nil {
+		t.Fatalf("failed to build layered pipeline: %v", err)
+	}
+
+	source := stream.Stream.Instance.Source
+	if source.SourceSpecs.Topic != "orders" || source.SourceSpecs.TimeoutMs != 1000 {
+		t.Errorf("failed to merge source: %+v", source.SourceSpecs)
+	}
+
+	if source.SourceSpecs.Configurations["bootstrap.servers"] != "kafka.prod:9092" ||
+		source.SourceSpecs.Configurations["group.id"] != "draethos" {
+		t.Errorf("failed to merge source configurations: %v", source.SourceSpecs.Configurations)
+	}
+
+	target := stream.Stream.Instance.Target
+	databaseSpecs, err := target.TargetSpecs.Database()
+	if err != nil || databaseSpecs.BatchSize != 5000 || databaseSpecs.Table != "orders" {
+		t.Errorf("failed to merge target: %+v, %v", target.TargetSpecs, err)
+	}
+
+	if target.TargetSpecs.Configurations["host"] != "pgsql.prod" || target.TargetSpecs.Configurations["sslmode"] != "require" {
+		t.Errorf("failed to merge target configurations: %v", target.TargetSpecs.Configurations)
+	}
+}
+
+func TestShouldRejectUnknownProfileAndIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeLayeringFile(t, dir, "common/kafka.yaml", LayeringKafkaFragmentTest)
+	writeLayeringFile(t, dir, "common/pgsql.yaml", LayeringPgsqlFragmentTest)
+	pipeline := writeLayeringFile(t, dir, "pipeline.yaml", LayeringPipelineTest)
+
+	_, err := NewConfigBuilder().SetFile(pipeline).SetProfile("qa").Build()
+	if err == nil || !strings.Contains(err.Error(), "available profiles: dev, prod") {
+		t.Errorf("expected unknown profile error, got: %v", err)
+	}
+
+	cycle := writeLayeringFile(t, dir, "cycle.yaml", "include: cycle.yaml\n")
+	_, err = NewConfigBuilder().SetFile(cycle).Build()
+	if err == nil || !strings.Contains(err.Error(), "include cycle detected") {
+		t.Errorf("expected include cycle error, got: %v", err)
+	}
+}
+
+func TestShouldOnlyInterpolateSelectedProfile(t *testing.T) {
+	os.Setenv("DRAETHOS_TEST_DEV_PASSWORD", "dev-secret")
+	defer os.Unsetenv("DRAETHOS_TEST_DEV_PASSWORD")
+
+	pipeline := writeLayeringFile(t, t.TempDir(), "pipeline.yaml", LayeringProfileSecretsTest)
+
+	for _, profile := range []string{"", "dev"} {
+		stream, err := NewConfigBuilder().SetFile(pipeline).SetProfile(profile).Build()
+		if err != nil {
+			t.Fatalf("profile %q: failed to build pipeline: %v", profile, err)
+		}
+
+		password := stream.Stream.Instance.Target.TargetSpecs.Configurations["password"]
+		if profile == "dev" && password != "dev-secret" {
+			t.Errorf("expected dev password, got %v", password)
+		}
+	}
+
+	_, err := NewConfigBuilder().SetFile(pipeline).SetProfile("prod").Build()
+	if err == nil || !strings.Contains(err.Error(), "pipeline.yaml line 28: ${DRAETHOS_TEST_PROD_PASSWORD}") {
+		t.Errorf("expected unresolved prod password, got: %v", err)
+	}
+}
+
+func writeLayeringFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	return path
+}
+
+const (
+	LayeringKafkaFragmentTest = `stream:
+  instance:
+    source:
+      type: kafka
+      specs:
+        timeoutMs: 1000
+        configurations:
+          group.id: 'draethos'
+          bootstrap.servers: 'localhost:9093'
+          auto.offset.reset: 'beginning'
+`
+
+	LayeringPgsqlFragmentTest = `stream:
+  instance:
+    target:
+      type: pgsql
+      specs:
+        database: draethos
+        batchSize: 100
+        configurations:
+          host: '127.0.0.1'
+          port: 5432
+          user: 'root'
+          password: 'root'
+          sslmode: 'disable'
+`
+
+	LayeringPipelineTest = `include:
+  - common/kafka.yaml
+  - common/pgsql.yaml
+stream:
+  port: 9999
+  instance:
+    source:
+      specs:
+        topic: orders
+    target:
+      specs:
+        table: orders
+profiles:
+  dev:
+    stream:
+      instance:
+        target:
+          specs:
+            batchSize: 1
+  prod:
+    stream:
+      instance:
+        source:
+          specs:
+            configurations:
+              bootstrap.servers: 'kafka.prod:9092'
+        target:
+          specs:
+            batchSize: 5000
+            configurations:
+              host: 'pgsql.prod'
+              sslmode: 'require'
+`
+
+	LayeringProfileSecretsTest = `stream:
+  instance:
+    source:
+      type: generator
+      specs:
+        template:
+          id: "{{sequence}}"
+    target:
+      type: kafka
+      specs:
+        topic: orders
+        configurations:
+          bootstrap.servers: localhost:9092
+profiles:
+  dev:
+    stream:
+      instance:
+        target:
+          specs:
+            configurations:
+              password: ${DRAETHOS_TEST_DEV_PASSWORD}
+  prod:
+    stream:
+      instance:
+        target:
+          specs:
+            configurations:
+              password: ${DRAETHOS_TEST_PROD_PASSWORD}
+`
+)
@@ -0,0 +1,222 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+
+	FormatConsole = "console"
+	FormatJson    = "json"
+
+	PayloadOff       = "off"
+	PayloadTruncated = "truncated"
+	PayloadFull      = "full"
+
+	// TruncateLength is the number of bytes of a payload logged in truncated
+	// mode.
+	TruncateLength = 256
+)
+
+var (
+	Levels   = []string{LevelDebug, LevelInfo, LevelWarn, LevelError}
+	Formats  = []string{FormatConsole, FormatJson}
+	Payloads = []string{PayloadOff, PayloadTruncated, PayloadFull}
+
+	payloadMode atomic.Value
+)
+
+func init() {
+	payloadMode.Store(PayloadOff)
+}
+
+// Merge returns the base configuration with the values set on override,
+// fields are merged key by key.
+func Merge(base specs.Logging, override specs.Logging) specs.Logging {
+	if override.Level != "" {
+		base.Level = override.Level
+	}
+
+	if override.Format != "" {
+		base.Format = override.Format
+	}
+
+	if override.Payload != "" {
+		base.Payload = override.Payload
+	}
+
+	if override.Sampling.Initial != 0 {
+		base.Sampling.Initial = override.Sampling.Initial
+	}
+
+	if override.Sampling.Thereafter != 0 {
+		base.Sampling.Thereafter = override.Sampling.Thereafter
+	}
+
+	if len(override.Fields) > 0 {
+		fields := make(map[string]string, len(base.Fields)+len(override.Fields))
+		for key, value := range base.Fields {
+			fields[key] = value
+		}
+		for key, value := range override.Fields {
+			fields[key] = value
+		}
+		base.Fields = fields
+	}
+
+	return base
+}
+
+// New builds the logger described by the stream logging configuration, every
+// line carries the pipeline, source and target fields when they are known.
+// The pipeline is named after the stream name, or the pipeline file when the
+// stream has no name.
+func New(stream specs.Stream, file string) (*zap.Logger, error) {
+	logging := stream.Stream.Logging
+
+	level := zap.NewAtomicLevel()
+	if err := level.UnmarshalText([]byte(valueOrDefault(logging.Level, LevelInfo))); err != nil || !contains(Levels, level.String()) {
+		return nil, errors.Errorf("invalid log level %s, expected one of: %s", logging.Level, strings.Join(Levels, ", "))
+	}
+
+	config := zap.Config{
+		Level:            level,
+		Development:      false,
+		OutputPaths:      []string{"stderr"},
+		ErrorOutputPaths: []string{"stderr"},
+	}
+
+	switch format := valueOrDefault(logging.Format, FormatConsole); format {
+	case FormatConsole:
+		config.Encoding = FormatConsole
+		config.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	case FormatJson:
+		config.Encoding = FormatJson
+		config.EncoderConfig = zap.NewProductionEncoderConfig()
+		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	default:
+		return nil, errors.Errorf("invalid log format %s, expected one of: %s", format, strings.Join(Formats, ", "))
+	}
+
+	if payload := valueOrDefault(logging.Payload, PayloadOff); !contains(Payloads, payload) {
+		return nil, errors.Errorf("invalid log payload %s, expected one of: %s", payload, strings.Join(Payloads, ", "))
+	}
+
+	if logging.Sampling.Initial > 0 || logging.Sampling.Thereafter > 0 {
+		config.Sampling = &zap.SamplingConfig{
+			Initial:    logging.Sampling.Initial,
+			Thereafter: logging.Sampling.Thereafter,
+		}
+	}
+
+	config.InitialFields = map[string]interface{}{}
+	for key, value := range logging.Fields {
+		config.InitialFields[key] = value
+	}
+	for key, value := range map[string]string{
+		"pipeline": pipelineName(stream, file),
+		"source":   stream.Stream.Instance.Source.Type,
+		"target":   stream.Stream.Instance.Target.Type,
+	} {
+		if value != "" {
+			config.InitialFields[key] = value
+		}
+	}
+
+	return config.Build()
+}
+
+// Configure replaces the global logger and payload mode with the ones of the
+// stream.
+func Configure(stream specs.Stream, file string) error {
+	logger, err := New(stream, file)
+	if err != nil {
+		return err
+	}
+
+	_ = zap.L().Sync()
+	zap.ReplaceGlobals(logger)
+	payloadMode.Store(valueOrDefault(stream.Stream.Logging.Payload, PayloadOff))
+
+	return nil
+}
+
+// Payload formats an event content for a log line following the payload
+// mode configured, contents are omitted unless enabled. The content is only
+// formatted once the line is written, so lines below the level configured
+// don't pay for it.
+func Payload(content interface{}) fmt.Stringer {
+	return payload{content: content}
+}
+
+type payload struct {
+	content interface{}
+}
+
+func (p payload) String() string {
+	content := p.content
+	mode := payloadMode.Load().(string)
+	if mode == PayloadOff {
+		return "omitted"
+	}
+
+	var formatted string
+	switch value := content.(type) {
+	case []byte:
+		formatted = string(value)
+	case string:
+		formatted = value
+	default:
+		if content, err := json.Marshal(value); err == nil {
+			formatted = string(content)
+		} else {
+			formatted = fmt.Sprintf("%v", value)
+		}
+	}
+
+	if mode == PayloadTruncated && len(formatted) > TruncateLength {
+		return fmt.Sprintf("%s... (%d bytes)", formatted[:TruncateLength], len(formatted))
+	}
+
+	return formatted
+}
+
+func pipelineName(stream specs.Stream, file string) string {
+	if stream.Stream.Name != "" {
+		return stream.Stream.Name
+	}
+
+	base := filepath.Base(file)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
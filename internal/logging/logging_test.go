@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"draethos.io.com/pkg/streams/specs"
+	"go.uber.org/zap"
+)
+
+func TestShouldOverrideFileConfigurationWithFlags(t *testing.T) {
+	base := specs.Logging{Level: LevelInfo, Format: FormatJson, Fields: map[string]string{"env": "prod", "team": "data"}}
+	base.Sampling = specs.Sampling{Initial: 100, Thereafter: 100}
+	merged := Merge(base, specs.Logging{Level: LevelDebug, Sampling: specs.Sampling{Thereafter: 10}, Fields: map[string]string{"env": "staging"}})
+
+	if merged.Level != LevelDebug || merged.Format != FormatJson ||
+		merged.Fields["env"] != "staging" || merged.Fields["team"] != "data" || base.Fields["env"] != "prod" {
+		t.Errorf("unexpected configuration: %+v", merged)
+	}
+
+	if merged.Sampling.Initial != 100 || merged.Sampling.Thereafter != 10 {
+		t.Errorf("expected sampling merged by field, got %+v", merged.Sampling)
+	}
+}
+
+func TestShouldRejectInvalidConfiguration(t *testing.T) {
+	for _, logging := range []specs.Logging{{Level: "verbose"}, {Format: "xml"}, {Payload: "some"}} {
+		if _, err := New(specs.Stream{Stream: specs.Base{Logging: logging}}, "pipeline.yaml"); err == nil {
+			t.Errorf("expected %+v to be rejected", logging)
+		}
+	}
+}
+
+func TestShouldLogPayloadFollowingMode(t *testing.T) {
+	defer payloadMode.Store(PayloadOff)
+
+	content := []byte(strings.Repeat("a", TruncateLength+10))
+	stream := specs.Stream{Stream: specs.Base{Logging: specs.Logging{Payload: PayloadTruncated}}}
+	if err := Configure(stream, "pipeline.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	if payload := Payload(content).String(); !strings.HasSuffix(payload, "... (266 bytes)") || len(payload) > TruncateLength+20 {
+		t.Errorf("expected truncated payload, got %s", payload)
+	}
+
+	if payload := Payload(map[string]interface{}{"id": 1}).String(); payload != `{"id":1}` {
+		t.Errorf("expected json payload, got %s", payload)
+	}
+
+	payloadMode.Store(PayloadOff)
+	if payload := Payload(content).String(); payload != "omitted" {
+		t.Errorf("expected omitted payload, got %s", payload)
+	}
+}
+
+// countedContent counts the times it is formatted.
+type countedContent struct {
+	formatted *int
+}
+
+func (c countedContent) MarshalJSON() ([]byte, error) {
+	*c.formatted++
+	return []byte(`{}`), nil
+}
+
+func TestShouldOnlyFormatPayloadOfLinesWritten(t *testing.T) {
+	defer payloadMode.Store(PayloadOff)
+
+	stream := specs.Stream{Stream: specs.Base{Logging: specs.Logging{Level: LevelInfo, Payload: PayloadFull}}}
+	if err := Configure(stream, "pipeline.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	formatted := 0
+	zap.S().Debugf("processing event [%s]", Payload(countedContent{formatted: &formatted}))
+	if formatted != 0 {
+		t.Errorf("expected payload of debug line not formatted at info level, formatted %d times", formatted)
+	}
+
+	if payload := Payload(countedContent{formatted: &formatted}).String(); payload != "{}" || formatted != 1 {
+		t.Errorf("expected payload formatted once, got %s formatted %d times", payload, formatted)
+	}
+}
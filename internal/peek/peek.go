@@ -0,0 +1,159 @@
+package peek
+
+import (
+	context2 "draethos.io.com/internal/context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	LimitDefault     = 10
+	TimeoutDefault   = 30 * time.Second
+	PeekGroupDefault = "draethos"
+)
+
+type Event struct {
+	Key     string
+	Payload map[string]interface{}
+}
+
+type Options struct {
+	Limit         int
+	Timeout       time.Duration
+	FromBeginning bool
+}
+
+// captureTarget keeps the events received by the source in memory instead of
+// writing them, it never asks to be flushed.
+type captureTarget struct {
+	sync.Mutex
+	limit  int
+	events []Event
+	full   chan struct{}
+}
+
+func newCaptureTarget(limit int) *captureTarget {
+	return &captureTarget{limit: limit, full: make(chan struct{})}
+}
+
+func (c *captureTarget) Initialize() error {
+	return nil
+}
+
+func (c *captureTarget) Attach(key string, data map[string]interface{}) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.events) >= c.limit {
+		return nil
+	}
+
+	c.events = append(c.events, Event{Key: key, Payload: data})
+	if len(c.events) == c.limit {
+		close(c.full)
+	}
+
+	return nil
+}
+
+func (c *captureTarget) CanFlush() bool {
+	return false
+}
+
+func (c *captureTarget) Flush() error {
+	return nil
+}
+
+func (c *captureTarget) Close() error {
+	return nil
+}
+
+func (c *captureTarget) captured() []Event {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]Event(nil), c.events...)
+}
+
+// Peek runs the pipeline source until limit events were decoded, the source
+// stops or the timeout expires. Kafka sources consume with a group of their
+// own and never commit, so the pipeline offsets are left untouched.
+func Peek(stream specs.Stream, options Options) ([]Event, error) {
+	if options.Limit <= 0 {
+		options.Limit = LimitDefault
+	}
+
+	if options.Timeout <= 0 {
+		options.Timeout = TimeoutDefault
+	}
+
+	if stream.Stream.Instance.Source.Type == context2.KafkaSource {
+		stream.Stream.Instance.Source.SourceSpecs.Configurations = peekConfigurations(
+			stream.Stream.Instance.Source.SourceSpecs.Configurations, options.FromBeginning)
+		// parallel workers commit offsets explicitly, a single one only commits
+		// the offsets stored, which peek disables. Reaching stopAt commits the
+		// position of every partition, peek stops on its own limit instead.
+		stream.Stream.Instance.Source.SourceSpecs.Workers = 1
+		stream.Stream.Instance.Source.SourceSpecs.StopAt = ""
+	}
+
+	capture := newCaptureTarget(options.Limit)
+	source, err := context2.NewSourceContext(stream, capture, nil, &mux.Router{}, stream.Stream.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Worker()
+	}()
+
+	select {
+	case err = <-done:
+		if err != nil {
+			return capture.captured(), errors.Errorf("source %s stopped: %s", stream.Stream.Instance.Source.Type, err.Error())
+		}
+		return capture.captured(), nil
+	case <-capture.full:
+	case <-time.After(options.Timeout):
+		zap.S().Infof("no more events received in %s", options.Timeout)
+	}
+
+	source.Stop()
+	if err = <-done; err != nil {
+		zap.S().Warnf("failed to stop source: %s", err.Error())
+	}
+
+	return capture.captured(), nil
+}
+
+func peekConfigurations(configurations map[string]interface{}, fromBeginning bool) map[string]interface{} {
+	peek := make(map[string]interface{}, len(configurations)+3)
+	for key, value := range configurations {
+		peek[key] = value
+	}
+
+	group := PeekGroupDefault
+	if value, ok := configurations["group.id"]; ok && fmt.Sprintf("%v", value) != "" {
+		group = fmt.Sprintf("%v", value)
+	}
+
+	peek["group.id"] = fmt.Sprintf("%s-peek-%d-%d", group, os.Getpid(), time.Now().Unix())
+	peek["enable.auto.offset.store"] = false
+	peek["enable.auto.commit"] = false
+
+	if fromBeginning {
+		peek["auto.offset.reset"] = "earliest"
+	} else if _, ok := peek["auto.offset.reset"]; !ok {
+		peek["auto.offset.reset"] = "latest"
+	}
+
+	return peek
+}
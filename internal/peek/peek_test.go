@@ -0,0 +1,59 @@
+package peek
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+)
+
+const PeekEventsTest = `{"id":1,"name":"a"}
+{"id":2,"name":"b"}
+{"id":3,"name":"c"}
+`
+
+func TestShouldCaptureSourceEventsUpToLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := ioutil.WriteFile(path, []byte(PeekEventsTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stream := specs.Stream{Stream: specs.Base{Instance: specs.Instance{
+		Source: specs.Source{Type: "jsonl", SourceSpecs: specs.SourceSpecs{Path: path}},
+	}}}
+
+	events, err := Peek(stream, Options{Limit: 2, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("failed to peek source: %v", err)
+	}
+
+	if len(events) != 2 || events[0].Payload["name"] != "a" || events[1].Payload["name"] != "b" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	events, err = Peek(stream, Options{Limit: 10, Timeout: 5 * time.Second})
+	if err != nil || len(events) != 3 {
+		t.Errorf("expected every event of the file, got %d: %v", len(events), err)
+	}
+}
+
+func TestShouldNeverCommitKafkaOffsets(t *testing.T) {
+	configurations := peekConfigurations(map[string]interface{}{"group.id": "orders", "bootstrap.servers": "localhost:9092"}, true)
+
+	if configurations["group.id"] == "orders" || configurations["enable.auto.offset.store"] != false ||
+		configurations["auto.offset.reset"] != "earliest" || configurations["bootstrap.servers"] != "localhost:9092" {
+		t.Errorf("unexpected configurations: %v", configurations)
+	}
+
+	if configurations["enable.auto.commit"] != false {
+		t.Errorf("expected auto commit disabled, got %v", configurations["enable.auto.commit"])
+	}
+
+	group := peekConfigurations(map[string]interface{}{"bootstrap.servers": "localhost:9092"}, false)["group.id"].(string)
+	if !strings.HasPrefix(group, PeekGroupDefault+"-peek-") {
+		t.Errorf("expected default group prefix, got %s", group)
+	}
+}
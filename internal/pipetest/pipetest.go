@@ -0,0 +1,394 @@
+package pipetest
+
+import (
+	"bufio"
+	context2 "draethos.io.com/internal/context"
+	"draethos.io.com/internal/dlq"
+	"draethos.io.com/internal/source"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+const (
+	TimeoutDefault      = 30 * time.Second
+	FixtureBase64Prefix = "base64:"
+	FixtureHexPrefix    = "hex:"
+)
+
+// Record is an event delivered to the target, or to the dlq when Error is
+// set, as written to the expected output file.
+type Record struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value,omitempty"`
+	Error *RecordError           `json:"error,omitempty"`
+}
+
+type RecordError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type recorder struct {
+	sync.Mutex
+	records []Record
+}
+
+func (r *recorder) add(record Record) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.records = append(r.records, record)
+}
+
+// captureTarget records the events attached in memory, as target or as dlq.
+type captureTarget struct {
+	recorder *recorder
+	dlq      bool
+}
+
+func (c captureTarget) Initialize() error {
+	return nil
+}
+
+func (c captureTarget) Attach(key string, data map[string]interface{}) error {
+	if !c.dlq {
+		c.recorder.add(Record{Key: key, Value: data})
+		return nil
+	}
+
+	envelope, err := dlq.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	c.recorder.add(Record{Key: key, Error: &RecordError{Type: envelope.ErrorType, Message: envelope.Error}})
+
+	return nil
+}
+
+func (c captureTarget) CanFlush() bool {
+	return false
+}
+
+func (c captureTarget) Flush() error {
+	return nil
+}
+
+func (c captureTarget) Close() error {
+	return nil
+}
+
+// Run sends the fixture through the pipeline source and codec, capturing what
+// would reach the target and the dlq. File sources read the fixture as their
+// path, kafka and http sources receive each fixture line as a message body,
+// written as base64: or hex: for binary payloads.
+func Run(stream specs.Stream, fixture string, timeout time.Duration) ([]Record, error) {
+	if timeout <= 0 {
+		timeout = TimeoutDefault
+	}
+
+	recorder := &recorder{}
+	target := captureTarget{recorder: recorder}
+	deadLetters := captureTarget{recorder: recorder, dlq: true}
+
+	sourceSpec := stream.Stream.Instance.Source
+	switch sourceSpec.Type {
+	case context2.KafkaSource, context2.HttpSource:
+		if err := feed(sourceSpec, fixture, target, deadLetters); err != nil {
+			return nil, err
+		}
+	default:
+		if fixture != "" {
+			stream.Stream.Instance.Source.SourceSpecs.Path = fixture
+		}
+
+		if err := run(stream, target, deadLetters, timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	return normalize(recorder.records)
+}
+
+func run(stream specs.Stream, target captureTarget, deadLetters captureTarget, timeout time.Duration) error {
+	worker, err := context2.NewSourceContext(stream, target, deadLetters, &mux.Router{}, stream.Stream.Port)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.Worker()
+	}()
+
+	select {
+	case err = <-done:
+		return err
+	case <-time.After(timeout):
+		worker.Stop()
+		<-done
+		return errors.Errorf("source %s did not finish in %s", stream.Stream.Instance.Source.Type, timeout)
+	}
+}
+
+// feed hands every fixture line to the source the way the kafka source handles
+// a message, kafka messages have no key as fixtures only carry their value.
+// Lines starting with base64: or hex: hold binary payloads, a line holding a
+// json string is unquoted.
+func feed(sourceSpec specs.Source, fixture string, target captureTarget, deadLetters captureTarget) error {
+	file, err := os.Open(fixture)
+	if err != nil {
+		return errors.Errorf("failed to load fixture %s: %s", fixture, err.Error())
+	}
+
+	defer file.Close()
+
+	codec, err := context2.NewCodecContext(sourceSpec.Codec, sourceSpec.CodecConfigurations)
+	if err != nil {
+		return err
+	}
+
+	topic := ""
+	if sourceSpec.Type == context2.KafkaSource {
+		topic = fixtureTopic(sourceSpec)
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		content, err := fixtureMessage(scanner.Text())
+		if err != nil {
+			return errors.Errorf("%s line %d: %s", fixture, line, err.Error())
+		}
+
+		key := ""
+		if sourceSpec.Type == context2.HttpSource {
+			key = source.RequestKey(content)
+		}
+
+		if err = source.HandleMessage(sourceSpec, codec, target, deadLetters, key, content, topic); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// fixtureMessage returns the message a fixture line holds.
+func fixtureMessage(line string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(line, FixtureBase64Prefix):
+		content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(line, FixtureBase64Prefix)))
+		if err != nil {
+			return nil, errors.Errorf("invalid base64 message: %s", err.Error())
+		}
+		return content, nil
+	case strings.HasPrefix(line, FixtureHexPrefix):
+		content, err := hex.DecodeString(strings.TrimSpace(strings.TrimPrefix(line, FixtureHexPrefix)))
+		if err != nil {
+			return nil, errors.Errorf("invalid hex message: %s", err.Error())
+		}
+		return content, nil
+	}
+
+	var quoted string
+	if err := json.Unmarshal([]byte(line), &quoted); err == nil {
+		return []byte(quoted), nil
+	}
+
+	return []byte(line), nil
+}
+
+// fixtureTopic is the topic fixture messages are consumed from, the first
+// topic subscribed or the pattern when only a pattern is.
+func fixtureTopic(sourceSpec specs.Source) string {
+	if topic := strings.TrimSpace(strings.Split(sourceSpec.SourceSpecs.Topic, ",")[0]); topic != "" {
+		return topic
+	}
+
+	return sourceSpec.SourceSpecs.TopicPattern
+}
+
+// normalize gives the captured values the types read back from json, so they
+// compare with the expected file.
+func normalize(records []Record) ([]Record, error) {
+	content, err := json.Marshal(records)
+	if err != nil {
+		return nil, errors.Errorf("failed to serialize events: %s", err.Error())
+	}
+
+	normalized := make([]Record, 0, len(records))
+	if err = json.Unmarshal(content, &normalized); err != nil {
+		return nil, errors.Errorf("failed to serialize events: %s", err.Error())
+	}
+
+	return normalized, nil
+}
+
+// ReadRecords loads an expected output file, one record per line.
+func ReadRecords(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Errorf("failed to load expected output %s: %s", path, err.Error())
+	}
+
+	defer file.Close()
+
+	records := make([]Record, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var record Record
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, errors.Errorf("%s line %d: %s", path, line, err.Error())
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// WriteRecords writes records in the expected output format.
+func WriteRecords(writer io.Writer, records []Record) error {
+	for _, record := range records {
+		content, err := json.Marshal(record)
+		if err != nil {
+			return errors.Errorf("failed to serialize event %s: %s", record.Key, err.Error())
+		}
+
+		if _, err = fmt.Fprintf(writer, "%s\n", content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Diff lists the differences between the expected and the actual records,
+// field by field, empty when they match.
+func Diff(expected []Record, actual []Record) []string {
+	differences := make([]string, 0)
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		path := fmt.Sprintf("event %d", i+1)
+		switch {
+		case i >= len(actual):
+			differences = append(differences, fmt.Sprintf("%s: missing, expected %s", path, describe(expected[i])))
+			continue
+		case i >= len(expected):
+			differences = append(differences, fmt.Sprintf("%s: unexpected %s", path, describe(actual[i])))
+			continue
+		}
+
+		if expected[i].Key != actual[i].Key {
+			differences = append(differences, fmt.Sprintf("%s key: expected %q, got %q", path, expected[i].Key, actual[i].Key))
+		}
+
+		if !reflect.DeepEqual(expected[i].Error, actual[i].Error) {
+			differences = append(differences, fmt.Sprintf("%s error: expected %s, got %s",
+				path, describeError(expected[i].Error), describeError(actual[i].Error)))
+		}
+
+		differences = diffValue(differences, path+" value", toInterface(expected[i].Value), toInterface(actual[i].Value))
+	}
+
+	return differences
+}
+
+func diffValue(differences []string, path string, expected interface{}, actual interface{}) []string {
+	expectedMap, expectedIsMap := expected.(map[string]interface{})
+	actualMap, actualIsMap := actual.(map[string]interface{})
+	if expectedIsMap && actualIsMap {
+		keys := make([]string, 0, len(expectedMap)+len(actualMap))
+		for key := range expectedMap {
+			keys = append(keys, key)
+		}
+		for key := range actualMap {
+			if _, ok := expectedMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := fmt.Sprintf("%s.%s", path, key)
+			expectedValue, inExpected := expectedMap[key]
+			actualValue, inActual := actualMap[key]
+			switch {
+			case !inActual:
+				differences = append(differences, fmt.Sprintf("%s: missing, expected %s", childPath, format(expectedValue)))
+			case !inExpected:
+				differences = append(differences, fmt.Sprintf("%s: unexpected %s", childPath, format(actualValue)))
+			default:
+				differences = diffValue(differences, childPath, expectedValue, actualValue)
+			}
+		}
+
+		return differences
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		differences = append(differences, fmt.Sprintf("%s: expected %s, got %s", path, format(expected), format(actual)))
+	}
+
+	return differences
+}
+
+func toInterface(value map[string]interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return value
+}
+
+func describe(record Record) string {
+	if record.Error != nil {
+		return fmt.Sprintf("dead letter %q (%s)", record.Key, describeError(record.Error))
+	}
+
+	return fmt.Sprintf("event %q %s", record.Key, format(record.Value))
+}
+
+func describeError(err *RecordError) string {
+	if err == nil {
+		return "no error"
+	}
+
+	return fmt.Sprintf("%s error %q", err.Type, err.Message)
+}
+
+func format(value interface{}) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(content)
+}
@@ -0,0 +1,107 @@
+package pipetest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+)
+
+const PipetestFixtureTest = `{"id":1,"name":"a"}
+not json
+{"id":2,"name":"b","price":10.5}
+`
+
+func TestShouldCaptureTargetAndDlqEventsInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := ioutil.WriteFile(path, []byte(PipetestFixtureTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sourceType := range []string{"jsonl", "kafka"} {
+		stream := specs.Stream{Stream: specs.Base{Instance: specs.Instance{
+			Source: specs.Source{Type: sourceType, Codec: "json"},
+		}}}
+
+		records, err := Run(stream, path, 5*time.Second)
+		if err != nil {
+			t.Fatalf("%s: failed to run pipeline: %v", sourceType, err)
+		}
+
+		if len(records) != 3 || records[0].Value["name"] != "a" || records[1].Error == nil ||
+			records[1].Error.Type != "decode" || records[2].Value["price"] != 10.5 {
+			t.Errorf("%s: unexpected records: %+v", sourceType, records)
+		}
+
+		var buffer bytes.Buffer
+		if err = WriteRecords(&buffer, records); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedPath := filepath.Join(t.TempDir(), "expected.jsonl")
+		if err = ioutil.WriteFile(expectedPath, buffer.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := ReadRecords(expectedPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if differences := Diff(expected, records); len(differences) != 0 {
+			t.Errorf("%s: expected written records to match, got %v", sourceType, differences)
+		}
+	}
+}
+
+func TestShouldFeedBinaryFixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.txt")
+	if err := ioutil.WriteFile(path, []byte("hex:81a2696401\nbase64:gaJpZAI=\nhex:zz\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stream := specs.Stream{Stream: specs.Base{Instance: specs.Instance{
+		Source: specs.Source{Type: "kafka", Codec: "msgpack", SourceSpecs: specs.SourceSpecs{Topic: "orders", TopicField: "topic"}},
+	}}}
+
+	_, err := Run(stream, path, 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "line 3: invalid hex message") {
+		t.Errorf("expected invalid hex error, got %v", err)
+	}
+
+	if err = ioutil.WriteFile(path, []byte("hex:81a2696401\nbase64:gaJpZAI=\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := Run(stream, path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("failed to run pipeline: %v", err)
+	}
+
+	if len(records) != 2 || records[0].Value["id"] != 1.0 || records[1].Value["id"] != 2.0 || records[1].Value["topic"] != "orders" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
+
+func TestShouldDiffEventsFieldByField(t *testing.T) {
+	expected := []Record{
+		{Key: "1", Value: map[string]interface{}{"price": 10.0, "customer": map[string]interface{}{"name": "a"}}},
+		{Key: "2", Value: map[string]interface{}{"id": 2.0}},
+	}
+	actual := []Record{
+		{Key: "1", Value: map[string]interface{}{"price": "10", "customer": map[string]interface{}{"name": "a", "age": 30.0}}},
+	}
+
+	differences := Diff(expected, actual)
+	joined := strings.Join(differences, "\n")
+	if len(differences) != 3 ||
+		!strings.Contains(joined, `event 1 value.price: expected 10, got "10"`) ||
+		!strings.Contains(joined, "event 1 value.customer.age: unexpected 30") ||
+		!strings.Contains(joined, "event 2: missing") {
+		t.Errorf("unexpected differences: %v", differences)
+	}
+}
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const ReloaderPollInterval = 5 * time.Second
+
+type Reloader interface {
+	Watch() <-chan specs.Stream
+}
+
+type reloader struct {
+	configBuilder ConfigBuilder
+	filePath      string
+	modTime       time.Time
+}
+
+// NewReloader re-validates the pipeline file whenever a SIGHUP is received or,
+// when watching is enabled, whenever the file or one of its includes changes.
+func NewReloader(configBuilder ConfigBuilder) Reloader {
+	return &reloader{configBuilder: configBuilder, filePath: configBuilder.GetFile()}
+}
+
+func (r *reloader) Watch() <-chan specs.Stream {
+	streams := make(chan specs.Stream)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+
+	var ticker <-chan time.Time
+	if r.configBuilder.IsEnabledWatch() {
+		r.modTime = r.lastModified()
+		ticker = time.NewTicker(ReloaderPollInterval).C
+	}
+
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				zap.S().Infof("caught signal SIGHUP: reloading %s", r.filePath)
+			case <-ticker:
+				modTime := r.lastModified()
+				if modTime.Equal(r.modTime) {
+					continue
+				}
+
+				r.modTime = modTime
+				zap.S().Infof("file %s changed: reloading", r.filePath)
+			}
+
+			stream, err := r.configBuilder.Build()
+			if err != nil {
+				zap.S().Errorf("failed to reload %s, keeping current pipeline: %s", r.filePath, err.Error())
+				continue
+			}
+
+			streams <- *stream
+		}
+	}()
+
+	return streams
+}
+
+// lastModified returns the most recent modification time among the pipeline
+// file and the files it includes.
+func (r *reloader) lastModified() time.Time {
+	var modTime time.Time
+	for _, file := range r.configBuilder.GetFiles() {
+		info, err := os.Stat(file)
+		if err != nil {
+			zap.S().Warnf("failed to stat %s: %s", file, err.Error())
+			return r.modTime
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+
+	return modTime
+}
+
+type reloadableTarget struct {
+	sync.Mutex
+	target interfaces.TargetInterface
+}
+
+// newReloadableTarget wraps a target so that it can be replaced while the
+// source keeps running, avoiding a consumer restart when only the target changes.
+func newReloadableTarget(target interfaces.TargetInterface) *reloadableTarget {
+	return &reloadableTarget{target: target}
+}
+
+// targetSwap is a target replacing the current one on reload.
+type targetSwap struct {
+	current *reloadableTarget
+	next    interfaces.TargetInterface
+}
+
+// swapTargets replaces every target or none of them. The replacements are
+// initialized and the current targets flushed before any is replaced, the
+// replacements are closed when any of it fails.
+func swapTargets(swaps []targetSwap) error {
+	for _, swap := range swaps {
+		if err := swap.next.Initialize(); err != nil {
+			closeSwaps(swaps)
+			return errors.Errorf("failed to initialize target: %s", err.Error())
+		}
+	}
+
+	for _, swap := range swaps {
+		swap.current.Lock()
+		defer swap.current.Unlock()
+	}
+
+	for _, swap := range swaps {
+		if err := swap.current.target.Flush(); err != nil {
+			closeSwaps(swaps)
+			return errors.Errorf("failed to flush current target: %s", err.Error())
+		}
+	}
+
+	for _, swap := range swaps {
+		if err := swap.current.target.Close(); err != nil {
+			zap.S().Warnf("failed to close current target: %s", err.Error())
+		}
+
+		swap.current.target = swap.next
+	}
+
+	return nil
+}
+
+func closeSwaps(swaps []targetSwap) {
+	for _, swap := range swaps {
+		_ = swap.next.Close()
+	}
+}
+
+func (r *reloadableTarget) Initialize() error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.target.Initialize()
+}
+
+func (r *reloadableTarget) Attach(key string, data map[string]interface{}) error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.target.Attach(key, data)
+}
+
+func (r *reloadableTarget) Flush() error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.target.Flush()
+}
+
+func (r *reloadableTarget) CanFlush() bool {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.target.CanFlush()
+}
+
+func (r *reloadableTarget) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.target.Close()
+}
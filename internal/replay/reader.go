@@ -0,0 +1,356 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	context2 "draethos.io.com/internal/context"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/target"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	KafkaGroupDefault = "draethos-replay"
+	KafkaTimeoutMs    = 10000
+	SqsWaitSeconds    = 5
+)
+
+// Record is a dlq envelope as stored, Ack removes it from the dlq once the
+// replayed event was flushed to the target.
+type Record struct {
+	Content []byte
+	Ack     func() error
+}
+
+// Reader returns io.EOF once every envelope available was read.
+type Reader interface {
+	Read() (*Record, error)
+	Close() error
+}
+
+// NewReader reads the envelopes back from the dlq the pipeline writes to, the
+// codec is the one of the dlq.
+func NewReader(dlqSpec specs.Target, codec interfaces.CodecInterface) (Reader, error) {
+	switch dlqSpec.Type {
+	case context2.KafkaTarget:
+		return newKafkaReader(dlqSpec)
+	case context2.S3Target:
+		encoded, _ := codec.(interfaces.EncodedCodecInterface)
+		return newS3Reader(dlqSpec, encoded)
+	case context2.SqsTarget:
+		return newSqsReader(dlqSpec)
+	case "":
+		return nil, errors.Errorf("dlq not defined on the pipeline, inform the dead-lettered events with --input")
+	default:
+		return nil, errors.Errorf("dlq %s cannot be read back, export the events to a jsonl file and inform it with --input", dlqSpec.Type)
+	}
+}
+
+// RecordCodec returns the codec reading the records of the dlq. Files written
+// to s3 are encoded as a whole, so their lines are read by the codec ending
+// the chain.
+func RecordCodec(dlqSpec specs.Target, codec interfaces.CodecInterface) interfaces.CodecInterface {
+	if encoded, ok := codec.(interfaces.EncodedCodecInterface); ok && dlqSpec.Type == context2.S3Target {
+		return encoded.Codec()
+	}
+
+	return codec
+}
+
+type fileReader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+}
+
+// NewFileReader reads one envelope per line of a jsonl file.
+func NewFileReader(path string) (Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Errorf("failed to load %s: %s", path, err.Error())
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	return &fileReader{file: file, scanner: scanner}, nil
+}
+
+func (f *fileReader) Read() (*Record, error) {
+	for f.scanner.Scan() {
+		if strings.TrimSpace(f.scanner.Text()) == "" {
+			continue
+		}
+
+		return &Record{Content: append([]byte(nil), f.scanner.Bytes()...)}, nil
+	}
+
+	if err := f.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+func (f *fileReader) Close() error {
+	return f.file.Close()
+}
+
+type kafkaReader struct {
+	consumer *kafka.Consumer
+	timeout  int
+}
+
+// newKafkaReader consumes the dlq topic with its own consumer group until no
+// message arrives within the timeout, offsets are committed per replayed event
+// so a new run resumes where it stopped.
+func newKafkaReader(dlqSpec specs.Target) (Reader, error) {
+	kafkaSpecs, err := dlqSpec.TargetSpecs.Kafka()
+	if err != nil {
+		return nil, errors.Errorf("kafka dlq: %s", err.Error())
+	}
+
+	configMap := kafka.ConfigMap{
+		"group.id":             KafkaGroupDefault,
+		"auto.offset.reset":    "earliest",
+		"enable.auto.commit":   false,
+		"enable.partition.eof": true,
+	}
+
+	for key, value := range dlqSpec.TargetSpecs.Configurations {
+		if key == "group.id" {
+			continue
+		}
+		_ = configMap.SetKey(key, value)
+	}
+
+	consumer, err := kafka.NewConsumer(&configMap)
+	if err != nil {
+		return nil, errors.Errorf("kafka dlq: %s", err.Error())
+	}
+
+	if err = consumer.SubscribeTopics(strings.Split(kafkaSpecs.Topic, ","), nil); err != nil {
+		consumer.Close()
+		return nil, errors.Errorf("failed to subscribe dlq topic %s: %s", kafkaSpecs.Topic, err.Error())
+	}
+
+	return &kafkaReader{consumer: consumer, timeout: KafkaTimeoutMs}, nil
+}
+
+func (k *kafkaReader) Read() (*Record, error) {
+	for {
+		switch e := k.consumer.Poll(k.timeout).(type) {
+		case nil:
+			return nil, io.EOF
+		case *kafka.Message:
+			message := e
+			return &Record{Content: message.Value, Ack: func() error {
+				_, err := k.consumer.CommitMessage(message)
+				return err
+			}}, nil
+		case kafka.Error:
+			if e.IsFatal() {
+				return nil, e
+			}
+			zap.S().Debugf(e.Error())
+		}
+	}
+}
+
+func (k *kafkaReader) Close() error {
+	return k.consumer.Close()
+}
+
+type s3Reader struct {
+	client    *s3.S3
+	encoded   interfaces.EncodedCodecInterface
+	bucket    string
+	lineBreak []byte
+	keys      []string
+	lines     [][]byte
+}
+
+// newS3Reader lists the objects under the dlq prefix, up to the first date
+// placeholder, objects are kept on the bucket after the replay.
+func newS3Reader(dlqSpec specs.Target, encoded interfaces.EncodedCodecInterface) (Reader, error) {
+	s3Specs, err := dlqSpec.TargetSpecs.S3()
+	if err != nil {
+		return nil, errors.Errorf("s3 dlq: %s", err.Error())
+	}
+
+	sess, err := target.NewAwsSession(dlqSpec.TargetSpecs.Configurations)
+	if err != nil {
+		return nil, errors.Errorf("s3 dlq: %s", err.Error())
+	}
+
+	reader := &s3Reader{
+		client:    s3.New(sess),
+		encoded:   encoded,
+		bucket:    s3Specs.Bucket,
+		lineBreak: []byte(s3Specs.LineBreak),
+	}
+
+	prefix := s3Specs.Prefix
+	if index := strings.Index(prefix, "%{"); index >= 0 {
+		prefix = prefix[:index]
+	}
+
+	err = reader.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(reader.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			reader.keys = append(reader.keys, aws.StringValue(object.Key))
+		}
+		return true
+	})
+
+	if err != nil {
+		return nil, errors.Errorf("failed to list bucket %s: %s", reader.bucket, err.Error())
+	}
+
+	zap.S().Infof("%d object(s) found on s3://%s/%s", len(reader.keys), reader.bucket, prefix)
+
+	return reader, nil
+}
+
+func (s *s3Reader) Read() (*Record, error) {
+	for len(s.lines) == 0 {
+		if len(s.keys) == 0 {
+			return nil, io.EOF
+		}
+
+		key := s.keys[0]
+		s.keys = s.keys[1:]
+
+		object, err := s.client.GetObject(&s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+		if err != nil {
+			return nil, errors.Errorf("failed to download %s: %s", key, err.Error())
+		}
+
+		content, err := ioutil.ReadAll(object.Body)
+		object.Body.Close()
+		if err != nil {
+			return nil, errors.Errorf("failed to download %s: %s", key, err.Error())
+		}
+
+		if s.encoded != nil {
+			if content, err = s.encoded.Decode(content); err != nil {
+				return nil, errors.Errorf("failed to decode %s: %s", key, err.Error())
+			}
+		}
+
+		for _, line := range bytes.Split(content, s.lineBreak) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				s.lines = append(s.lines, line)
+			}
+		}
+	}
+
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+
+	return &Record{Content: line}, nil
+}
+
+func (s *s3Reader) Close() error {
+	return nil
+}
+
+type sqsReader struct {
+	client   *sqs.SQS
+	queueUrl string
+	messages []*sqs.Message
+}
+
+// newSqsReader receives the messages of the dlq queue until it is empty,
+// messages are deleted once replayed.
+func newSqsReader(dlqSpec specs.Target) (Reader, error) {
+	sqsSpecs, err := dlqSpec.TargetSpecs.Sqs()
+	if err != nil {
+		return nil, errors.Errorf("sqs dlq: %s", err.Error())
+	}
+
+	sess, err := target.NewAwsSession(dlqSpec.TargetSpecs.Configurations)
+	if err != nil {
+		return nil, errors.Errorf("sqs dlq: %s", err.Error())
+	}
+
+	reader := &sqsReader{client: sqs.New(sess), queueUrl: sqsSpecs.QueueUrl}
+	if reader.queueUrl == "" {
+		output, err := reader.client.GetQueueUrl(&sqs.GetQueueUrlInput{QueueName: aws.String(sqsSpecs.Queue)})
+		if err != nil {
+			return nil, errors.Errorf("queue %s not found: %s", sqsSpecs.Queue, err.Error())
+		}
+		reader.queueUrl = aws.StringValue(output.QueueUrl)
+	}
+
+	return reader, nil
+}
+
+func (s *sqsReader) Read() (*Record, error) {
+	if len(s.messages) == 0 {
+		output, err := s.client.ReceiveMessage(&sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.queueUrl),
+			MaxNumberOfMessages: aws.Int64(10),
+			WaitTimeSeconds:     aws.Int64(SqsWaitSeconds),
+		})
+		if err != nil {
+			return nil, errors.Errorf("failed to receive messages from %s: %s", s.queueUrl, err.Error())
+		}
+
+		if len(output.Messages) == 0 {
+			return nil, io.EOF
+		}
+
+		s.messages = output.Messages
+	}
+
+	message := s.messages[0]
+	s.messages = s.messages[1:]
+
+	return &Record{Content: []byte(aws.StringValue(message.Body)), Ack: func() error {
+		if _, err := s.client.DeleteMessage(&sqs.DeleteMessageInput{
+			QueueUrl:      aws.String(s.queueUrl),
+			ReceiptHandle: message.ReceiptHandle,
+		}); err != nil {
+			return errors.Errorf("failed to delete message %s: %s", aws.StringValue(message.MessageId), err.Error())
+		}
+		return nil
+	}}, nil
+}
+
+func (s *sqsReader) Close() error {
+	return nil
+}
+
+// Describe names where the envelopes of the dlq are read from.
+func Describe(dlqSpec specs.Target) string {
+	switch dlqSpec.Type {
+	case context2.KafkaTarget:
+		if kafkaSpecs, err := dlqSpec.TargetSpecs.Kafka(); err == nil {
+			return fmt.Sprintf("kafka topic %s", kafkaSpecs.Topic)
+		}
+	case context2.S3Target:
+		if s3Specs, err := dlqSpec.TargetSpecs.S3(); err == nil {
+			return fmt.Sprintf("s3://%s/%s", s3Specs.Bucket, s3Specs.Prefix)
+		}
+	case context2.SqsTarget:
+		if sqsSpecs, err := dlqSpec.TargetSpecs.Sqs(); err == nil {
+			return fmt.Sprintf("sqs queue %s%s", sqsSpecs.QueueUrl, sqsSpecs.Queue)
+		}
+	}
+
+	return dlqSpec.Type
+}
@@ -0,0 +1,247 @@
+package replay
+
+import (
+	"draethos.io.com/internal/dlq"
+	interfaces2 "draethos.io.com/internal/interfaces"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Options filter the envelopes replayed, Failed receives the envelopes that
+// failed again as jsonl so they can be replayed later with a file reader.
+type Options struct {
+	ErrorType string
+	Since     time.Time
+	Until     time.Time
+	DryRun    bool
+	Failed    io.Writer
+}
+
+type Failure struct {
+	Error string
+	Count int
+}
+
+type Report struct {
+	Read     int
+	Skipped  int
+	Replayed int
+	Failed   int
+	errors   map[string]int
+}
+
+// Failures groups the events that failed again by error, most frequent first.
+func (r Report) Failures() []Failure {
+	failures := make([]Failure, 0, len(r.errors))
+	for message, count := range r.errors {
+		failures = append(failures, Failure{Error: message, Count: count})
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].Count != failures[j].Count {
+			return failures[i].Count > failures[j].Count
+		}
+		return failures[i].Error < failures[j].Error
+	})
+
+	return failures
+}
+
+type pending struct {
+	record   *Record
+	envelope dlq.Envelope
+}
+
+// Replayer strips the envelopes read from the dlq and sends the original
+// events to the target, envelopes are acknowledged once the target flushed.
+type Replayer struct {
+	reader      Reader
+	codec       interfaces2.CodecInterface
+	sourceCodec interfaces2.CodecInterface
+	target      interfaces2.TargetInterface
+	options     Options
+	pending     []pending
+	report      Report
+}
+
+// NewReplayer builds a replayer, codec decodes the envelopes as stored on the
+// dlq and sourceCodec the raw content of events that could not be decoded.
+func NewReplayer(reader Reader,
+	codec interfaces2.CodecInterface,
+	sourceCodec interfaces2.CodecInterface,
+	target interfaces2.TargetInterface,
+	options Options) *Replayer {
+	return &Replayer{
+		reader:      reader,
+		codec:       codec,
+		sourceCodec: sourceCodec,
+		target:      target,
+		options:     options,
+		report:      Report{errors: make(map[string]int)},
+	}
+}
+
+func (r *Replayer) Run() (Report, error) {
+	for {
+		record, err := r.reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return r.report, errors.Errorf("failed to read dlq: %s", err.Error())
+		}
+
+		r.report.Read++
+
+		if err = r.replay(record); err != nil {
+			return r.report, err
+		}
+	}
+
+	if err := r.flush(); err != nil {
+		return r.report, err
+	}
+
+	return r.report, nil
+}
+
+func (r *Replayer) replay(record *Record) error {
+	content, err := r.codec.Deserialize(record.Content)
+	if err != nil {
+		return r.invalid(record, err)
+	}
+
+	envelope, err := dlq.Parse(content)
+	if err != nil {
+		return r.invalid(record, err)
+	}
+
+	if !r.matches(envelope) {
+		r.report.Skipped++
+		return nil
+	}
+
+	payload := envelope.Payload
+	if payload == nil {
+		if payload, err = r.sourceCodec.Deserialize([]byte(envelope.Raw)); err != nil {
+			return r.fail(envelope, dlq.DecodeError, err)
+		}
+		envelope.Payload = payload
+	}
+
+	if r.options.DryRun {
+		r.report.Replayed++
+		return nil
+	}
+
+	if err = r.target.Attach(envelope.Key, payload); err != nil {
+		return r.fail(envelope, dlq.TargetError, err)
+	}
+
+	r.pending = append(r.pending, pending{record: record, envelope: envelope})
+
+	if !r.target.CanFlush() {
+		return nil
+	}
+
+	return r.flush()
+}
+
+func (r *Replayer) matches(envelope dlq.Envelope) bool {
+	if r.options.ErrorType != "" && r.options.ErrorType != envelope.ErrorType {
+		return false
+	}
+
+	if !r.options.Since.IsZero() && envelope.FailedAt.Before(r.options.Since) {
+		return false
+	}
+
+	if !r.options.Until.IsZero() && envelope.FailedAt.After(r.options.Until) {
+		return false
+	}
+
+	return true
+}
+
+func (r *Replayer) flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+
+	events := r.pending
+	r.pending = nil
+
+	if err := r.target.Flush(); err != nil {
+		for _, event := range events {
+			if err := r.fail(event.envelope, dlq.TargetError, err); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, event := range events {
+		r.report.Replayed++
+
+		if event.record.Ack == nil {
+			continue
+		}
+
+		if err := event.record.Ack(); err != nil {
+			zap.S().Warnf("event %s replayed but not removed from dlq: %s", event.envelope.Key, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// fail records an event rejected again, keeping it on the failed output with
+// the new error.
+func (r *Replayer) fail(envelope dlq.Envelope, errorType string, err error) error {
+	r.report.Failed++
+	r.report.errors[fmt.Sprintf("%s: %s", errorType, err.Error())]++
+
+	if r.options.Failed == nil {
+		return nil
+	}
+
+	retry := dlq.NewEnvelope(errorType, envelope.Source, envelope.Key, err)
+	retry.Payload, retry.Raw = envelope.Payload, envelope.Raw
+
+	return r.write(retry.Map())
+}
+
+func (r *Replayer) invalid(record *Record, err error) error {
+	r.report.Failed++
+	r.report.errors[fmt.Sprintf("invalid envelope: %s", err.Error())]++
+
+	if r.options.Failed == nil {
+		return nil
+	}
+
+	if _, err = fmt.Fprintf(r.options.Failed, "%s\n", record.Content); err != nil {
+		return errors.Errorf("failed to write failed event: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r *Replayer) write(content map[string]interface{}) error {
+	line, err := json.Marshal(content)
+	if err != nil {
+		return errors.Errorf("failed to serialize failed event: %s", err.Error())
+	}
+
+	if _, err = fmt.Fprintf(r.options.Failed, "%s\n", line); err != nil {
+		return errors.Errorf("failed to write failed event: %s", err.Error())
+	}
+
+	return nil
+}
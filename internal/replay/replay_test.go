@@ -0,0 +1,154 @@
+package replay
+
+import (
+	"bytes"
+	codec "draethos.io.com/internal/codec"
+	"draethos.io.com/internal/dlq"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const ReplayEnvelopesTest = `{"error":{"type":"decode","message":"invalid character"},"source":"kafka","key":"k1","failedAt":"2021-10-18T10:00:00Z","raw":"{\"id\": 1}"}
+{"error":{"type":"target","message":"connection refused"},"source":"jsonl","key":"k2","failedAt":"2021-10-19T10:00:00Z","payload":{"id":2}}
+{"error":{"type":"target","message":"connection refused"},"source":"jsonl","key":"reject","failedAt":"2021-10-19T11:00:00Z","payload":{"id":3}}
+{"error":{"type":"decode","message":"invalid character"},"source":"http","key":"k4","failedAt":"2021-10-19T12:00:00Z","raw":"not json"}
+not an envelope
+`
+
+type captureTarget struct {
+	attached map[string]map[string]interface{}
+	flushed  int
+}
+
+func (c *captureTarget) Initialize() error { return nil }
+func (c *captureTarget) CanFlush() bool    { return false }
+func (c *captureTarget) Close() error      { return nil }
+
+func (c *captureTarget) Attach(key string, data map[string]interface{}) error {
+	if key == "reject" {
+		return errors.New("duplicate key")
+	}
+
+	c.attached[key] = data
+	return nil
+}
+
+func (c *captureTarget) Flush() error {
+	c.flushed++
+	return nil
+}
+
+func TestShouldKeepEnvelopeContent(t *testing.T) {
+	envelope := dlq.NewEnvelope(dlq.TargetError, "kafka", "k1", errors.New("connection refused")).
+		WithPayload(map[string]interface{}{"id": float64(1)})
+
+	content, err := json.Marshal(envelope.Map())
+	if err != nil {
+		t.Fatalf("failed to serialize envelope: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	_ = json.Unmarshal(content, &decoded)
+
+	parsed, err := dlq.Parse(decoded)
+	if err != nil {
+		t.Fatalf("failed to parse envelope: %v", err)
+	}
+
+	if parsed.ErrorType != dlq.TargetError || parsed.Error != "connection refused" || parsed.Source != "kafka" ||
+		parsed.Key != "k1" || !parsed.FailedAt.Equal(envelope.FailedAt) || parsed.Payload["id"] != float64(1) {
+		t.Errorf("envelope changed on round trip: %+v", parsed)
+	}
+
+	if _, err = dlq.Parse(map[string]interface{}{"id": 1}); err == nil {
+		t.Errorf("expected error parsing a plain event")
+	}
+}
+
+func TestShouldReplayEnvelopesAndReportFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dlq.jsonl")
+	if err := ioutil.WriteFile(path, []byte(ReplayEnvelopesTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewFileReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reader.Close()
+
+	target := &captureTarget{attached: make(map[string]map[string]interface{})}
+	var failed bytes.Buffer
+	report, err := NewReplayer(reader, codec.NewJsonCodec(), codec.NewJsonCodec(), target, Options{
+		Since:  time.Date(2021, 10, 18, 0, 0, 0, 0, time.UTC),
+		Failed: &failed,
+	}).Run()
+	if err != nil {
+		t.Fatalf("failed to replay: %v", err)
+	}
+
+	if report.Read != 5 || report.Skipped != 0 || report.Replayed != 2 || report.Failed != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	if target.attached["k1"]["id"] != float64(1) || target.attached["k2"]["id"] != float64(2) || target.flushed != 1 {
+		t.Errorf("unexpected events replayed: %v, %d flush(es)", target.attached, target.flushed)
+	}
+
+	lines := strings.Split(strings.TrimSpace(failed.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "duplicate key") || lines[2] != "not an envelope" {
+		t.Errorf("unexpected failed output:\n%s", failed.String())
+	}
+
+	if failures := report.Failures(); len(failures) != 3 || failures[0].Count != 1 {
+		t.Errorf("unexpected failures: %+v", failures)
+	}
+}
+
+func TestShouldFilterEnvelopes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dlq.jsonl")
+	if err := ioutil.WriteFile(path, []byte(ReplayEnvelopesTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewFileReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reader.Close()
+
+	target := &captureTarget{attached: make(map[string]map[string]interface{})}
+	report, err := NewReplayer(reader, codec.NewJsonCodec(), codec.NewJsonCodec(), target, Options{
+		ErrorType: dlq.TargetError,
+		Until:     time.Date(2021, 10, 19, 10, 30, 0, 0, time.UTC),
+		DryRun:    true,
+	}).Run()
+	if err != nil {
+		t.Fatalf("failed to replay: %v", err)
+	}
+
+	if report.Skipped != 3 || report.Replayed != 1 || report.Failed != 1 || len(target.attached) != 0 {
+		t.Errorf("unexpected report: %+v, attached: %v", report, target.attached)
+	}
+}
+
+func TestShouldReadS3DlqLinesWithTheCodecEndingTheChain(t *testing.T) {
+	chain := codec.NewChainCodec([]interfaces.EncodingInterface{codec.NewGzipEncoding()}, codec.NewJsonCodec())
+
+	if _, ok := RecordCodec(specs.Target{Type: "s3"}, chain).(interfaces.EncodedCodecInterface); ok {
+		t.Error("expected s3 records to be read by the json codec")
+	}
+
+	if _, ok := RecordCodec(specs.Target{Type: "sqs"}, chain).(interfaces.EncodedCodecInterface); !ok {
+		t.Error("expected sqs records to be read by the chain")
+	}
+}
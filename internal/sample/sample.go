@@ -0,0 +1,445 @@
+package sample
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	JsonLFormat  = "jsonl"
+	CsvFormat    = "csv"
+	DefaultLimit = 1000
+
+	KindNull    = "null"
+	KindBoolean = "boolean"
+	KindInteger = "integer"
+	KindNumber  = "number"
+	KindString  = "string"
+	KindObject  = "object"
+	KindList    = "list"
+)
+
+var (
+	isoDatePattern = regexp.MustCompile(`^[12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])`)
+
+	dateLayouts = []string{
+		"02/01/2006", "01/02/2006", "2006/01/02", "02-01-2006", "02.01.2006",
+		"02/01/2006 15:04:05", "01/02/2006 15:04:05", "2006/01/02 15:04:05",
+		time.RFC1123, time.RFC1123Z, time.RFC822, time.RFC822Z, time.RFC850, time.ANSIC, time.UnixDate,
+	}
+
+	timestampNames = []string{"date", "time", "_at", "timestamp", "epoch"}
+)
+
+// Field is a key seen in the sample records, Value holds the value used to
+// pick column types as the targets would for the first event received.
+type Field struct {
+	Name        string
+	Value       interface{}
+	Kinds       []string
+	Count       int
+	Nulls       int
+	Suggestions []string
+
+	strings []string
+}
+
+type Sample struct {
+	Path    string
+	Format  string
+	Records int
+	Fields  []*Field
+
+	fields map[string]*Field
+}
+
+// Read decodes up to limit records of a jsonl or csv file the same way the
+// jsonl and csv sources do and infers the type of every field.
+func Read(path string, limit int) (*Sample, error) {
+	format, err := Format(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Errorf("failed to load sample %s: %s", path, err.Error())
+	}
+
+	defer file.Close()
+
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	sample := New(path, format)
+	switch format {
+	case CsvFormat:
+		err = sample.readCsv(file, limit)
+	default:
+		err = sample.readJsonL(file, limit)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if sample.Records == 0 {
+		return nil, errors.Errorf("sample %s has no records", path)
+	}
+
+	sample.Complete()
+
+	return sample, nil
+}
+
+// New starts an empty sample, records are informed with Add and the types
+// are only final after Complete.
+func New(path string, format string) *Sample {
+	return &Sample{Path: path, Format: format, fields: make(map[string]*Field)}
+}
+
+// Complete counts the records missing each field and builds the suggestions.
+func (s *Sample) Complete() {
+	for _, field := range s.Fields {
+		field.Nulls += s.Records - field.Count - field.Nulls
+		field.suggest(s)
+	}
+}
+
+// Format resolves the sample format from the file extension.
+func Format(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return JsonLFormat, nil
+	case ".csv":
+		return CsvFormat, nil
+	}
+
+	return "", errors.Errorf("unsupported sample %s, use a .jsonl or .csv file", path)
+}
+
+func (s *Sample) readJsonL(reader io.Reader, limit int) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for s.Records < limit && scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var payload = make(map[string]interface{}, 0)
+		if err := json.Unmarshal(scanner.Bytes(), &payload); err != nil {
+			return errors.Errorf("line %d: failed to decode json record: %s", line, err.Error())
+		}
+
+		s.Add(payload, keyOrder(scanner.Bytes()))
+	}
+
+	return scanner.Err()
+}
+
+func (s *Sample) readCsv(reader io.Reader, limit int) error {
+	csvReader := csv.NewReader(reader)
+
+	var columns []string
+	for s.Records < limit {
+		records, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return errors.Errorf("failed to read csv sample: %s", err.Error())
+		}
+
+		if columns == nil {
+			for _, v := range records {
+				columns = append(columns, strings.ToLower(strings.ReplaceAll(v, " ", "_")))
+			}
+			continue
+		}
+
+		payload := make(map[string]interface{}, 0)
+		for k, v := range records {
+			if k < len(columns) {
+				payload[columns[k]] = v
+			}
+		}
+
+		s.Add(payload, columns)
+	}
+
+	return nil
+}
+
+// Add observes a record, keys informs the order fields are listed and the
+// record keys are sorted when it is empty.
+func (s *Sample) Add(payload map[string]interface{}, keys []string) {
+	s.Records++
+
+	if len(keys) == 0 {
+		for key := range payload {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	for _, key := range keys {
+		value, ok := payload[key]
+		if !ok {
+			continue
+		}
+
+		field, ok := s.fields[key]
+		if !ok {
+			field = &Field{Name: key}
+			s.fields[key] = field
+			s.Fields = append(s.Fields, field)
+		}
+
+		field.observe(value)
+	}
+}
+
+func (f *Field) observe(value interface{}) {
+	kind := kindOf(value)
+	if kind == KindNull {
+		f.Nulls++
+		return
+	}
+
+	f.Count++
+
+	// integral json numbers are decoded as float64 as well, a field holding
+	// both is a number field rather than a mixed one
+	switch {
+	case kind == KindInteger && contains(f.Kinds, KindNumber):
+		kind = KindNumber
+	case kind == KindNumber && contains(f.Kinds, KindInteger):
+		for i, k := range f.Kinds {
+			if k == KindInteger {
+				f.Kinds[i] = KindNumber
+			}
+		}
+	}
+
+	if !contains(f.Kinds, kind) {
+		f.Kinds = append(f.Kinds, kind)
+	}
+
+	if f.Value == nil {
+		f.Value = value
+	}
+
+	if text, ok := value.(string); ok {
+		f.strings = append(f.strings, text)
+
+		current, isString := f.Value.(string)
+		if isString && len(text) > len(current) && len(text) > 255 {
+			f.Value = text
+		}
+	}
+}
+
+// Kind returns the type seen on the sample, "mixed" when records disagree.
+func (f *Field) Kind() string {
+	switch len(f.Kinds) {
+	case 0:
+		return KindNull
+	case 1:
+		return f.Kinds[0]
+	}
+
+	return fmt.Sprintf("mixed (%s)", strings.Join(f.Kinds, ", "))
+}
+
+func (f *Field) suggest(sample *Sample) {
+	if len(f.Kinds) > 1 {
+		f.Suggestions = append(f.Suggestions,
+			fmt.Sprintf("mixed types %s, the column type follows the first event received, normalize the field to a single type", strings.Join(f.Kinds, ", ")))
+	}
+
+	if len(f.Kinds) == 0 {
+		f.Suggestions = append(f.Suggestions, "always null on the sample, the column is created as VARCHAR(255)")
+		return
+	}
+
+	first := f.Kinds[0]
+	if f.Nulls > 0 && (first == KindInteger || first == KindNumber || first == KindBoolean) {
+		f.Suggestions = append(f.Suggestions,
+			fmt.Sprintf("null in %d of %d records but the %s column is created NOT NULL, set a default before ingestion", f.Nulls, sample.Records, first))
+	}
+
+	if (first == KindInteger || first == KindNumber) && f.looksLikeEpoch() {
+		f.Suggestions = append(f.Suggestions, "unix timestamp stored as a number, convert it to ISO 8601 such as 2006-01-02T15:04:05 to get a timestamp column")
+	}
+
+	if first == KindObject || first == KindList {
+		f.Suggestions = append(f.Suggestions, fmt.Sprintf("nested %s stored as a json column, flatten it to query its fields", first))
+	}
+
+	if len(f.strings) == 0 || len(f.strings) != f.Count {
+		return
+	}
+
+	switch {
+	case all(f.strings, isInteger):
+		f.Suggestions = append(f.Suggestions, "integers stored as strings, the column is created as VARCHAR(255), convert them to numbers before ingestion")
+	case all(f.strings, isNumber):
+		f.Suggestions = append(f.Suggestions, "numbers stored as strings, the column is created as VARCHAR(255), convert them to numbers before ingestion")
+	case all(f.strings, isBoolean):
+		f.Suggestions = append(f.Suggestions, "booleans stored as strings, the column is created as VARCHAR(255), convert them to booleans before ingestion")
+	case all(f.strings, isForeignDate):
+		f.Suggestions = append(f.Suggestions, "dates stored as strings in a format the targets do not recognize, convert them to ISO 8601 such as 2006-01-02T15:04:05")
+	}
+}
+
+func (f *Field) looksLikeEpoch() bool {
+	name := strings.ToLower(f.Name)
+	matches := false
+	for _, candidate := range timestampNames {
+		if strings.Contains(name, candidate) {
+			matches = true
+		}
+	}
+
+	value, ok := f.Value.(float64)
+	if !ok || !matches {
+		return false
+	}
+
+	digits := len(strconv.FormatInt(int64(value), 10))
+
+	return digits == 10 || digits == 13
+}
+
+func kindOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return KindNull
+	case bool:
+		return KindBoolean
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return KindInteger
+	case float32:
+		return KindNumber
+	case float64:
+		if v == math.Trunc(v) {
+			return KindInteger
+		}
+		return KindNumber
+	case string:
+		return KindString
+	case map[string]interface{}:
+		return KindObject
+	case []interface{}:
+		return KindList
+	}
+
+	return KindString
+}
+
+func isInteger(value string) bool {
+	_, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return err == nil
+}
+
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return err == nil
+}
+
+func isBoolean(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "false":
+		return true
+	}
+
+	return false
+}
+
+func isForeignDate(value string) bool {
+	value = strings.TrimSpace(value)
+	if isoDatePattern.MatchString(value) {
+		return false
+	}
+
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func all(values []string, predicate func(string) bool) bool {
+	matched := false
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		if !predicate(value) {
+			return false
+		}
+
+		matched = true
+	}
+
+	return matched
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keyOrder lists the top level keys in the order they were written, the
+// decoded map does not keep it.
+func keyOrder(content []byte) []string {
+	keys := make([]string, 0)
+
+	decoder := json.NewDecoder(strings.NewReader(string(content)))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return keys
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return keys
+		}
+
+		key, _ := token.(string)
+		keys = append(keys, key)
+
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return keys
+		}
+	}
+
+	return keys
+}
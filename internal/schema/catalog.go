@@ -0,0 +1,395 @@
+package schema
+
+import (
+	"sort"
+
+	"draethos.io.com/internal/context"
+	"draethos.io.com/internal/logging"
+	"draethos.io.com/pkg/streams/specs"
+)
+
+var awsConfigurations = []Field{
+	{Name: "aws.region", Kind: KindString, Default: "us-east-1", Description: "aws region"},
+	{Name: "aws.profile", Kind: KindString, Default: "default", Description: "profile read from the credential file"},
+	{Name: "aws.access.key", Kind: KindString, Description: "static access key, used together with aws.secret.key"},
+	{Name: "aws.secret.key", Kind: KindString, Description: "static secret key, used together with aws.access.key"},
+	{Name: "aws.credential.file", Kind: KindString, Description: "shared credential file path"},
+}
+
+var connectors = []Connector{
+	{
+		Name:        context.KafkaSource,
+		Kind:        SourceConnector,
+		Description: "consume events from Apache Kafka topics",
+		Specs: []Field{
+			{Name: "topic", Kind: KindString, Example: "orders", Description: "comma separated list of topics, required unless topicPattern is set"},
+			{Name: "topicPattern", Kind: KindString, Example: "^orders\\..*", Description: "regular expression subscribing every matching topic, including the ones created later"},
+			{Name: "topicField", Kind: KindString, Example: "topic", Description: "event field the originating topic is written to"},
+			{Name: "timeoutMs", Kind: KindInteger, Minimum: Min(0), Description: "poll timeout in milliseconds"},
+			{Name: "workers", Kind: KindInteger, Minimum: Min(1), Default: 1, Description: "goroutines processing events, events of a partition or key are always processed in order"},
+			{Name: "dispatchBy", Kind: KindString, Default: "partition", Enum: []string{"partition", "key"}, Description: "how events are spread across workers"},
+			{Name: "startAt", Kind: KindString, Example: "2h", Description: "position the partitions start from, overriding committed offsets: earliest, latest, an RFC3339 time or a duration ago such as 2h"},
+			{Name: "stopAt", Kind: KindString, Description: "stop once every partition reached events newer than the time, RFC3339 or a duration ago"},
+			{Name: "offsets", Kind: KindObject, AllowUnknown: true, Example: map[string]interface{}{"0": 1200}, Description: "offset each partition starts from, keyed by partition or topic:partition, overriding startAt"},
+			{Name: "configurations", Kind: KindObject, Required: true, AllowUnknown: true, Description: "librdkafka consumer configurations", Fields: []Field{
+				{Name: "bootstrap.servers", Kind: KindString, Required: true, Example: "localhost:9092", Description: "kafka brokers"},
+				{Name: "group.id", Kind: KindString, Required: true, Example: "draethos", Description: "consumer group"},
+				{Name: "auto.offset.reset", Kind: KindString, Enum: []string{"smallest", "earliest", "beginning", "largest", "latest", "end", "error"}, Description: "offset used when the group has no committed offset"},
+			}},
+		},
+	},
+	{
+		Name:        context.HttpSource,
+		Kind:        SourceConnector,
+		Description: "receive events through http requests",
+		Specs: []Field{
+			{Name: "endpoint", Kind: KindString, Required: true, Example: "/events", Description: "request path"},
+			{Name: "method", Kind: KindString, Default: "GET,POST", Description: "comma separated list of allowed methods"},
+			{Name: "configurations", Kind: KindObject, Description: "http server configurations", Fields: []Field{
+				{Name: "writeTimeout", Kind: KindDuration, Minimum: Min(0), Default: "15s", Description: "write timeout, such as \"15s\" or an integer in seconds"},
+				{Name: "readTimeout", Kind: KindDuration, Minimum: Min(0), Default: "15s", Description: "read timeout, such as \"15s\" or an integer in seconds"},
+				{Name: "idleTimeout", Kind: KindDuration, Minimum: Min(0), Default: "15s", Description: "idle timeout, such as \"15s\" or an integer in seconds"},
+				{Name: "serverName", Kind: KindString, Description: "tls server name"},
+			}},
+		},
+	},
+	{
+		Name:        context.CsvSource,
+		Kind:        SourceConnector,
+		Description: "read events from a csv file or directory",
+		Specs: []Field{
+			{Name: "path", Kind: KindString, Required: true, Example: "./data/events.csv", Description: "csv file or directory, files may be compressed with gzip, zstd or bzip2 or be zip archives"},
+			{Name: "configurations", Kind: KindObject, Description: "csv parsing configurations", Fields: []Field{
+				{Name: "delimiter", Kind: KindString, Default: ",", Description: "single character separating values"},
+				{Name: "quote", Kind: KindString, Default: `"`, Description: "single character quoting values, empty disables quoting"},
+				{Name: "escape", Kind: KindString, Description: "single character escaping the next one, quotes are escaped by doubling them when not set"},
+				{Name: "comment", Kind: KindString, Example: "#", Description: "prefix of the lines skipped"},
+				{Name: "header.row", Kind: KindInteger, Minimum: Min(0), Default: 1, Description: "row holding the column names, not counting blank and comment lines, rows before it are skipped, 0 when the file has no header"},
+				{Name: "columns", Kind: KindList, Description: "column names, replace the header names and are required when header.row is 0"},
+				{Name: "skip.rows", Kind: KindInteger, Minimum: Min(0), Default: 0, Description: "rows skipped after the header"},
+				{Name: "null.values", Kind: KindList, Example: []string{"", "NULL"}, Description: "values read as null"},
+				{Name: "trim", Kind: KindBoolean, Default: false, Description: "trim spaces around values"},
+				{Name: "normalize.columns", Kind: KindBoolean, Default: true, Description: "lowercase the header names and replace spaces by underscores"},
+				{Name: "types", Kind: KindObject, AllowUnknown: true, Example: map[string]interface{}{"amount": "number"}, Description: "type of each column: string, integer, number, boolean or json"},
+				{Name: "infer.types", Kind: KindBoolean, Default: false, Description: "infer the type of the columns without one from the first rows"},
+				{Name: "infer.rows", Kind: KindInteger, Minimum: Min(1), Default: 100, Description: "rows read to infer the type of the columns"},
+			}},
+		},
+	},
+	{
+		Name:        context.JsonLSource,
+		Kind:        SourceConnector,
+		Description: "read events from a jsonl file or directory",
+		Specs: []Field{
+			{Name: "path", Kind: KindString, Required: true, Example: "./data/events.jsonl", Description: "jsonl file or directory, files may be compressed with gzip, zstd or bzip2 or be zip archives"},
+		},
+	},
+	{
+		Name:        context.GeneratorSource,
+		Kind:        SourceConnector,
+		Description: "generate synthetic events from a template, for load tests and benchmarks",
+		Specs: []Field{
+			{Name: "template", Kind: KindObject, Required: true, AllowUnknown: true, Example: map[string]interface{}{
+				"id":        "{{uuid}}",
+				"name":      "{{name}}",
+				"price":     "{{float 1 100}}",
+				"createdAt": "{{timestamp}}",
+			}, Description: "event template, strings accept {{uuid}}, {{name}}, {{int 1 10}}, {{float 1 10}}, {{choice a b}}, {{timestamp}} and other placeholders"},
+			{Name: "configurations", Kind: KindObject, Description: "generator configurations", Fields: []Field{
+				{Name: "rate", Kind: KindNumber, Minimum: Min(0), Example: 1000, Description: "events per second, unlimited when omitted"},
+				{Name: "count", Kind: KindInteger, Minimum: Min(0), Example: 100000, Description: "events generated before the source stops, unlimited when omitted"},
+				{Name: "seed", Kind: KindInteger, Description: "random seed, the same seed generates the same events"},
+			}},
+		},
+	},
+	{
+		Name:        context.KafkaTarget,
+		Kind:        TargetConnector,
+		Description: "produce events to an Apache Kafka topic",
+		Specs: []Field{
+			{Name: "topic", Kind: KindString, Required: true, Example: "orders.processed", Description: "topic name"},
+			codecField(),
+			codecConfigurationsField(),
+			batchSizeField(),
+			flushField(),
+			{Name: "configurations", Kind: KindObject, Required: true, AllowUnknown: true, Description: "librdkafka producer configurations", Fields: []Field{
+				{Name: "bootstrap.servers", Kind: KindString, Required: true, Example: "localhost:9092", Description: "kafka brokers"},
+			}},
+		},
+	},
+	{
+		Name:        context.S3Target,
+		Kind:        TargetConnector,
+		Description: "upload batches of events to AWS S3",
+		Specs: []Field{
+			{Name: "bucket", Kind: KindString, Required: true, Example: "draethos-events", Description: "bucket name"},
+			{Name: "prefix", Kind: KindString, Example: "events/%{YEAR}/%{MONTH}/%{DAY}", Description: "object prefix, accepts %{YEAR}, %{MONTH}, %{DAY}, %{HOUR}, %{MINUTE}, %{SECOND} and %{topic}"},
+			codecField(),
+			codecConfigurationsField(),
+			batchSizeField(),
+			bufferSizeField(),
+			{Name: "lineBreak", Kind: KindString, Default: "\n", Description: "separator written between events"},
+			topicFieldField(),
+			flushField(),
+			{Name: "configurations", Kind: KindObject, Description: "aws configurations", Fields: awsConfigurations},
+		},
+	},
+	{
+		Name:        context.SqsTarget,
+		Kind:        TargetConnector,
+		Description: "send events to an AWS SQS queue",
+		Specs: []Field{
+			{Name: "queueUrl", Kind: KindString, Required: true, Example: "https://sqs.us-east-1.amazonaws.com/000000000000/events", Description: "queue url"},
+			{Name: "queue", Kind: KindString, Description: "queue name"},
+			codecField(),
+			codecConfigurationsField(),
+			batchSizeField(),
+			bufferSizeField(),
+			{Name: "delaySeconds", Kind: KindInteger, Minimum: Min(0), Maximum: Max(900), Description: "message delivery delay in seconds"},
+			flushField(),
+			{Name: "configurations", Kind: KindObject, Description: "aws configurations", Fields: awsConfigurations},
+		},
+	},
+	{
+		Name:        context.SnsTarget,
+		Kind:        TargetConnector,
+		Description: "publish events to an AWS SNS topic",
+		Specs: []Field{
+			{Name: "topicArn", Kind: KindString, Required: true, Example: "arn:aws:sns:us-east-1:000000000000:events", Description: "topic arn"},
+			codecField(),
+			codecConfigurationsField(),
+			batchSizeField(),
+			bufferSizeField(),
+			flushField(),
+			{Name: "configurations", Kind: KindObject, Description: "aws configurations", Fields: awsConfigurations},
+		},
+	},
+	{
+		Name:        context.PgSqlTarget,
+		Kind:        TargetConnector,
+		Description: "insert events into a Postgres table, columns are created on demand",
+		Specs: []Field{
+			{Name: "database", Kind: KindString, Required: true, Example: "draethos", Description: "database name"},
+			{Name: "table", Kind: KindString, Required: true, Example: "events", Description: "table name, accepts %{topic}"},
+			{Name: "keyColumnName", Kind: KindString, Default: "id", Description: "primary key column"},
+			topicFieldField(),
+			batchSizeField(),
+			flushField(),
+			{Name: "configurations", Kind: KindObject, Required: true, Description: "connection configurations", Fields: []Field{
+				{Name: "host", Kind: KindString, Required: true, Example: "127.0.0.1", Description: "database host"},
+				{Name: "port", Kind: KindInteger, Minimum: Min(1), Maximum: Max(65535), Default: 5432, Description: "database port"},
+				{Name: "user", Kind: KindString, Required: true, Example: "draethos", Description: "database user"},
+				{Name: "password", Kind: KindString, Required: true, Example: "${DATABASE_PASSWORD}", Description: "database password"},
+				{Name: "sslmode", Kind: KindString, Required: true, Enum: []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}, Description: "ssl mode"},
+			}},
+		},
+	},
+	{
+		Name:        context.MySqlTarget,
+		Kind:        TargetConnector,
+		Description: "replace events into a Mysql table, columns are created on demand",
+		Specs: []Field{
+			{Name: "database", Kind: KindString, Required: true, Example: "draethos", Description: "database name"},
+			{Name: "table", Kind: KindString, Required: true, Example: "events", Description: "table name, accepts %{topic}"},
+			{Name: "keyColumnName", Kind: KindString, Default: "id", Description: "primary key column"},
+			topicFieldField(),
+			batchSizeField(),
+			flushField(),
+			{Name: "configurations", Kind: KindObject, Required: true, Description: "connection configurations", Fields: []Field{
+				{Name: "host", Kind: KindString, Required: true, Example: "127.0.0.1", Description: "database host"},
+				{Name: "port", Kind: KindInteger, Minimum: Min(1), Maximum: Max(65535), Default: 3306, Description: "database port"},
+				{Name: "user", Kind: KindString, Required: true, Example: "draethos", Description: "database user"},
+				{Name: "password", Kind: KindString, Required: true, Example: "${DATABASE_PASSWORD}", Description: "database password"},
+			}},
+		},
+	},
+}
+
+var codecs = []Connector{
+	{Name: context.JsonCodec, Kind: CodecConnector, Description: "json documents"},
+	{Name: context.YamlCodec, Kind: CodecConnector, Description: "yaml documents"},
+	{Name: context.MsgpackCodec, Kind: CodecConnector, Description: "MessagePack maps, keeping integers, binary and timestamps"},
+	{Name: context.CborCodec, Kind: CodecConnector, Description: "CBOR maps, keeping integers, binary and timestamps"},
+	{
+		Name:        context.XmlCodec,
+		Kind:        CodecConnector,
+		Description: "xml documents",
+		Specs: []Field{
+			{Name: "root", Kind: KindString, Default: "root", Description: "root element name of the documents written"},
+			{Name: "attribute.prefix", Kind: KindString, Default: "@", Description: "prefix of the keys holding attributes"},
+			{Name: "text.key", Kind: KindString, Default: "#text", Description: "key holding the text of elements with attributes or children"},
+			{Name: "namespace", Kind: KindString, Description: "default namespace of the documents written"},
+			{Name: "namespaces", Kind: KindObject, AllowUnknown: true, Example: map[string]interface{}{"soap": "http://schemas.xmlsoap.org/soap/envelope/"}, Description: "namespace uri of each prefix, elements of these namespaces are named prefix:name"},
+		},
+	},
+	{
+		Name:        context.AvroCodec,
+		Kind:        CodecConnector,
+		Description: "avro records in the schema registry wire format",
+		Specs: []Field{
+			{Name: "registry.url", Kind: KindString, Required: true, Example: "http://localhost:8081", Description: "schema registry url"},
+			{Name: "registry.username", Kind: KindString, Description: "schema registry basic auth user"},
+			{Name: "registry.password", Kind: KindString, Description: "schema registry basic auth password"},
+			{Name: "registry.timeout", Kind: KindDuration, Default: "10s", Description: "schema registry request timeout"},
+			{Name: "subject", Kind: KindString, Example: "orders-value", Description: "subject events are serialized with, required by targets"},
+			{Name: "schema", Kind: KindString, Description: "writer schema, the latest version of the subject is used when not set"},
+			{Name: "schema.file", Kind: KindString, Description: "file the writer schema is read from"},
+			{Name: "auto.register", Kind: KindBoolean, Default: false, Description: "register the writer schema under the subject"},
+		},
+	},
+	{
+		Name:        context.CsvCodec,
+		Kind:        CodecConnector,
+		Description: "comma separated values, one event per line",
+		Specs:       delimitedCodecSpecs(","),
+	},
+	{
+		Name:        context.TsvCodec,
+		Kind:        CodecConnector,
+		Description: "tab separated values, one event per line",
+		Specs:       delimitedCodecSpecs("\t"),
+	},
+	{
+		Name:        context.ProtobufCodec,
+		Kind:        CodecConnector,
+		Description: "binary protobuf messages described by a descriptor set",
+		Specs: []Field{
+			{Name: "descriptor.file", Kind: KindString, Required: true, Example: "./schemas/orders.pb", Description: "FileDescriptorSet generated by protoc --descriptor_set_out --include_imports"},
+			{Name: "message", Kind: KindString, Required: true, Example: "shop.v1.Order", Description: "fully qualified message name"},
+			{Name: "use.proto.names", Kind: KindBoolean, Default: false, Description: "name fields as in the .proto file instead of lowerCamelCase"},
+			{Name: "emit.defaults", Kind: KindBoolean, Default: false, Description: "decode fields holding their default value"},
+			{Name: "discard.unknown", Kind: KindBoolean, Default: false, Description: "drop event fields missing from the message instead of failing"},
+		},
+	},
+}
+
+var encodings = []Connector{
+	{Name: context.GzipEncoding, Kind: EncodingConnector, Description: "gzip compression"},
+	{Name: context.ZstdEncoding, Kind: EncodingConnector, Description: "zstd compression"},
+	{Name: context.SnappyEncoding, Kind: EncodingConnector, Description: "snappy compression, a single block or the framing format"},
+	{Name: context.Lz4Encoding, Kind: EncodingConnector, Description: "lz4 frame compression"},
+	{Name: context.Base64Encoding, Kind: EncodingConnector, Description: "base64 text, written with the standard alphabet"},
+}
+
+func delimitedCodecSpecs(delimiter string) []Field {
+	return []Field{
+		{Name: "columns", Kind: KindList, Example: []string{"id", "amount", "created_at"}, Description: "column names, in the order values are read and written, required unless header is enabled"},
+		{Name: "header", Kind: KindBoolean, Default: false, Description: "payloads start with a header line and files written by targets start with the columns"},
+		{Name: "delimiter", Kind: KindString, Default: delimiter, Description: "single character separating values"},
+		{Name: "quote", Kind: KindString, Default: "minimal", Enum: []string{"minimal", "all"}, Description: "quote only the values that need it or every value"},
+	}
+}
+
+// CodecConfigurationsKey holds the configurations of the codec selected by the
+// sibling "codec" key.
+const CodecConfigurationsKey = "codecConfigurations"
+
+func codecField() Field {
+	return Field{Name: "codec", Kind: KindCodec, Default: context.JsonCodec, Enum: names(codecs), Description: "codec used to serialize events, optionally preceded by encodings applied in reverse order"}
+}
+
+func codecConfigurationsField() Field {
+	return Field{Name: CodecConfigurationsKey, Kind: KindObject, AllowUnknown: true, Description: "codec configurations, see draethos connectors <codec>"}
+}
+
+// CodecConfigurations describes the configurations accepted by the codec ending
+// the chain, codecs without configurations accept none.
+func CodecConfigurations(codec string) Field {
+	connector, _ := Lookup(CodecConnector, specs.Codec(codec).Format())
+	return Field{Name: CodecConfigurationsKey, Kind: KindObject, Fields: connector.Specs}
+}
+
+func batchSizeField() Field {
+	return Field{Name: "batchSize", Kind: KindInteger, Minimum: Min(0), Description: "events buffered before flushing"}
+}
+
+func topicFieldField() Field {
+	return Field{Name: "topicField", Kind: KindString, Default: "topic", Description: "event field %{topic} is read from, the topicField of the kafka source"}
+}
+
+func bufferSizeField() Field {
+	return Field{Name: "bufferSize", Kind: KindInteger, Minimum: Min(0), Description: "bytes buffered before flushing, takes precedence over batchSize"}
+}
+
+func flushField() Field {
+	return Field{Name: "flushInMilliseconds", Kind: KindInteger, Minimum: Min(0), Description: "reserved, not used by the target yet"}
+}
+
+// Document describes the whole pipeline file, connector specs are resolved from
+// the catalog through the "type" key of source, target and dlq.
+func Document() Field {
+	return Field{Kind: KindObject, Fields: []Field{
+		{Name: "stream", Kind: KindObject, Required: true, Fields: []Field{
+			{Name: "name", Kind: KindString, Description: "pipeline name logged on every line, defaults to the file name"},
+			{Name: "port", Kind: KindScalar, Default: "9000", Description: "http server port"},
+			{Name: "healthCheck", Kind: KindObject, Fields: []Field{
+				{Name: "endpoint", Kind: KindString, Default: "/health", Description: "liveness endpoint"},
+			}},
+			{Name: "metrics", Kind: KindObject, Fields: []Field{
+				{Name: "endpoint", Kind: KindString, Default: "/metrics", Description: "prometheus endpoint"},
+			}},
+			{Name: "logging", Kind: KindObject, Fields: []Field{
+				{Name: "level", Kind: KindString, Default: logging.LevelInfo, Enum: logging.Levels, Description: "minimum level logged"},
+				{Name: "format", Kind: KindString, Default: logging.FormatConsole, Enum: logging.Formats, Description: "log line encoding"},
+				{Name: "payload", Kind: KindString, Default: logging.PayloadOff, Enum: logging.Payloads, Description: "how event contents are logged at debug level"},
+				{Name: "sampling", Kind: KindObject, Description: "lines logged per second with the same level and message", Fields: []Field{
+					{Name: "initial", Kind: KindInteger, Minimum: Min(0), Description: "lines logged before sampling"},
+					{Name: "thereafter", Kind: KindInteger, Minimum: Min(0), Description: "log one of every thereafter lines once initial was reached, 0 drops them"},
+				}},
+				{Name: "fields", Kind: KindObject, AllowUnknown: true, Example: map[string]interface{}{"env": "prod"}, Description: "static fields added to every line"},
+			}},
+			{Name: "instance", Kind: KindObject, Required: true, Fields: []Field{
+				{Name: "source", Kind: KindConnector, Required: true, Connectors: SourceConnector, Fields: []Field{
+					{Name: "type", Kind: KindString, Required: true, Enum: ConnectorNames(SourceConnector), Description: "source connector"},
+					{Name: "codec", Kind: KindCodec, Default: context.JsonCodec, Enum: ConnectorNames(CodecConnector), Description: "codec used to deserialize events, optionally preceded by encodings applied in order"},
+					codecConfigurationsField(),
+					{Name: "specs", Kind: KindObject, AllowUnknown: true, Description: "source specs"},
+				}},
+				{Name: "target", Kind: KindConnector, Required: true, Connectors: TargetConnector, Fields: []Field{
+					{Name: "type", Kind: KindString, Required: true, Enum: ConnectorNames(TargetConnector), Description: "target connector"},
+					{Name: "specs", Kind: KindObject, AllowUnknown: true, Description: "target specs"},
+				}},
+				{Name: "dlq", Kind: KindConnector, Connectors: TargetConnector, Fields: []Field{
+					{Name: "type", Kind: KindString, Required: true, Enum: ConnectorNames(TargetConnector), Description: "dead letter target connector"},
+					{Name: "specs", Kind: KindObject, AllowUnknown: true, Description: "dead letter target specs"},
+				}},
+			}},
+		}},
+	}}
+}
+
+func Connectors(kind ConnectorKind) []Connector {
+	list := make([]Connector, 0)
+	for _, connector := range append(append(append([]Connector{}, connectors...), codecs...), encodings...) {
+		if connector.Kind == kind {
+			list = append(list, connector)
+		}
+	}
+
+	return list
+}
+
+func ConnectorNames(kind ConnectorKind) []string {
+	return names(Connectors(kind))
+}
+
+func names(connectors []Connector) []string {
+	list := make([]string, 0, len(connectors))
+	for _, connector := range connectors {
+		list = append(list, connector.Name)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
+
+func Lookup(kind ConnectorKind, name string) (Connector, bool) {
+	for _, connector := range Connectors(kind) {
+		if connector.Name == name {
+			return connector, true
+		}
+	}
+
+	return Connector{}, false
+}
@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"draethos.io.com/internal/context"
+	"draethos.io.com/pkg/streams/specs"
+	"gopkg.in/yaml.v3"
+)
+
+type Setting struct {
+	Path  string
+	Field Field
+}
+
+// Settings flattens the connector specs into the leaf keys a pipeline sets,
+// nested objects are joined by dots such as "specs.configurations.host" and
+// only listed themselves when they accept keys beyond the known ones.
+func (c Connector) Settings() []Setting {
+	if c.Kind == CodecConnector {
+		return settings(CodecConfigurationsKey, c.Specs)
+	}
+
+	return settings("specs", c.Specs)
+}
+
+func settings(prefix string, fields []Field) []Setting {
+	list := make([]Setting, 0)
+	for _, field := range fields {
+		path := fmt.Sprintf("%s.%s", prefix, field.Name)
+		if field.Kind == KindObject && len(field.Fields) > 0 {
+			if field.AllowUnknown {
+				list = append(list, Setting{Path: path, Field: field})
+			}
+			list = append(list, settings(path, field.Fields)...)
+			continue
+		}
+
+		list = append(list, Setting{Path: path, Field: field})
+	}
+
+	return list
+}
+
+// Example renders a pipeline snippet using the connector with its required
+// keys and the optional keys that carry an example value.
+func (c Connector) Example() (string, error) {
+	connector := &yaml.Node{Kind: yaml.MappingNode}
+	switch c.Kind {
+	case CodecConnector:
+		appendScalar(connector, "type", "kafka")
+		appendScalar(connector, "codec", c.Name)
+		if configurations := exampleNode(c.Specs); len(configurations.Content) > 0 {
+			connector.Content = append(connector.Content, scalarNode(CodecConfigurationsKey), configurations)
+		}
+	case EncodingConnector:
+		appendScalar(connector, "type", "kafka")
+		appendScalar(connector, "codec", c.Name+specs.CodecSeparator+context.JsonCodec)
+	default:
+		appendScalar(connector, "type", c.Name)
+		if specs := exampleNode(c.Specs); len(specs.Content) > 0 {
+			connector.Content = append(connector.Content, scalarNode("specs"), specs)
+		}
+	}
+
+	key := string(c.Kind)
+	if c.Kind == CodecConnector || c.Kind == EncodingConnector {
+		key = string(SourceConnector)
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalarNode(key), connector}}
+
+	var builder strings.Builder
+	encoder := yaml.NewEncoder(&builder)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return "", err
+	}
+
+	return builder.String(), encoder.Close()
+}
+
+func exampleNode(fields []Field) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range fields {
+		if field.Kind == KindObject && field.Example == nil {
+			if child := exampleNode(field.Fields); len(child.Content) > 0 && field.Required {
+				node.Content = append(node.Content, scalarNode(field.Name), child)
+			}
+			continue
+		}
+
+		if !field.Required && field.Example == nil {
+			continue
+		}
+
+		node.Content = append(node.Content, scalarNode(field.Name), valueNode(field))
+	}
+
+	return node
+}
+
+func valueNode(field Field) *yaml.Node {
+	var value interface{}
+	switch {
+	case field.Example != nil:
+		value = field.Example
+	case field.Default != nil:
+		value = field.Default
+	case len(field.Enum) > 0:
+		value = field.Enum[0]
+	case field.Kind == KindInteger || field.Kind == KindNumber:
+		value = 0
+	case field.Kind == KindBoolean:
+		value = false
+	default:
+		value = ""
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return scalarNode(fmt.Sprint(value))
+	}
+
+	return &node
+}
+
+func appendScalar(node *yaml.Node, key string, value string) {
+	node.Content = append(node.Content, scalarNode(key), scalarNode(value))
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShouldRenderValidExamples(t *testing.T) {
+	source, _ := Lookup(SourceConnector, "kafka")
+	target, _ := Lookup(TargetConnector, "pgsql")
+
+	for _, kind := range []ConnectorKind{SourceConnector, TargetConnector} {
+		for _, connector := range Connectors(kind) {
+			instance := []Connector{source, connector}
+			if kind == SourceConnector {
+				instance = []Connector{connector, target}
+			}
+
+			var builder strings.Builder
+			builder.WriteString("stream:\n  instance:\n")
+			for _, item := range instance {
+				example, err := item.Example()
+				if err != nil {
+					t.Fatalf("failed to render %s %s example: %v", item.Kind, item.Name, err)
+				}
+
+				for _, line := range strings.Split(strings.TrimRight(example, "\n"), "\n") {
+					builder.WriteString("    " + line + "\n")
+				}
+			}
+
+			if problems := Validate([]byte(builder.String())); len(problems) > 0 {
+				t.Errorf("%s %s example is invalid:\n%s", connector.Kind, connector.Name, problems.Error())
+			}
+		}
+	}
+}
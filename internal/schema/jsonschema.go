@@ -0,0 +1,189 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	JsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+	JsonSchemaId    = "https://draethos.io.com/schemas/pipeline.json"
+
+	IncludeKey  = "include"
+	ProfilesKey = "profiles"
+
+	durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+)
+
+// JsonSchema renders the pipeline document as a JSON Schema, connector specs
+// are published as definitions selected through the "type" key.
+func JsonSchema() ([]byte, error) {
+	definitions := make(map[string]interface{})
+	for _, kind := range []ConnectorKind{SourceConnector, TargetConnector} {
+		for _, connector := range Connectors(kind) {
+			definitions[definitionName(connector)] = withDescription(fieldSchema(connector.Object()), connector.Description)
+		}
+	}
+
+	document := fieldSchema(Document())
+	properties := document["properties"].(map[string]interface{})
+	properties[IncludeKey] = map[string]interface{}{
+		"description": "files deep-merged below this one, relative to this file",
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+	properties[ProfilesKey] = map[string]interface{}{
+		"description":          "overlays deep-merged on top of this file when selected with --profile",
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "object"},
+	}
+
+	document["$schema"] = JsonSchemaDraft
+	document["$id"] = JsonSchemaId
+	document["title"] = "draethos pipeline"
+	document["definitions"] = definitions
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+func fieldSchema(field Field) map[string]interface{} {
+	schema := make(map[string]interface{})
+
+	switch field.Kind {
+	case KindString, KindInteger, KindNumber, KindBoolean:
+		schema["type"] = string(field.Kind)
+	case KindScalar:
+		schema["type"] = []string{"string", "integer", "number", "boolean"}
+	case KindDuration:
+		schema["oneOf"] = []interface{}{
+			map[string]interface{}{"type": "integer", "minimum": 0},
+			map[string]interface{}{"type": "string", "pattern": durationPattern},
+		}
+	case KindList:
+		schema["oneOf"] = []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": []string{"string", "integer", "number", "boolean"}}},
+		}
+	case KindCodec:
+		schema["oneOf"] = codecChainSchemas(field)
+	case KindObject, KindConnector:
+		schema["type"] = "object"
+
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		for _, child := range field.Fields {
+			properties[child.Name] = fieldSchema(child)
+			if child.Required {
+				required = append(required, child.Name)
+			}
+		}
+
+		if len(properties) > 0 {
+			schema["properties"] = properties
+		}
+
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+
+		schema["additionalProperties"] = field.AllowUnknown && field.Kind != KindConnector
+	}
+
+	if field.Kind == KindConnector {
+		schema["allOf"] = connectorConditions(field)
+	}
+
+	if field.Description != "" {
+		schema["description"] = field.Description
+	}
+
+	if len(field.Enum) > 0 && field.Kind != KindCodec {
+		schema["enum"] = field.Enum
+	}
+
+	if field.Default != nil {
+		schema["default"] = field.Default
+	}
+
+	if field.Example != nil {
+		schema["examples"] = []interface{}{field.Example}
+	}
+
+	if field.Minimum != nil && field.Kind != KindDuration {
+		schema["minimum"] = *field.Minimum
+	}
+
+	if field.Maximum != nil && field.Kind != KindDuration {
+		schema["maximum"] = *field.Maximum
+	}
+
+	return schema
+}
+
+// codecChainSchemas accepts a codec written alone, as a chain ending with it
+// or as a list of names.
+func codecChainSchemas(field Field) []interface{} {
+	codecs, encodings := quoteNames(field.Enum), quoteNames(ConnectorNames(EncodingConnector))
+	pattern := fmt.Sprintf(`^\s*((%s)\s*\|\s*)*(%s)\s*$`, strings.Join(encodings, "|"), strings.Join(codecs, "|"))
+
+	return []interface{}{
+		map[string]interface{}{"type": "string", "pattern": pattern},
+		map[string]interface{}{"type": "array", "minItems": 1, "items": map[string]interface{}{
+			"type": "string",
+			"enum": append(append([]string{}, ConnectorNames(EncodingConnector)...), field.Enum...),
+		}},
+	}
+}
+
+func quoteNames(names []string) []string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+
+	return quoted
+}
+
+func connectorConditions(field Field) []interface{} {
+	conditions := make([]interface{}, 0)
+	for _, connector := range Connectors(field.Connectors) {
+		then := map[string]interface{}{
+			"properties": map[string]interface{}{
+				"specs": map[string]interface{}{"$ref": fmt.Sprintf("#/definitions/%s", definitionName(connector))},
+			},
+		}
+
+		for _, spec := range connector.Specs {
+			if spec.Required {
+				then["required"] = []string{"specs"}
+				break
+			}
+		}
+
+		conditions = append(conditions, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"type": map[string]interface{}{"const": connector.Name}},
+				"required":   []string{"type"},
+			},
+			"then": then,
+		})
+	}
+
+	return conditions
+}
+
+func definitionName(connector Connector) string {
+	return fmt.Sprintf("%s.%s", connector.Kind, connector.Name)
+}
+
+func withDescription(schema map[string]interface{}, description string) map[string]interface{} {
+	if description != "" {
+		schema["description"] = description
+	}
+
+	return schema
+}
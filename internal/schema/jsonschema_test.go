@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestShouldExportConnectorDefinitions(t *testing.T) {
+	content, err := JsonSchema()
+	if err != nil {
+		t.Fatalf("failed to render schema: %v", err)
+	}
+
+	var document struct {
+		Definitions map[string]struct {
+			Required   []string               `json:"required"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"definitions"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+
+	if err = json.Unmarshal(content, &document); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+
+	for _, kind := range []ConnectorKind{SourceConnector, TargetConnector} {
+		for _, connector := range Connectors(kind) {
+			if _, ok := document.Definitions[definitionName(connector)]; !ok {
+				t.Errorf("missing definition for %s", definitionName(connector))
+			}
+		}
+	}
+
+	pgsql := document.Definitions["target.pgsql"]
+	if _, ok := pgsql.Properties["configurations"]; !ok || len(pgsql.Required) == 0 {
+		t.Errorf("unexpected pgsql definition: %+v", pgsql)
+	}
+
+	for _, key := range []string{"stream", IncludeKey, ProfilesKey} {
+		if _, ok := document.Properties[key]; !ok {
+			t.Errorf("missing root property %s", key)
+		}
+	}
+}
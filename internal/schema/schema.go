@@ -0,0 +1,89 @@
+package schema
+
+const (
+	KindString  Kind = "string"
+	KindInteger Kind = "integer"
+	KindNumber  Kind = "number"
+	KindBoolean Kind = "boolean"
+	KindScalar  Kind = "scalar"
+
+	// KindDuration accepts strings such as "15s" or integers as seconds.
+	KindDuration Kind = "duration"
+
+	// KindList accepts a list of scalars or a comma separated string.
+	KindList   Kind = "list"
+	KindObject Kind = "object"
+
+	// KindCodec accepts a codec optionally preceded by the encodings payloads
+	// go through, as a chain such as "gzip|json" or as a list.
+	KindCodec Kind = "codec"
+
+	// KindConnector is an object whose "type" key selects the connector used to
+	// validate its "specs" key.
+	KindConnector Kind = "connector"
+)
+
+const (
+	SourceConnector ConnectorKind = "source"
+	TargetConnector ConnectorKind = "target"
+	CodecConnector  ConnectorKind = "codec"
+
+	EncodingConnector ConnectorKind = "encoding"
+)
+
+type Kind string
+
+type ConnectorKind string
+
+type Field struct {
+	Name         string
+	Kind         Kind
+	Required     bool
+	Default      interface{}
+	Example      interface{}
+	Description  string
+	Enum         []string
+	Minimum      *float64
+	Maximum      *float64
+	Fields       []Field
+	AllowUnknown bool
+	Connectors   ConnectorKind
+}
+
+type Connector struct {
+	Name        string
+	Kind        ConnectorKind
+	Description string
+	Specs       []Field
+}
+
+func (f Field) Field(name string) (Field, bool) {
+	for _, field := range f.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+
+	return Field{}, false
+}
+
+func (f Field) FieldNames() []string {
+	names := make([]string, 0, len(f.Fields))
+	for _, field := range f.Fields {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+func (c Connector) Object() Field {
+	return Field{Name: "specs", Kind: KindObject, Fields: c.Specs}
+}
+
+func Min(minimum float64) *float64 {
+	return &minimum
+}
+
+func Max(maximum float64) *float64 {
+	return &maximum
+}
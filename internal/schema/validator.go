@@ -0,0 +1,488 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"draethos.io.com/internal/context"
+	"draethos.io.com/pkg/streams/specs"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	tagNull   = "!!null"
+	tagString = "!!str"
+	tagInt    = "!!int"
+	tagFloat  = "!!float"
+	tagBool   = "!!bool"
+)
+
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	}
+
+	return fmt.Sprintf("line %d: %s: %s", p.Line, p.Path, p.Message)
+}
+
+type Problems []Problem
+
+func (p Problems) Error() string {
+	lines := make([]string, 0, len(p))
+	for _, problem := range p {
+		lines = append(lines, problem.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type validator struct {
+	problems Problems
+	origins  map[*yaml.Node]string
+}
+
+// Validate parses the content and checks it against the pipeline document,
+// every problem found is returned ordered by line.
+func Validate(content []byte) Problems {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return Problems{{Line: parseErrorLine(err.Error()), Message: err.Error()}}
+	}
+
+	return ValidateNode(&document, nil)
+}
+
+// ValidateNode checks an already parsed document, origins maps nodes to the
+// file they were read from when the document is composed of several files.
+func ValidateNode(document *yaml.Node, origins map[*yaml.Node]string) Problems {
+	v := &validator{origins: origins}
+
+	if document.Kind == yaml.DocumentNode && len(document.Content) > 0 {
+		document = document.Content[0]
+	}
+
+	if document.Kind == 0 || document.Tag == tagNull {
+		return Problems{{Line: 1, Message: "document is empty"}}
+	}
+
+	v.object("", Document(), document)
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		if v.problems[i].File != v.problems[j].File {
+			return v.problems[i].File < v.problems[j].File
+		}
+
+		return v.problems[i].Line < v.problems[j].Line
+	})
+
+	return v.problems
+}
+
+func (v *validator) report(node *yaml.Node, path string, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{
+		File:    v.origins[node],
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) field(path string, field Field, node *yaml.Node) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == tagNull {
+		if field.Required {
+			v.report(node, path, "value not defined")
+		}
+		return
+	}
+
+	switch field.Kind {
+	case KindObject:
+		v.object(path, field, node)
+	case KindConnector:
+		v.connector(path, field, node)
+	case KindList:
+		v.list(path, field, node)
+	case KindCodec:
+		v.codec(path, field, node)
+	default:
+		v.scalar(path, field, node)
+	}
+}
+
+func (v *validator) object(path string, field Field, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		v.report(node, path, "expected object, got %s", describe(node))
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		childPath := join(path, key.Value)
+		seen[key.Value] = true
+
+		child, ok := field.Field(key.Value)
+		if !ok {
+			if !field.AllowUnknown {
+				v.report(key, childPath, "unknown key%s", suggest(key.Value, field.FieldNames()))
+			}
+			continue
+		}
+
+		if child.Name == CodecConfigurationsKey {
+			v.codecConfigurations(childPath, key, value, codecOf(node))
+			continue
+		}
+
+		v.field(childPath, child, value)
+	}
+
+	for _, child := range field.Fields {
+		if child.Required && !seen[child.Name] {
+			v.report(node, join(path, child.Name), "required key not defined")
+		}
+	}
+
+	if _, ok := field.Field(CodecConfigurationsKey); ok && !seen[CodecConfigurationsKey] {
+		codec := codecOf(node)
+		for _, child := range CodecConfigurations(codec).Fields {
+			if child.Required {
+				v.report(node, join(path, CodecConfigurationsKey, child.Name), "required key not defined for codec %s", codec)
+			}
+		}
+	}
+}
+
+// codecConfigurations validates the configurations against the codec selected
+// next to them.
+func (v *validator) codecConfigurations(path string, key *yaml.Node, node *yaml.Node, codec string) {
+	field := CodecConfigurations(codec)
+	if len(field.Fields) == 0 && node.Kind == yaml.MappingNode && len(node.Content) > 0 {
+		v.report(key, path, "codec %s takes no configurations", codec)
+		return
+	}
+
+	v.field(path, field, node)
+}
+
+// codecOf names the codec ending the chain selected by the "codec" key.
+func codecOf(node *yaml.Node) string {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "codec" {
+			continue
+		}
+
+		value := node.Content[i+1]
+		if value.Kind == yaml.SequenceNode && len(value.Content) > 0 {
+			value = value.Content[len(value.Content)-1]
+		}
+
+		if codec := specs.Codec(value.Value).Format(); codec != "" {
+			return codec
+		}
+	}
+
+	return context.JsonCodec
+}
+
+// codec checks every name of the chain is an encoding but the last one, which
+// must be one of the codecs of the field.
+func (v *validator) codec(path string, field Field, node *yaml.Node) {
+	type link struct {
+		node *yaml.Node
+		path string
+		name string
+	}
+
+	chain := make([]link, 0)
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag != tagString {
+			v.report(node, path, "expected codec, got %s %q", describe(node), node.Value)
+			return
+		}
+
+		for _, name := range specs.Codec(node.Value).Chain() {
+			chain = append(chain, link{node: node, path: path, name: name})
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if item.Kind != yaml.ScalarNode || item.Tag != tagString {
+				v.report(item, itemPath, "expected string, got %s", describe(item))
+				return
+			}
+			chain = append(chain, link{node: item, path: itemPath, name: strings.TrimSpace(item.Value)})
+		}
+	default:
+		v.report(node, path, "expected codec, got %s", describe(node))
+		return
+	}
+
+	if len(chain) == 0 {
+		v.report(node, path, "codec not defined")
+		return
+	}
+
+	encodings := ConnectorNames(EncodingConnector)
+	for _, encoding := range chain[:len(chain)-1] {
+		if !contains(encodings, encoding.name) {
+			v.report(encoding.node, encoding.path, "invalid encoding %q, expected one of: %s", encoding.name, strings.Join(encodings, ", "))
+		}
+	}
+
+	last := chain[len(chain)-1]
+	if contains(encodings, last.name) {
+		v.report(last.node, last.path, "chain ends with encoding %q, expected a codec such as %s", last.name, context.JsonCodec)
+		return
+	}
+
+	if len(field.Enum) > 0 && !contains(field.Enum, last.name) {
+		v.report(last.node, last.path, "invalid value %q, expected one of: %s", last.name, strings.Join(field.Enum, ", "))
+	}
+}
+
+func (v *validator) connector(path string, field Field, node *yaml.Node) {
+	v.object(path, field, node)
+
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	name := ""
+	var specs *yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch node.Content[i].Value {
+		case "type":
+			name = node.Content[i+1].Value
+		case "specs":
+			specs = node.Content[i+1]
+		}
+	}
+
+	connector, ok := Lookup(field.Connectors, name)
+	if !ok {
+		return
+	}
+
+	object := connector.Object()
+	if specs == nil {
+		for _, child := range object.Fields {
+			if child.Required {
+				v.report(node, join(path, "specs", child.Name), "required key not defined for %s %s", field.Connectors, name)
+			}
+		}
+		return
+	}
+
+	if specs.Kind != yaml.MappingNode {
+		return
+	}
+
+	v.field(join(path, "specs"), object, specs)
+}
+
+func (v *validator) list(path string, field Field, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag != tagString {
+			v.report(node, path, "expected list or comma separated string, got %s %q", describe(node), node.Value)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if item.Kind != yaml.ScalarNode || item.Tag == tagNull {
+				v.report(item, fmt.Sprintf("%s[%d]", path, i), "expected scalar, got %s", describe(item))
+			}
+		}
+	default:
+		v.report(node, path, "expected list, got %s", describe(node))
+	}
+}
+
+func (v *validator) scalar(path string, field Field, node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode {
+		v.report(node, path, "expected %s, got %s", field.Kind, describe(node))
+		return
+	}
+
+	switch field.Kind {
+	case KindString:
+		if node.Tag != tagString {
+			v.report(node, path, "expected string, got %s %q, quote the value", describe(node), node.Value)
+			return
+		}
+	case KindInteger:
+		if node.Tag != tagInt {
+			v.report(node, path, "expected integer, got %s %q", describe(node), node.Value)
+			return
+		}
+	case KindNumber:
+		if node.Tag != tagInt && node.Tag != tagFloat {
+			v.report(node, path, "expected number, got %s %q", describe(node), node.Value)
+			return
+		}
+	case KindDuration:
+		if node.Tag == tagString {
+			if _, err := time.ParseDuration(node.Value); err != nil {
+				v.report(node, path, "expected duration such as \"15s\", got %q", node.Value)
+			}
+			return
+		}
+
+		if node.Tag != tagInt {
+			v.report(node, path, "expected duration, got %s %q", describe(node), node.Value)
+			return
+		}
+	case KindBoolean:
+		if node.Tag != tagBool {
+			v.report(node, path, "expected boolean, got %s %q", describe(node), node.Value)
+			return
+		}
+	}
+
+	if len(field.Enum) > 0 && !contains(field.Enum, node.Value) {
+		v.report(node, path, "invalid value %q, expected one of: %s", node.Value, strings.Join(field.Enum, ", "))
+	}
+
+	if field.Minimum == nil && field.Maximum == nil {
+		return
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(node.Value, "_", ""), 64)
+	if err != nil {
+		if integer, err := strconv.ParseInt(strings.ReplaceAll(node.Value, "_", ""), 0, 64); err == nil {
+			value = float64(integer)
+		} else {
+			return
+		}
+	}
+
+	if field.Minimum != nil && value < *field.Minimum {
+		v.report(node, path, "value %s is lower than minimum %v", node.Value, *field.Minimum)
+	}
+
+	if field.Maximum != nil && value > *field.Maximum {
+		v.report(node, path, "value %s is greater than maximum %v", node.Value, *field.Maximum)
+	}
+}
+
+// Parse converts an answer typed by the user into a scalar node tagged after
+// the field kind, rejecting values the validator would report.
+func (f Field) Parse(value string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: tagString, Value: value}
+
+	switch f.Kind {
+	case KindInteger, KindDuration, KindScalar:
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			node.Tag = tagInt
+		}
+	case KindNumber:
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			node.Tag = tagFloat
+		}
+	case KindBoolean:
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			node.Tag, node.Value = tagBool, strconv.FormatBool(parsed)
+		}
+	case KindObject, KindConnector:
+		return nil, fmt.Errorf("expected %s", f.Kind)
+	}
+
+	v := &validator{}
+	v.field(f.Name, f, node)
+	if len(v.problems) > 0 {
+		return nil, fmt.Errorf("%s", v.problems[0].Message)
+	}
+
+	return node, nil
+}
+
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "list"
+	}
+
+	switch node.Tag {
+	case tagString:
+		return "string"
+	case tagInt:
+		return "integer"
+	case tagFloat:
+		return "number"
+	case tagBool:
+		return "boolean"
+	case tagNull:
+		return "null"
+	}
+
+	return strings.TrimPrefix(node.Tag, "!!")
+}
+
+func suggest(key string, candidates []string) string {
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, key) {
+			return fmt.Sprintf(", did you mean %q?", candidate)
+		}
+	}
+
+	return fmt.Sprintf(", expected one of: %s", strings.Join(candidates, ", "))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func join(path ...string) string {
+	parts := make([]string, 0, len(path))
+	for _, p := range path {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func parseErrorLine(message string) int {
+	index := strings.Index(message, "line ")
+	if index < 0 {
+		return 0
+	}
+
+	digits := strings.FieldsFunc(message[index+5:], func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+
+	if len(digits) == 0 {
+		return 0
+	}
+
+	line, _ := strconv.Atoi(digits[0])
+
+	return line
+}
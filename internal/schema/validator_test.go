@@ -0,0 +1,191 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShouldAcceptValidPipeline(t *testing.T) {
+	problems := Validate([]byte(ValidPipelineTest))
+	if len(problems) > 0 {
+		t.Errorf("unexpected problems:\n%s", problems.Error())
+	}
+}
+
+func TestShouldReportEveryProblemWithLine(t *testing.T) {
+	problems := Validate([]byte(InvalidPipelineTest))
+
+	expected := []string{
+		"line 8: stream.instance.source.specs.timeoutMs: value -5 is lower than minimum 0",
+		"line 10: stream.instance.source.specs.configurations.group.id: required key not defined",
+		"line 14: stream.instance.target.specs.table: required key not defined",
+		"line 15: stream.instance.target.specs.batchsize: unknown key, did you mean \"batchSize\"?",
+		"line 17: stream.instance.target.specs.configurations.host: expected string",
+		"line 17: stream.instance.target.specs.configurations.user: required key not defined",
+		"line 17: stream.instance.target.specs.configurations.password: required key not defined",
+		"line 18: stream.instance.target.specs.configurations.port: expected integer",
+		"line 19: stream.instance.target.specs.configurations.sslmode: invalid value \"nope\"",
+	}
+
+	if len(problems) != len(expected) {
+		t.Errorf("expected %d problems, got %d:\n%s", len(expected), len(problems), problems.Error())
+	}
+
+	for _, message := range expected {
+		if !strings.Contains(problems.Error(), message) {
+			t.Errorf("problem not reported: %s\n%s", message, problems.Error())
+		}
+	}
+}
+
+func TestShouldValidateCodecConfigurations(t *testing.T) {
+	problems := Validate([]byte(CodecPipelineTest))
+
+	expected := []string{
+		"line 7: stream.instance.source.codecConfigurations.registry.url: required key not defined",
+		"line 8: stream.instance.source.codecConfigurations.auto.register: expected boolean",
+		"line 18: stream.instance.target.specs.codecConfigurations: codec json takes no configurations",
+	}
+
+	if len(problems) != len(expected) {
+		t.Errorf("expected %d problems, got %d:\n%s", len(expected), len(problems), problems.Error())
+	}
+
+	for _, message := range expected {
+		if !strings.Contains(problems.Error(), message) {
+			t.Errorf("problem not reported: %s", message)
+		}
+	}
+}
+
+func TestShouldValidateCodecChains(t *testing.T) {
+	problems := Validate([]byte(CodecChainPipelineTest))
+
+	expected := []string{
+		"line 18: stream.instance.target.specs.codec[0]: invalid encoding \"brotli\"",
+		"line 26: stream.instance.dlq.specs.codec: invalid encoding \"json\"",
+		"line 26: stream.instance.dlq.specs.codec: chain ends with encoding \"gzip\"",
+	}
+
+	if len(problems) != len(expected) {
+		t.Errorf("expected %d problems, got %d:\n%s", len(expected), len(problems), problems.Error())
+	}
+
+	for _, message := range expected {
+		if !strings.Contains(problems.Error(), message) {
+			t.Errorf("problem not reported: %s", message)
+		}
+	}
+}
+
+func TestShouldReportSyntaxError(t *testing.T) {
+	problems := Validate([]byte("stream:\n  port: 9999\n   metrics: {\n"))
+	if len(problems) != 1 || problems[0].Line == 0 {
+		t.Errorf("expected syntax error with line, got: %v", problems)
+	}
+}
+
+const (
+	ValidPipelineTest = `stream:
+  port: 9999
+  healthCheck:
+    endpoint: /health
+  instance:
+    source:
+      type: kafka
+      codec: json
+      specs:
+        topic: draethos.inbound
+        timeoutMs: 1000
+        configurations:
+          group.id: 'draethos'
+          bootstrap.servers: 'localhost:9093'
+          enable.auto.commit: false
+    target:
+      type: pgsql
+      specs:
+        database: draethos
+        table: events
+        batchSize: 100
+        configurations:
+          host: '127.0.0.1'
+          port: 5432
+          user: 'root'
+          password: 'root'
+          sslmode: 'disable'
+`
+
+	InvalidPipelineTest = `stream:
+  port: 9999
+  instance:
+    source:
+      type: kafka
+      specs:
+        topic: x
+        timeoutMs: -5
+        configurations:
+          bootstrap.servers: localhost
+    target:
+      type: pgsql
+      specs:
+        database: db
+        batchsize: 10
+        configurations:
+          host: 1
+          port: "abc"
+          sslmode: nope
+`
+
+	CodecPipelineTest = `stream:
+  instance:
+    source:
+      type: kafka
+      codec: avro
+      codecConfigurations:
+        subject: orders-value
+        auto.register: 'yes'
+      specs:
+        topic: orders
+        configurations:
+          group.id: 'draethos'
+          bootstrap.servers: 'localhost:9093'
+    target:
+      type: kafka
+      specs:
+        topic: orders.json
+        codecConfigurations:
+          subject: orders-value
+        configurations:
+          bootstrap.servers: 'localhost:9093'
+`
+
+	CodecChainPipelineTest = `stream:
+  instance:
+    source:
+      type: kafka
+      codec: gzip|base64|csv
+      codecConfigurations:
+        header: true
+      specs:
+        topic: orders
+        configurations:
+          group.id: 'draethos'
+          bootstrap.servers: 'localhost:9093'
+    target:
+      type: kafka
+      specs:
+        topic: orders.json
+        codec:
+          - brotli
+          - json
+        configurations:
+          bootstrap.servers: 'localhost:9093'
+    dlq:
+      type: kafka
+      specs:
+        topic: orders.dlq
+        codec: json|gzip
+        configurations:
+          bootstrap.servers: 'localhost:9093'
+`
+)
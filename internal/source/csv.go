@@ -2,38 +2,139 @@ package source
 
 import (
 	"crypto/md5"
+	"draethos.io.com/internal/dlq"
 	interfaces2 "draethos.io.com/internal/interfaces"
-	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"draethos.io.com/pkg/streams/specs"
 	"go.uber.org/zap"
 )
 
+const (
+	CsvTypeString  = "string"
+	CsvTypeInteger = "integer"
+	CsvTypeNumber  = "number"
+	CsvTypeBoolean = "boolean"
+	CsvTypeJson    = "json"
+)
+
+var (
+	csvTypes = []string{CsvTypeString, CsvTypeInteger, CsvTypeNumber, CsvTypeBoolean, CsvTypeJson}
+
+	csvNumberPattern = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+)
+
+const CsvInferRowsDefault = 100
+
 type csvSource struct {
 	sync.Mutex
-	sourceSpec specs.Source
-	target     interfaces2.TargetInterface
-	dlq        interfaces2.TargetInterface
-	codec      interfaces2.CodecInterface
+	sourceSpec     specs.Source
+	target         interfaces2.TargetInterface
+	dlq            interfaces2.TargetInterface
+	codec          interfaces2.CodecInterface
+	configurations csvSourceConfigurations
+	delimiter      rune
+	quote          rune
+	escape         rune
+	stop           chan struct{}
+	stopOnce       sync.Once
+}
+
+type csvSourceConfigurations struct {
+	Delimiter        string            `config:"delimiter"`
+	Quote            string            `config:"quote"`
+	Escape           string            `config:"escape"`
+	Comment          string            `config:"comment"`
+	HeaderRow        int               `config:"header.row"`
+	Columns          []string          `config:"columns"`
+	SkipRows         int               `config:"skip.rows"`
+	NullValues       []string          `config:"null.values"`
+	Trim             bool              `config:"trim"`
+	NormalizeColumns bool              `config:"normalize.columns"`
+	Types            map[string]string `config:"types"`
+	InferTypes       bool              `config:"infer.types"`
+	InferRows        int               `config:"infer.rows"`
+}
+
+// csvRow is a line read before the types of the columns are inferred.
+type csvRow struct {
+	line    int
+	records []string
 }
 
+// NewCsvSource reads the lines of a csv file, or of every csv file of a
+// directory, as events named after the header row or the columns informed.
 func NewCsvSource(sourceSpec specs.Source,
 	target interfaces2.TargetInterface,
 	dlq interfaces2.TargetInterface,
 	codec interfaces2.CodecInterface,
 ) (interfaces2.SourceInterface, error) {
+	configurations := csvSourceConfigurations{Delimiter: ",", Quote: `"`, HeaderRow: 1, NormalizeColumns: true, InferRows: CsvInferRowsDefault}
+	if err := specs.DecodeConfigurations(sourceSpec.SourceSpecs.Configurations, &configurations); err != nil {
+		return nil, errors.New(fmt.Sprintf("csv source: %s", err.Error()))
+	}
+
+	delimiter, err := csvCharacter("delimiter", configurations.Delimiter, false)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := csvCharacter("quote", configurations.Quote, true)
+	if err != nil {
+		return nil, err
+	}
+
+	escape, err := csvCharacter("escape", configurations.Escape, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if escape == 0 {
+		escape = quote
+	}
+
+	if delimiter == quote || delimiter == escape {
+		return nil, errors.New("csv source: delimiter must differ from quote and escape")
+	}
+
+	if configurations.HeaderRow < 0 || configurations.SkipRows < 0 {
+		return nil, errors.New("csv source: header.row and skip.rows must not be negative")
+	}
+
+	if configurations.InferRows < 1 {
+		return nil, errors.New("csv source: infer.rows must be at least 1")
+	}
+
+	if configurations.HeaderRow == 0 && len(configurations.Columns) == 0 {
+		return nil, errors.New("csv source: columns not defined, required when header.row is 0")
+	}
+
+	for column, kind := range configurations.Types {
+		if !csvContains(csvTypes, kind) {
+			return nil, errors.New(fmt.Sprintf("csv source: invalid type %s of column %s, expected one of: %s", kind, column, strings.Join(csvTypes, ", ")))
+		}
+	}
+
 	return &csvSource{
-		sourceSpec: sourceSpec,
-		target:     target,
-		dlq:        dlq,
-		codec:      codec,
+		sourceSpec:     sourceSpec,
+		target:         target,
+		dlq:            dlq,
+		codec:          codec,
+		configurations: configurations,
+		delimiter:      delimiter,
+		quote:          quote,
+		escape:         escape,
+		stop:           make(chan struct{}),
 	}, nil
 }
 
@@ -71,7 +172,11 @@ func (c *csvSource) processDir(path string) error {
 	}
 
 	for _, v := range files {
-		if !strings.HasSuffix(v, ".csv") {
+		if c.stopped() {
+			break
+		}
+
+		if !acceptsFile(v, ".csv") {
 			zap.S().Warnf("invalid file %s", v)
 			continue
 		}
@@ -90,65 +195,121 @@ func (c *csvSource) processFile(filename string) error {
 		return err
 	}
 
-	file, err := os.Open(filename)
-	if err != nil {
+	if err := readFile(filename, ".csv", c.processReader); err != nil {
 		zap.S().Errorf("failed to load csv file %s: %s", filename, err.Error())
 		return err
 	}
 
-	defer file.Close()
+	if err := dlq.Flush(c.dlq); err != nil {
+		return err
+	}
+
+	if err := c.target.Flush(); err != nil {
+		return errors.New(fmt.Sprintf("failed to flush event: %s", err.Error()))
+	}
+
+	return nil
+}
 
-	reader := csv.NewReader(file)
+// processReader sends the lines of a csv file, or of a file inside an
+// archive, to the target.
+func (c *csvSource) processReader(filename string, file io.Reader) error {
+	reader := newCsvReader(file, c.delimiter, c.quote, c.escape, c.configurations.Comment, c.configurations.Trim)
 
-	lines := 0
-	var columns []string
-	for {
+	rows := 0
+	columns := c.configurations.Columns
+
+	var inferred map[string]string
+	var pending []csvRow
+	for !c.stopped() {
 		records, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
 
 		if err != nil {
-			zap.S().Errorf("failed to read columns csv file %s: %s", filename, err.Error())
-			return err
+			return errors.New(fmt.Sprintf("failed to read %s: %s", filename, err.Error()))
 		}
 
-		if records == nil {
-			break
+		rows++
+		if rows < c.configurations.HeaderRow {
+			continue
 		}
 
-		if lines == 0 {
-			for _, v := range records {
-				columns = append(columns, strings.ToLower(strings.ReplaceAll(v, " ", "_")))
+		if rows == c.configurations.HeaderRow {
+			if len(c.configurations.Columns) == 0 {
+				columns = c.columns(records)
 			}
 
-			lines++
-
-			zap.S().Debugf("columns %s", records)
+			zap.S().Debugf("columns %s", columns)
 
 			continue
 		}
 
-		payload := make(map[string]interface{}, 0)
-		for k, v := range records {
-			payload[columns[k]] = v
+		if rows <= c.configurations.HeaderRow+c.configurations.SkipRows {
+			continue
 		}
 
-		key := fmt.Sprintf("'%x'", md5.Sum([]byte(strings.Join(records, ""))))
-		if err := c.target.Attach(key, payload); err != nil {
-			zap.S().Errorf("failed to attach content: %s", err.Error())
-			return err
+		if !c.configurations.InferTypes || inferred != nil {
+			if err := c.send(reader.line, columns, inferred, records); err != nil {
+				return err
+			}
+			continue
 		}
 
-		if !c.target.CanFlush() {
+		// types are inferred from the first rows, which are sent afterwards
+		pending = append(pending, csvRow{line: reader.line, records: records})
+		if len(pending) < c.configurations.InferRows {
 			continue
 		}
 
-		if err := c.target.Flush(); err != nil {
-			return errors.New(fmt.Sprintf("failed to flush event: %s", err.Error()))
+		inferred = c.inferTypes(columns, pending)
+		if err := c.sendRows(columns, inferred, pending); err != nil {
+			return err
+		}
+		pending = nil
+	}
+
+	if len(pending) > 0 && !c.stopped() {
+		return c.sendRows(columns, c.inferTypes(columns, pending), pending)
+	}
+
+	return nil
+}
+
+// sendRows sends the lines read while the types of the columns were inferred.
+func (c *csvSource) sendRows(columns []string, inferred map[string]string, rows []csvRow) error {
+	for _, row := range rows {
+		if err := c.send(row.line, columns, inferred, row.records); err != nil {
+			return err
 		}
 	}
 
+	return nil
+}
+
+// send converts the values of a line into an event and attaches it to the
+// target, lines that cannot be converted are sent to the dlq.
+func (c *csvSource) send(line int, columns []string, inferred map[string]string, records []string) error {
+	key := fmt.Sprintf("'%x'", md5.Sum([]byte(strings.Join(records, ""))))
+
+	payload, err := c.payload(columns, inferred, records)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("line %d: %s", line, err.Error()))
+		zap.S().Errorf("failed to decode content: %s", err.Error())
+		raw := []byte(strings.Join(records, string(c.delimiter)))
+		return dlq.Send(c.dlq, dlq.NewEnvelope(dlq.DecodeError, c.sourceSpec.Type, key, err).WithRaw(raw))
+	}
+
+	if err := c.target.Attach(key, payload); err != nil {
+		zap.S().Errorf("failed to attach content: %s", err.Error())
+		return dlq.Send(c.dlq, dlq.NewEnvelope(dlq.TargetError, c.sourceSpec.Type, key, err).WithPayload(payload))
+	}
+
+	if !c.target.CanFlush() {
+		return nil
+	}
+
 	if err := c.target.Flush(); err != nil {
 		return errors.New(fmt.Sprintf("failed to flush event: %s", err.Error()))
 	}
@@ -156,6 +317,100 @@ func (c *csvSource) processFile(filename string) error {
 	return nil
 }
 
+// inferTypes reads the type of every column without one from the rows, the
+// narrowest type fitting all of their values, integers and numbers mixed are
+// numbers. Empty and null values are left out.
+func (c *csvSource) inferTypes(columns []string, rows []csvRow) map[string]string {
+	inferred := make(map[string]string, len(columns))
+	for i, column := range columns {
+		if _, ok := c.configurations.Types[column]; ok {
+			continue
+		}
+
+		kind := ""
+		for _, row := range rows {
+			if i >= len(row.records) || row.records[i] == "" || csvContains(c.configurations.NullValues, row.records[i]) {
+				continue
+			}
+			kind = widenCsvType(kind, inferCsvType(row.records[i]))
+		}
+
+		if kind != "" && kind != CsvTypeString {
+			inferred[column] = kind
+		}
+	}
+
+	zap.S().Debugf("inferred types %v", inferred)
+
+	return inferred
+}
+
+// columns names the values after the header, lowercased with spaces replaced
+// by underscores unless normalize.columns is disabled.
+func (c *csvSource) columns(header []string) []string {
+	columns := make([]string, 0, len(header))
+	for _, v := range header {
+		if c.configurations.NormalizeColumns {
+			v = strings.ToLower(strings.ReplaceAll(v, " ", "_"))
+		}
+		columns = append(columns, v)
+	}
+
+	return columns
+}
+
+// payload converts the values of a line into an event, lines with fewer values
+// than columns leave the remaining columns out. Empty values of columns whose
+// type was inferred are null.
+func (c *csvSource) payload(columns []string, inferred map[string]string, records []string) (map[string]interface{}, error) {
+	if len(records) > len(columns) {
+		return nil, errors.New(fmt.Sprintf("expected at most %d values, got %d", len(columns), len(records)))
+	}
+
+	payload := make(map[string]interface{}, len(records))
+	for k, v := range records {
+		column := columns[k]
+		if csvContains(c.configurations.NullValues, v) {
+			payload[column] = nil
+			continue
+		}
+
+		kind, ok := c.configurations.Types[column]
+		if !ok {
+			if kind, ok = inferred[column]; ok && v == "" {
+				payload[column] = nil
+				continue
+			}
+		}
+
+		if !ok {
+			payload[column] = v
+			continue
+		}
+
+		value, err := csvValue(v, kind)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("column %s: invalid %s %q", column, kind, v))
+		}
+		payload[column] = value
+	}
+
+	return payload, nil
+}
+
+func (c *csvSource) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+func (c *csvSource) stopped() bool {
+	select {
+	case <-c.stop:
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *csvSource) filePathWalkDir(root string) ([]string, error) {
 	var files []string
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -167,3 +422,83 @@ func (c *csvSource) filePathWalkDir(root string) ([]string, error) {
 
 	return files, err
 }
+
+func csvValue(value string, kind string) (interface{}, error) {
+	switch kind {
+	case CsvTypeInteger:
+		return strconv.ParseInt(value, 10, 64)
+	case CsvTypeNumber:
+		return strconv.ParseFloat(value, 64)
+	case CsvTypeBoolean:
+		return strconv.ParseBool(value)
+	case CsvTypeJson:
+		var data interface{}
+		if err := json.Unmarshal([]byte(value), &data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	return value, nil
+}
+
+// inferCsvType names the type of a value, true and false are booleans and
+// integers with leading zeros such as zip codes are strings.
+func inferCsvType(value string) string {
+	switch strings.ToLower(value) {
+	case "true", "false":
+		return CsvTypeBoolean
+	}
+
+	if integer, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(integer, 10) == value {
+		return CsvTypeInteger
+	}
+
+	digits := strings.TrimPrefix(value, "-")
+	if !csvNumberPattern.MatchString(value) || (len(digits) > 1 && digits[0] == '0' && digits[1] != '.') {
+		return CsvTypeString
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return CsvTypeNumber
+	}
+
+	return CsvTypeString
+}
+
+// widenCsvType returns the type holding values of both types.
+func widenCsvType(current string, kind string) string {
+	switch {
+	case current == "" || current == kind:
+		return kind
+	case (current == CsvTypeInteger && kind == CsvTypeNumber) || (current == CsvTypeNumber && kind == CsvTypeInteger):
+		return CsvTypeNumber
+	}
+
+	return CsvTypeString
+}
+
+// csvCharacter reads a configuration holding a single character, empty
+// values are only accepted when optional.
+func csvCharacter(name string, value string, optional bool) (rune, error) {
+	if value == "" && optional {
+		return 0, nil
+	}
+
+	character, size := utf8.DecodeRuneInString(value)
+	if size == 0 || size != len(value) || character == '\r' || character == '\n' || character == utf8.RuneError {
+		return 0, errors.New(fmt.Sprintf("csv source: invalid %s %q, expected a single character", name, value))
+	}
+
+	return character, nil
+}
+
+func csvContains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
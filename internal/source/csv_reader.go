@@ -0,0 +1,111 @@
+package source
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// csvReader splits delimited lines into values. Unlike encoding/csv the quote
+// and escape characters are configurable, an escape equal to the quote means
+// quotes are escaped by doubling them.
+type csvReader struct {
+	reader    *bufio.Reader
+	delimiter rune
+	quote     rune
+	escape    rune
+	comment   string
+	trim      bool
+	line      int
+}
+
+func newCsvReader(reader io.Reader, delimiter rune, quote rune, escape rune, comment string, trim bool) *csvReader {
+	return &csvReader{
+		reader:    bufio.NewReader(reader),
+		delimiter: delimiter,
+		quote:     quote,
+		escape:    escape,
+		comment:   comment,
+		trim:      trim,
+	}
+}
+
+// Read returns the values of the next record, skipping blank and comment
+// lines. Quoted values may span lines.
+func (r *csvReader) Read() ([]string, error) {
+	line, err := r.readLine()
+	for err == nil && (strings.TrimSpace(line) == "" || (r.comment != "" && strings.HasPrefix(line, r.comment))) {
+		line, err = r.readLine()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	start := r.line
+	values := make([]string, 0)
+
+	var value strings.Builder
+	quoted, inQuotes := false, false
+	for {
+		runes := []rune(line)
+		for i := 0; i < len(runes); i++ {
+			c := runes[i]
+
+			switch {
+			case c == r.escape && r.escape != r.quote && i+1 < len(runes):
+				i++
+				value.WriteRune(runes[i])
+			case inQuotes && c == r.quote && r.escape == r.quote && i+1 < len(runes) && runes[i+1] == r.quote:
+				i++
+				value.WriteRune(r.quote)
+			case inQuotes && c == r.quote:
+				inQuotes = false
+			case inQuotes:
+				value.WriteRune(c)
+			case c == r.delimiter:
+				values = append(values, r.value(value.String()))
+				value.Reset()
+				quoted = false
+			case c == r.quote && r.quote != 0 && !quoted && strings.TrimSpace(value.String()) == "":
+				value.Reset()
+				quoted, inQuotes = true, true
+			default:
+				value.WriteRune(c)
+			}
+		}
+
+		if !inQuotes {
+			break
+		}
+
+		if line, err = r.readLine(); err != nil {
+			return nil, errors.Errorf("line %d: quoted value not closed", start)
+		}
+		value.WriteByte('\n')
+	}
+
+	return append(values, r.value(value.String())), nil
+}
+
+func (r *csvReader) value(value string) string {
+	if r.trim {
+		return strings.TrimSpace(value)
+	}
+
+	return value
+}
+
+// readLine returns the next line without its line break.
+func (r *csvReader) readLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	r.line++
+
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
@@ -0,0 +1,147 @@
+package source
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"draethos.io.com/pkg/streams/specs"
+)
+
+func TestShouldReadCsvWithQuoteAndEscapeCharacters(t *testing.T) {
+	content := "# exported orders\n" +
+		"id;note\n" +
+		"\n" +
+		"1;'it''s; fine'\n" +
+		"2;'multi\nline'\n" +
+		"3;escaped\\;value\n"
+
+	reader := newCsvReader(strings.NewReader(content), ';', '\'', '\'', "#", false)
+
+	expected := [][]string{{"id", "note"}, {"1", "it's; fine"}, {"2", "multi\nline"}, {"3", "escaped\\", "value"}}
+	for _, values := range expected {
+		records, err := reader.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(records, values) {
+			t.Errorf("expected %q, got %q", values, records)
+		}
+	}
+
+	backslash := newCsvReader(strings.NewReader(`"say \"hi\"",a\,b`), ',', '"', '\\', "", false)
+	records, err := backslash.Read()
+	if err != nil || !reflect.DeepEqual(records, []string{`say "hi"`, "a,b"}) {
+		t.Errorf("unexpected values %q: %v", records, err)
+	}
+
+	unclosed := newCsvReader(strings.NewReader("1,\"open\n"), ',', '"', '"', "", false)
+	if _, err = unclosed.Read(); err == nil {
+		t.Error("expected unclosed quote to be rejected")
+	}
+}
+
+func TestShouldTypeCsvValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.csv")
+	content := "report generated daily\n" +
+		"Order Id|Amount|Paid|Zip|Note|Meta\n" +
+		"units|brl|flag|code|text|json\n" +
+		" 1 | 10.50 | true | 01310 | NULL | {\"a\":1} \n" +
+		"2|abc|false|90210|ok|[]\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target, dlqTarget := &collectTarget{}, &collectTarget{}
+	source, err := NewCsvSource(specs.Source{Type: "csv", SourceSpecs: specs.SourceSpecs{
+		Path: path,
+		Configurations: map[string]interface{}{
+			"delimiter":   "|",
+			"header.row":  2,
+			"skip.rows":   1,
+			"trim":        true,
+			"null.values": "NULL",
+			"infer.types": true,
+			"types":       map[string]interface{}{"amount": "number", "meta": "json"},
+		},
+	}}, target, dlqTarget, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = source.Worker(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(target.events) != 1 || len(dlqTarget.events) != 1 {
+		t.Fatalf("expected an event and a dead letter, got %v and %v", target.events, dlqTarget.events)
+	}
+
+	expected := map[string]interface{}{
+		"order_id": int64(1),
+		"amount":   10.5,
+		"paid":     true,
+		"zip":      "01310",
+		"note":     nil,
+		"meta":     map[string]interface{}{"a": float64(1)},
+	}
+
+	if !reflect.DeepEqual(target.events[0], expected) {
+		t.Errorf("expected %v, got %v", expected, target.events[0])
+	}
+}
+
+func TestShouldRejectInvalidCsvConfigurations(t *testing.T) {
+	_, err := NewCsvSource(specs.Source{SourceSpecs: specs.SourceSpecs{Configurations: map[string]interface{}{"header.row": 0}}}, nil, nil, nil)
+	if err == nil {
+		t.Error("expected missing columns to be rejected")
+	}
+
+	_, err = NewCsvSource(specs.Source{SourceSpecs: specs.SourceSpecs{Configurations: map[string]interface{}{"types": map[string]interface{}{"id": "uuid"}}}}, nil, nil, nil)
+	if err == nil {
+		t.Error("expected unknown type to be rejected")
+	}
+}
+
+func TestShouldInferCsvTypesPerColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readings.csv")
+	content := "id,value,zip,label\n" +
+		"1,10,01310,a\n" +
+		"2,10.5,90210,1\n" +
+		"3,,90211,b\n" +
+		"4,high,90212,c\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target, dlqTarget := &collectTarget{}, &collectTarget{}
+	source, err := NewCsvSource(specs.Source{Type: "csv", SourceSpecs: specs.SourceSpecs{
+		Path:           path,
+		Configurations: map[string]interface{}{"infer.types": true, "infer.rows": 3},
+	}}, target, dlqTarget, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = source.Worker(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []map[string]interface{}{
+		{"id": int64(1), "value": float64(10), "zip": "01310", "label": "a"},
+		{"id": int64(2), "value": 10.5, "zip": "90210", "label": "1"},
+		{"id": int64(3), "value": nil, "zip": "90211", "label": "b"},
+	}
+
+	if !reflect.DeepEqual(target.events, expected) {
+		t.Errorf("expected %v, got %v", expected, target.events)
+	}
+
+	// rows after the inferred ones must match the types of their columns
+	if len(dlqTarget.events) != 1 {
+		t.Errorf("expected the row with a text value to be dead-lettered, got %v", dlqTarget.events)
+	}
+}
@@ -0,0 +1,140 @@
+package source
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/DataDog/zstd"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh")
+	zipMagic   = []byte("PK\x03\x04")
+
+	// bzip2Block follows the block size digit of the bzip2 header
+	bzip2Block = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+
+	compressedExtensions = []string{".gz", ".gzip", ".zst", ".zstd", ".bz2"}
+)
+
+// acceptsFile tells whether the file name holds events of the extension,
+// plain, compressed such as orders.csv.gz or as a zip archive.
+func acceptsFile(name string, extension string) bool {
+	name = strings.ToLower(name)
+	if strings.HasSuffix(name, ".zip") {
+		return true
+	}
+
+	for _, compressed := range compressedExtensions {
+		if strings.HasSuffix(name, compressed) {
+			name = strings.TrimSuffix(name, compressed)
+			break
+		}
+	}
+
+	return strings.HasSuffix(name, extension)
+}
+
+// readFile calls read with the content of the file decompressed while it is
+// read, gzip, zstd and bzip2 are recognized by their magic bytes. Zip archives
+// call read for every entry holding events of the extension, nothing is
+// extracted to disk.
+func readFile(filename string, extension string, read func(name string, reader io.Reader) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	magic := make([]byte, len(zipMagic))
+	n, _ := io.ReadFull(file, magic)
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(magic[:n], zipMagic) {
+		return decompress(filename, file, read)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return errors.Errorf("failed to open zip archive %s: %s", filename, err.Error())
+	}
+
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		name := fmt.Sprintf("%s:%s", filename, entry.Name)
+		if strings.HasSuffix(strings.ToLower(entry.Name), ".zip") || !acceptsFile(path.Base(entry.Name), extension) {
+			zap.S().Warnf("invalid file %s", name)
+			continue
+		}
+
+		reader, err := entry.Open()
+		if err != nil {
+			return errors.Errorf("failed to open %s: %s", name, err.Error())
+		}
+
+		err = decompress(name, reader, read)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decompress(name string, reader io.Reader, read func(name string, reader io.Reader) error) error {
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(len(bzip2Magic) + 1 + len(bzip2Block))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return errors.Errorf("failed to read gzip file %s: %s", name, err.Error())
+		}
+		defer gzipReader.Close()
+
+		return read(name, gzipReader)
+	case bytes.HasPrefix(magic, zstdMagic):
+		zstdReader := zstd.NewReader(buffered)
+		defer zstdReader.Close()
+
+		return read(name, zstdReader)
+	case isBzip2(magic):
+		return read(name, bzip2.NewReader(buffered))
+	}
+
+	return read(name, buffered)
+}
+
+// isBzip2 checks the block magic as well, text files may start with BZh.
+func isBzip2(magic []byte) bool {
+	if len(magic) < len(bzip2Magic)+1+len(bzip2Block) {
+		return false
+	}
+
+	return bytes.HasPrefix(magic, bzip2Magic) && bytes.Equal(magic[len(bzip2Magic)+1:], bzip2Block)
+}
@@ -0,0 +1,133 @@
+package source
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/DataDog/zstd"
+)
+
+// bzip2JsonLTest holds {"id":3} and {"id":4} compressed by the bzip2 command line.
+const bzip2JsonLTest = "425a683931415926535939a660c40000075980001010000c100420000a2000212804fd50832621384f1a249c2fc5dc914e14240e69983100"
+
+func gzipTest(t *testing.T, content string) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+func TestShouldReadCompressedJsonLFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	compressed, err := zstd.Compress(nil, []byte("{\"id\":2}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bzip2, _ := hex.DecodeString(bzip2JsonLTest)
+	files := map[string][]byte{
+		"a.jsonl.gz":  gzipTest(t, "{\"id\":1}\n"),
+		"b.jsonl.zst": compressed,
+		"c.jsonl.bz2": bzip2,
+		"d.txt":       []byte("{\"id\":5}\n"),
+	}
+
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	target := &collectTarget{}
+	source, _ := NewJsonLSource(specs.Source{Type: "jsonl", SourceSpecs: specs.SourceSpecs{Path: dir}}, target, nil, nil)
+	if err = source.Worker(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(target.events) != 4 {
+		t.Fatalf("expected 4 events, got %v", target.events)
+	}
+
+	for i, event := range target.events {
+		if event["id"] != float64(i+1) {
+			t.Errorf("expected id %d, got %v", i+1, event["id"])
+		}
+	}
+}
+
+func TestShouldReadCsvFilesOfZipArchives(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	entries := []struct {
+		name    string
+		content []byte
+	}{
+		{"2026/orders-1.csv", []byte("id,status\n1,created\n")},
+		{"2026/orders-2.csv.gz", gzipTest(t, "status,id\npaid,2\n")},
+		{"README.txt", []byte("not events")},
+	}
+
+	for _, entry := range entries {
+		file, err := writer.Create(entry.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = file.Write(entry.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the archive is recognized by its content
+	path := filepath.Join(t.TempDir(), "orders.export")
+	if err := ioutil.WriteFile(path, archive.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := &collectTarget{}
+	source, err := NewCsvSource(specs.Source{Type: "csv", SourceSpecs: specs.SourceSpecs{Path: path}}, target, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = source.Worker(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(target.events) != 2 || target.events[0]["status"] != "created" || target.events[1]["id"] != "2" {
+		t.Errorf("unexpected events: %v", target.events)
+	}
+}
+
+func TestShouldAcceptCompressedFileNames(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"orders.csv":     true,
+		"orders.CSV.GZ":  true,
+		"orders.csv.zst": true,
+		"orders.zip":     true,
+		"orders.gz":      false,
+		"orders.jsonl":   false,
+	} {
+		if acceptsFile(name, ".csv") != expected {
+			t.Errorf("%s: expected %v", name, expected)
+		}
+	}
+}
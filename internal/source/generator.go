@@ -0,0 +1,160 @@
+package source
+
+import (
+	"draethos.io.com/internal/dlq"
+	interfaces2 "draethos.io.com/internal/interfaces"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+	"go.uber.org/zap"
+)
+
+type generatorSource struct {
+	sourceSpec     specs.Source
+	target         interfaces2.TargetInterface
+	dlq            interfaces2.TargetInterface
+	template       generatorValue
+	configurations generatorSourceConfigurations
+	stop           chan struct{}
+	stopOnce       sync.Once
+}
+
+type generatorSourceConfigurations struct {
+	Rate  float64 `config:"rate"`
+	Count int64   `config:"count"`
+	Seed  int64   `config:"seed"`
+}
+
+// NewGeneratorSource produces events from the template informed on specs,
+// at most rate events per second and count events when they are set.
+func NewGeneratorSource(sourceSpec specs.Source,
+	target interfaces2.TargetInterface,
+	dlq interfaces2.TargetInterface,
+) (interfaces2.SourceInterface, error) {
+	configurations := generatorSourceConfigurations{Seed: time.Now().UnixNano()}
+	if err := specs.DecodeConfigurations(sourceSpec.SourceSpecs.Configurations, &configurations); err != nil {
+		return nil, errors.New(fmt.Sprintf("generator source: %s", err.Error()))
+	}
+
+	if len(sourceSpec.SourceSpecs.Template) == 0 {
+		return nil, errors.New("generator source: template not defined")
+	}
+
+	template, err := compileTemplate("template", sourceSpec.SourceSpecs.Template)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("generator source: %s", err.Error()))
+	}
+
+	return &generatorSource{
+		sourceSpec:     sourceSpec,
+		target:         target,
+		dlq:            dlq,
+		template:       template,
+		configurations: configurations,
+		stop:           make(chan struct{}),
+	}, nil
+}
+
+func (g *generatorSource) Worker() error {
+	if err := g.target.Initialize(); err != nil {
+		return err
+	}
+
+	if g.dlq != nil {
+		if err := g.dlq.Initialize(); err != nil {
+			return err
+		}
+	}
+
+	zap.S().Infof("generating events [rate: %v/s, count: %v, seed: %v]",
+		g.configurations.Rate, g.configurations.Count, g.configurations.Seed)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	random := rand.New(rand.NewSource(g.configurations.Seed))
+	start := time.Now()
+
+	for sequence := int64(1); g.configurations.Count <= 0 || sequence <= g.configurations.Count; sequence++ {
+		if !g.wait(start, sequence, sigChan) {
+			break
+		}
+
+		payload, _ := g.template(random, sequence).(map[string]interface{})
+		key := fmt.Sprintf("%d-%d", g.configurations.Seed, sequence)
+
+		if err := g.target.Attach(key, payload); err != nil {
+			zap.S().Errorf("failed to attach content: %s", err.Error())
+			if err = dlq.Send(g.dlq, dlq.NewEnvelope(dlq.TargetError, g.sourceSpec.Type, key, err).WithPayload(payload)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !g.target.CanFlush() {
+			continue
+		}
+
+		if err := g.target.Flush(); err != nil {
+			return errors.New(fmt.Sprintf("failed to flush event: %s", err.Error()))
+		}
+	}
+
+	if err := dlq.Flush(g.dlq); err != nil {
+		return err
+	}
+
+	if err := g.target.Flush(); err != nil {
+		return errors.New(fmt.Sprintf("failed to flush event: %s", err.Error()))
+	}
+
+	return nil
+}
+
+func (g *generatorSource) Stop() {
+	g.stopOnce.Do(func() { close(g.stop) })
+}
+
+// wait holds the event until its turn when a rate is set, it returns false
+// once the source was stopped or a termination signal was caught, the events
+// generated so far are flushed by the worker.
+func (g *generatorSource) wait(start time.Time, sequence int64, sigChan <-chan os.Signal) bool {
+	var delay time.Duration
+	if g.configurations.Rate > 0 {
+		due := start.Add(time.Duration(float64(sequence-1) / g.configurations.Rate * float64(time.Second)))
+		delay = time.Until(due)
+	}
+
+	if delay <= 0 {
+		select {
+		case <-g.stop:
+			return false
+		case sig := <-sigChan:
+			zap.S().Infof("caught signal %v: terminating", sig)
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-g.stop:
+		return false
+	case sig := <-sigChan:
+		zap.S().Infof("caught signal %v: terminating", sig)
+		return false
+	case <-timer.C:
+		return true
+	}
+}
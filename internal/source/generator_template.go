@@ -0,0 +1,262 @@
+package source
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([^}]*?)\s*\}\}`)
+
+	firstNames = []string{"Ana", "Bruno", "Carla", "Daniel", "Elisa", "Felipe", "Gabriela", "Hugo", "Isabel", "Joao", "Karen", "Lucas", "Marina", "Nelson", "Olivia", "Pedro"}
+	lastNames  = []string{"Almeida", "Barbosa", "Costa", "Dias", "Ferreira", "Gomes", "Lima", "Martins", "Nunes", "Oliveira", "Pereira", "Ribeiro", "Santos", "Silva", "Souza", "Teixeira"}
+	words      = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa"}
+	domains    = []string{"example.com", "example.org", "example.net"}
+)
+
+// generatorValue produces the value of a template node for the event with
+// the sequence informed, starting at 1.
+type generatorValue func(random *rand.Rand, sequence int64) interface{}
+
+// generatorFunctions are the placeholders accepted by templates, each one
+// validates its arguments once when the template is compiled.
+var generatorFunctions = map[string]func(args []string) (generatorValue, error){
+	"uuid": noArgs(func(random *rand.Rand, _ int64) interface{} {
+		b := make([]byte, 16)
+		random.Read(b)
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	}),
+	"firstName": noArgs(func(random *rand.Rand, _ int64) interface{} {
+		return firstNames[random.Intn(len(firstNames))]
+	}),
+	"lastName": noArgs(func(random *rand.Rand, _ int64) interface{} {
+		return lastNames[random.Intn(len(lastNames))]
+	}),
+	"name": noArgs(func(random *rand.Rand, _ int64) interface{} {
+		return fmt.Sprintf("%s %s", firstNames[random.Intn(len(firstNames))], lastNames[random.Intn(len(lastNames))])
+	}),
+	"email": noArgs(func(random *rand.Rand, sequence int64) interface{} {
+		return fmt.Sprintf("%s.%s%d@%s",
+			strings.ToLower(firstNames[random.Intn(len(firstNames))]),
+			strings.ToLower(lastNames[random.Intn(len(lastNames))]),
+			sequence,
+			domains[random.Intn(len(domains))])
+	}),
+	"word": noArgs(func(random *rand.Rand, _ int64) interface{} {
+		return words[random.Intn(len(words))]
+	}),
+	"bool": noArgs(func(random *rand.Rand, _ int64) interface{} {
+		return random.Intn(2) == 1
+	}),
+	"sequence": noArgs(func(_ *rand.Rand, sequence int64) interface{} {
+		return float64(sequence)
+	}),
+	"timestamp": noArgs(func(_ *rand.Rand, _ int64) interface{} {
+		return time.Now().UTC().Format(time.RFC3339)
+	}),
+	"unix": noArgs(func(_ *rand.Rand, _ int64) interface{} {
+		return float64(time.Now().Unix())
+	}),
+	"date": noArgs(func(_ *rand.Rand, _ int64) interface{} {
+		return time.Now().UTC().Format("2006-01-02")
+	}),
+	"int": func(args []string) (generatorValue, error) {
+		minimum, maximum, err := numberRange(args)
+		if err != nil {
+			return nil, err
+		}
+
+		low, high := int64(math.Ceil(minimum)), int64(math.Floor(maximum))
+		if high < low {
+			return nil, errors.Errorf("no integer between %s and %s", args[0], args[1])
+		}
+
+		return func(random *rand.Rand, _ int64) interface{} {
+			return float64(low + random.Int63n(high-low+1))
+		}, nil
+	},
+	"float": func(args []string) (generatorValue, error) {
+		minimum, maximum, err := numberRange(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(random *rand.Rand, _ int64) interface{} {
+			return math.Round((minimum+random.Float64()*(maximum-minimum))*100) / 100
+		}, nil
+	},
+	"choice": func(args []string) (generatorValue, error) {
+		if len(args) == 0 {
+			return nil, errors.Errorf("expected at least one option")
+		}
+
+		return func(random *rand.Rand, _ int64) interface{} {
+			return args[random.Intn(len(args))]
+		}, nil
+	},
+}
+
+// GeneratorFunctions lists the placeholders accepted by generator templates.
+func GeneratorFunctions() []string {
+	names := make([]string, 0, len(generatorFunctions))
+	for name := range generatorFunctions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// compileTemplate walks the template once, strings made of a single
+// placeholder keep the type of the value generated, such as numbers and
+// booleans, other strings have their placeholders replaced.
+func compileTemplate(path string, value interface{}) (generatorValue, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// keys are generated in a stable order so a seed always produces the
+		// same events
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fields := make([]generatorValue, 0, len(keys))
+		for _, key := range keys {
+			compiled, err := compileTemplate(joinPath(path, key), v[key])
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, compiled)
+		}
+
+		return func(random *rand.Rand, sequence int64) interface{} {
+			content := make(map[string]interface{}, len(fields))
+			for i, field := range fields {
+				content[keys[i]] = field(random, sequence)
+			}
+			return content
+		}, nil
+	case []interface{}:
+		items := make([]generatorValue, 0, len(v))
+		for i, child := range v {
+			compiled, err := compileTemplate(fmt.Sprintf("%s[%d]", path, i), child)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, compiled)
+		}
+
+		return func(random *rand.Rand, sequence int64) interface{} {
+			content := make([]interface{}, 0, len(items))
+			for _, item := range items {
+				content = append(content, item(random, sequence))
+			}
+			return content
+		}, nil
+	case string:
+		return compileString(path, v)
+	}
+
+	return func(_ *rand.Rand, _ int64) interface{} {
+		return value
+	}, nil
+}
+
+func compileString(path string, value string) (generatorValue, error) {
+	matches := placeholderPattern.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return func(_ *rand.Rand, _ int64) interface{} {
+			return value
+		}, nil
+	}
+
+	placeholders := make([]generatorValue, 0, len(matches))
+	for _, match := range matches {
+		expression := strings.Fields(value[match[2]:match[3]])
+		if len(expression) == 0 {
+			return nil, errors.Errorf("%s: empty placeholder", path)
+		}
+
+		function, ok := generatorFunctions[expression[0]]
+		if !ok {
+			return nil, errors.Errorf("%s: unknown function %s, expected one of: %s", path, expression[0], strings.Join(GeneratorFunctions(), ", "))
+		}
+
+		compiled, err := function(expression[1:])
+		if err != nil {
+			return nil, errors.Errorf("%s: %s: %s", path, expression[0], err.Error())
+		}
+		placeholders = append(placeholders, compiled)
+	}
+
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(value) {
+		return placeholders[0], nil
+	}
+
+	return func(random *rand.Rand, sequence int64) interface{} {
+		var builder strings.Builder
+		last := 0
+		for i, match := range matches {
+			builder.WriteString(value[last:match[0]])
+			switch generated := placeholders[i](random, sequence).(type) {
+			case float64:
+				builder.WriteString(strconv.FormatFloat(generated, 'f', -1, 64))
+			default:
+				builder.WriteString(fmt.Sprint(generated))
+			}
+			last = match[1]
+		}
+		builder.WriteString(value[last:])
+		return builder.String()
+	}, nil
+}
+
+func noArgs(value generatorValue) func(args []string) (generatorValue, error) {
+	return func(args []string) (generatorValue, error) {
+		if len(args) > 0 {
+			return nil, errors.Errorf("unexpected arguments %s", strings.Join(args, " "))
+		}
+		return value, nil
+	}
+}
+
+func numberRange(args []string) (float64, float64, error) {
+	if len(args) != 2 {
+		return 0, 0, errors.Errorf("expected minimum and maximum, such as {{int 1 100}}")
+	}
+
+	minimum, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid minimum %s", args[0])
+	}
+
+	maximum, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid maximum %s", args[1])
+	}
+
+	if maximum < minimum {
+		return 0, 0, errors.Errorf("maximum %s lower than minimum %s", args[1], args[0])
+	}
+
+	return minimum, maximum, nil
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", path, key)
+}
@@ -0,0 +1,189 @@
+package source
+
+import (
+	"math/rand"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+)
+
+type collectTarget struct {
+	events  []map[string]interface{}
+	flushes int
+}
+
+func (c *collectTarget) Initialize() error { return nil }
+func (c *collectTarget) CanFlush() bool    { return len(c.events)%2 == 0 }
+func (c *collectTarget) Close() error      { return nil }
+
+func (c *collectTarget) Attach(_ string, data map[string]interface{}) error {
+	c.events = append(c.events, data)
+	return nil
+}
+
+func (c *collectTarget) Flush() error {
+	c.flushes++
+	return nil
+}
+
+func TestShouldGenerateTypedValuesFromTemplate(t *testing.T) {
+	template, err := compileTemplate("template", map[string]interface{}{
+		"id":       "{{sequence}}",
+		"quantity": "{{int 1 3}}",
+		"price":    "{{float 1 2}}",
+		"active":   "{{bool}}",
+		"order":    "order-{{sequence}}-{{choice a b}}",
+		"fixed":    10,
+		"customer": map[string]interface{}{"email": "{{email}}"},
+		"tags":     []interface{}{"{{word}}"},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template: %v", err)
+	}
+
+	event := template(rand.New(rand.NewSource(1)), 7).(map[string]interface{})
+
+	if event["id"] != float64(7) || event["fixed"] != 10 {
+		t.Errorf("unexpected values: %v", event)
+	}
+
+	if quantity, ok := event["quantity"].(float64); !ok || quantity < 1 || quantity > 3 || quantity != float64(int(quantity)) {
+		t.Errorf("expected integer between 1 and 3, got %v", event["quantity"])
+	}
+
+	if price, ok := event["price"].(float64); !ok || price < 1 || price > 2 {
+		t.Errorf("expected number between 1 and 2, got %v", event["price"])
+	}
+
+	if _, ok := event["active"].(bool); !ok {
+		t.Errorf("expected boolean, got %v", event["active"])
+	}
+
+	if order := event["order"].(string); order != "order-7-a" && order != "order-7-b" {
+		t.Errorf("unexpected interpolated string: %s", order)
+	}
+
+	if email := event["customer"].(map[string]interface{})["email"].(string); !strings.Contains(email, "7@example.") {
+		t.Errorf("unexpected email: %s", email)
+	}
+
+	again := template(rand.New(rand.NewSource(1)), 7).(map[string]interface{})
+	if again["price"] != event["price"] || again["order"] != event["order"] {
+		t.Errorf("same seed generated different events: %v, %v", event, again)
+	}
+}
+
+func TestShouldRejectInvalidTemplate(t *testing.T) {
+	for template, message := range map[string]string{
+		"{{nope}}":      "unknown function nope",
+		"{{int 10 1}}":  "maximum 1 lower than minimum 10",
+		"{{int 1}}":     "expected minimum and maximum",
+		"{{uuid 1}}":    "unexpected arguments 1",
+		"{{choice}}":    "expected at least one option",
+		"{{float a 1}}": "invalid minimum a",
+	} {
+		_, err := compileTemplate("template", map[string]interface{}{"field": template})
+		if err == nil || !strings.Contains(err.Error(), "template.field") || !strings.Contains(err.Error(), message) {
+			t.Errorf("%s: expected error %q, got %v", template, message, err)
+		}
+	}
+}
+
+func TestShouldStopAfterCount(t *testing.T) {
+	target := &collectTarget{}
+	source, err := NewGeneratorSource(specs.Source{Type: "generator", SourceSpecs: specs.SourceSpecs{
+		Template:       map[string]interface{}{"id": "{{uuid}}"},
+		Configurations: map[string]interface{}{"count": 5, "seed": 1},
+	}}, target, nil)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	if err = source.Worker(); err != nil {
+		t.Fatalf("generator failed: %v", err)
+	}
+
+	if len(target.events) != 5 || target.flushes != 3 {
+		t.Errorf("expected 5 events in 3 flushes, got %d in %d", len(target.events), target.flushes)
+	}
+}
+
+func TestShouldStopGeneratorOnce(t *testing.T) {
+	target := &collectTarget{}
+	source, err := NewGeneratorSource(specs.Source{Type: "generator", SourceSpecs: specs.SourceSpecs{
+		Template:       map[string]interface{}{"id": "{{sequence}}"},
+		Configurations: map[string]interface{}{"rate": 1},
+	}}, target, nil)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Worker()
+	}()
+
+	source.Stop()
+	source.Stop()
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Errorf("generator failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected generator to stop")
+	}
+}
+
+// readyTarget tells when the first event was attached.
+type readyTarget struct {
+	collectTarget
+	ready chan struct{}
+}
+
+func (r *readyTarget) Attach(key string, data map[string]interface{}) error {
+	if len(r.events) == 0 {
+		close(r.ready)
+	}
+
+	return r.collectTarget.Attach(key, data)
+}
+
+func TestShouldFlushGeneratorOnTerminationSignal(t *testing.T) {
+	target := &readyTarget{ready: make(chan struct{})}
+	source, err := NewGeneratorSource(specs.Source{Type: "generator", SourceSpecs: specs.SourceSpecs{
+		Template:       map[string]interface{}{"id": "{{sequence}}"},
+		Configurations: map[string]interface{}{"rate": 1},
+	}}, target, nil)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Worker()
+	}()
+
+	<-target.ready
+	if err = syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Errorf("generator failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected generator to stop on SIGTERM")
+	}
+
+	if len(target.events) != 1 || target.flushes != 1 {
+		t.Errorf("expected 1 event flushed on exit, got %d events in %d flushes", len(target.events), target.flushes)
+	}
+}
@@ -1,9 +1,12 @@
 package source
 
 import (
+	"context"
 	"crypto/md5"
 	"crypto/tls"
+	"draethos.io.com/internal/dlq"
 	interfaces2 "draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/logging"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +15,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -21,19 +25,28 @@ import (
 )
 
 type httpSource struct {
-	sourceSpec   specs.Source
-	target       interfaces2.TargetInterface
-	dlq          interfaces2.TargetInterface
-	codec        interfaces2.CodecInterface
-	router       *mux.Router
-	port         string
-	writeTimeout int64
-	readTimeout  int64
-	idleTimeout  int64
-	serverName   string
+	sourceSpec     specs.Source
+	target         interfaces2.TargetInterface
+	dlq            interfaces2.TargetInterface
+	codec          interfaces2.CodecInterface
+	router         *mux.Router
+	port           string
+	configurations httpSourceConfigurations
+	stop           chan struct{}
+	stopOnce       *sync.Once
 }
 
-const MethodsAllowedDefault = "GET,POST"
+type httpSourceConfigurations struct {
+	WriteTimeout time.Duration `config:"writeTimeout"`
+	ReadTimeout  time.Duration `config:"readTimeout"`
+	IdleTimeout  time.Duration `config:"idleTimeout"`
+	ServerName   string        `config:"serverName"`
+}
+
+const (
+	MethodsAllowedDefault = "GET,POST"
+	HttpTimeoutDefault    = 15 * time.Second
+)
 
 func NewHttpSource(sourceSpec specs.Source,
 	target interfaces2.TargetInterface,
@@ -41,13 +54,26 @@ func NewHttpSource(sourceSpec specs.Source,
 	codec interfaces2.CodecInterface,
 	router *mux.Router,
 	port string) (interfaces2.SourceInterface, error) {
+	configurations := httpSourceConfigurations{
+		WriteTimeout: HttpTimeoutDefault,
+		ReadTimeout:  HttpTimeoutDefault,
+		IdleTimeout:  HttpTimeoutDefault,
+	}
+
+	if err := specs.DecodeConfigurations(sourceSpec.SourceSpecs.Configurations, &configurations); err != nil {
+		return nil, errors.New(fmt.Sprintf("http source: %s", err.Error()))
+	}
+
 	return httpSource{
-		sourceSpec: sourceSpec,
-		target:     target,
-		dlq:        dlq,
-		codec:      codec,
-		router:     router,
-		port:       port,
+		sourceSpec:     sourceSpec,
+		target:         target,
+		dlq:            dlq,
+		codec:          codec,
+		router:         router,
+		port:           port,
+		configurations: configurations,
+		stop:           make(chan struct{}),
+		stopOnce:       &sync.Once{},
 	}, nil
 }
 
@@ -62,22 +88,6 @@ func (k httpSource) Worker() error {
 		}
 	}
 
-	if v, ok := k.sourceSpec.SourceSpecs.Configurations["writeTimeout"].(int64); ok {
-		k.writeTimeout = v
-	}
-
-	if v, ok := k.sourceSpec.SourceSpecs.Configurations["readTimeout"].(int64); ok {
-		k.readTimeout = v
-	}
-
-	if v, ok := k.sourceSpec.SourceSpecs.Configurations["idleTimeout"].(int64); ok {
-		k.idleTimeout = v
-	}
-
-	if v, ok := k.sourceSpec.SourceSpecs.Configurations["serverName"].(string); ok {
-		k.serverName = v
-	}
-
 	if k.sourceSpec.SourceSpecs.Method == "" {
 		k.sourceSpec.SourceSpecs.Method = MethodsAllowedDefault
 	}
@@ -96,11 +106,11 @@ func (k httpSource) Worker() error {
 	srv := &http.Server{
 		Handler:      k.router,
 		Addr:         fmt.Sprintf("0.0.0.0:%s", k.port),
-		WriteTimeout: time.Duration(k.writeTimeout) * time.Second,
-		ReadTimeout:  time.Duration(k.readTimeout) * time.Second,
-		IdleTimeout:  time.Duration(k.idleTimeout) * time.Second,
+		WriteTimeout: k.configurations.WriteTimeout,
+		ReadTimeout:  k.configurations.ReadTimeout,
+		IdleTimeout:  k.configurations.IdleTimeout,
 		TLSConfig: &tls.Config{
-			ServerName: k.serverName,
+			ServerName: k.configurations.ServerName,
 		},
 	}
 
@@ -111,7 +121,23 @@ func (k httpSource) Worker() error {
 		}
 	}()
 
-	<-sigchan
+	select {
+	case <-sigchan:
+	case <-k.stop:
+		zap.S().Infof("stop requested: shutting down endpoint %s", k.sourceSpec.SourceSpecs.Endpoint)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		zap.S().Warnf("failed to shutdown server: %s", err.Error())
+	}
+
+	if err := dlq.Flush(k.dlq); err != nil {
+		return err
+	}
+
 	if err := k.target.Flush(); err != nil {
 		return errors.New(fmt.Sprintf("failed to flush event: %s", err.Error()))
 	}
@@ -119,6 +145,10 @@ func (k httpSource) Worker() error {
 	return nil
 }
 
+func (k httpSource) Stop() {
+	k.stopOnce.Do(func() { close(k.stop) })
+}
+
 func (k httpSource) Handle(w http.ResponseWriter, r *http.Request) {
 
 	defer r.Body.Close()
@@ -130,11 +160,12 @@ func (k httpSource) Handle(w http.ResponseWriter, r *http.Request) {
 
 	payload := make(map[string]interface{})
 	if body, err := ioutil.ReadAll(r.Body); err == nil && len(body) > 0 {
-		key = fmt.Sprintf("'%x'", md5.Sum(body))
+		key = RequestKey(body)
 
 		payload, err = k.codec.Deserialize(body)
 		if err != nil {
 			zap.S().Errorf("failed to deserialize content: %s", err.Error())
+			k.deadLetter(dlq.NewEnvelope(dlq.DecodeError, k.sourceSpec.Type, key, err).WithRaw(body))
 
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(map[string]string{
@@ -149,10 +180,11 @@ func (k httpSource) Handle(w http.ResponseWriter, r *http.Request) {
 		payload[k] = r.URL.Query().Get(k)
 	}
 
-	zap.S().Infof("processing request [%s %s => %v]", r.Method, r.RequestURI, payload)
+	zap.S().Debugf("processing request [%s %s => %s]", r.Method, r.RequestURI, logging.Payload(payload))
 
 	if err := k.target.Attach(key, payload); err != nil {
 		zap.S().Errorf("failed to attach content: %s", err.Error())
+		k.deadLetter(dlq.NewEnvelope(dlq.TargetError, k.sourceSpec.Type, key, err).WithPayload(payload))
 
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -180,3 +212,20 @@ func (k httpSource) Handle(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(payload)
 }
+
+// deadLetter keeps the rejected request on the dlq, the client is still
+// answered with the rejection.
+func (k httpSource) deadLetter(envelope dlq.Envelope) {
+	if k.dlq == nil {
+		return
+	}
+
+	if err := dlq.Send(k.dlq, envelope); err != nil {
+		zap.S().Errorf("failed to send event to dlq: %s", err.Error())
+	}
+}
+
+// RequestKey is the key of events received with the body informed.
+func RequestKey(body []byte) string {
+	return fmt.Sprintf("'%x'", md5.Sum(body))
+}
@@ -3,13 +3,14 @@ package source
 import (
 	"bufio"
 	"crypto/md5"
+	"draethos.io.com/internal/dlq"
 	interfaces2 "draethos.io.com/internal/interfaces"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 
 	"draethos.io.com/pkg/streams/specs"
@@ -22,6 +23,8 @@ type jsonLSource struct {
 	target     interfaces2.TargetInterface
 	dlq        interfaces2.TargetInterface
 	codec      interfaces2.CodecInterface
+	stop       chan struct{}
+	stopOnce   sync.Once
 }
 
 func NewJsonLSource(sourceSpec specs.Source,
@@ -34,6 +37,7 @@ func NewJsonLSource(sourceSpec specs.Source,
 		target:     target,
 		dlq:        dlq,
 		codec:      codec,
+		stop:       make(chan struct{}),
 	}, nil
 }
 
@@ -71,7 +75,11 @@ func (c *jsonLSource) processDir(path string) error {
 	}
 
 	for _, v := range files {
-		if !strings.HasSuffix(v, ".jsonl") {
+		if c.stopped() {
+			break
+		}
+
+		if !acceptsFile(v, ".jsonl") {
 			zap.S().Warnf("invalid file %s", v)
 			continue
 		}
@@ -90,27 +98,47 @@ func (c *jsonLSource) processFile(filename string) error {
 		return err
 	}
 
-	file, err := os.Open(filename)
-	if err != nil {
+	if err := readFile(filename, ".jsonl", c.processReader); err != nil {
 		zap.S().Errorf("failed to load jsonl file %s: %s", filename, err.Error())
 		return err
 	}
 
-	defer file.Close()
+	if err := dlq.Flush(c.dlq); err != nil {
+		return err
+	}
+
+	if err := c.target.Flush(); err != nil {
+		return errors.New(fmt.Sprintf("failed to flush event: %s", err.Error()))
+	}
+
+	return nil
+}
+
+// processReader sends the lines of a jsonl file, or of a file inside an
+// archive, to the target.
+func (c *jsonLSource) processReader(filename string, file io.Reader) error {
+	var err error
 
 	scanner := bufio.NewScanner(file)
 	scanner.Split(bufio.ScanLines)
 
-	for scanner.Scan() {
+	for !c.stopped() && scanner.Scan() {
+		key := fmt.Sprintf("%x", md5.Sum(scanner.Bytes()))
+
 		var payload = make(map[string]interface{}, 0)
 		if err = json.Unmarshal(scanner.Bytes(), &payload); err != nil {
-			return err
+			if err = dlq.Send(c.dlq, dlq.NewEnvelope(dlq.DecodeError, c.sourceSpec.Type, key, err).WithRaw(scanner.Bytes())); err != nil {
+				return err
+			}
+			continue
 		}
 
-		key := fmt.Sprintf("%x", md5.Sum(scanner.Bytes()))
 		if err = c.target.Attach(key, payload); err != nil {
 			zap.S().Errorf("failed to attach content: %s", err.Error())
-			return err
+			if err = dlq.Send(c.dlq, dlq.NewEnvelope(dlq.TargetError, c.sourceSpec.Type, key, err).WithPayload(payload)); err != nil {
+				return err
+			}
+			continue
 		}
 
 		if !c.target.CanFlush() {
@@ -122,13 +150,26 @@ func (c *jsonLSource) processFile(filename string) error {
 		}
 	}
 
-	if err = c.target.Flush(); err != nil {
-		return errors.New(fmt.Sprintf("failed to flush event: %s", err.Error()))
+	if err = scanner.Err(); err != nil {
+		return errors.New(fmt.Sprintf("failed to read %s: %s", filename, err.Error()))
 	}
 
 	return nil
 }
 
+func (c *jsonLSource) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+func (c *jsonLSource) stopped() bool {
+	select {
+	case <-c.stop:
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *jsonLSource) filePathWalkDir(root string) ([]string, error) {
 	var files []string
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -1,11 +1,16 @@
 package source
 
 import (
+	"draethos.io.com/internal/dlq"
 	interfaces2 "draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/logging"
 	"os"
 	"os/signal"
+	"regexp"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"draethos.io.com/pkg/streams/specs"
 	"github.com/confluentinc/confluent-kafka-go/kafka"
@@ -19,13 +24,25 @@ type kafkaSource struct {
 	dlq        interfaces2.TargetInterface
 	codec      interfaces2.CodecInterface
 	configMap  kafka.ConfigMap
+	position   *kafkaPosition
+	stop       chan struct{}
+	stopOnce   *sync.Once
 }
 
 func NewKafkaSource(sourceSpec specs.Source,
 	target interfaces2.TargetInterface,
 	dlq interfaces2.TargetInterface,
 	codec interfaces2.CodecInterface) (interfaces2.SourceInterface, error) {
-	return kafkaSource{sourceSpec: sourceSpec, target: target, dlq: dlq, codec: codec, configMap: kafka.ConfigMap{
+	if err := validateKafkaWorkers(sourceSpec.SourceSpecs.Workers, sourceSpec.SourceSpecs.DispatchBy); err != nil {
+		return nil, err
+	}
+
+	position, err := newKafkaPosition(sourceSpec.SourceSpecs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return kafkaSource{sourceSpec: sourceSpec, target: target, dlq: dlq, codec: codec, position: position, stop: make(chan struct{}), stopOnce: &sync.Once{}, configMap: kafka.ConfigMap{
 		"go.application.rebalance.enable": true,
 		"enable.partition.eof":            true,
 		"enable.auto.commit":              false,
@@ -57,7 +74,11 @@ func (k kafkaSource) Worker() error {
 		return err
 	}
 
-	topics := append(strings.Split(k.sourceSpec.SourceSpecs.Topic, ","), "^aRegex.*[Tt]opic")
+	topics, err := kafkaTopics(k.sourceSpec.SourceSpecs.Topic, k.sourceSpec.SourceSpecs.TopicPattern)
+	if err != nil {
+		return err
+	}
+
 	err = consumer.SubscribeTopics(topics, nil)
 	if err != nil {
 		return err
@@ -67,14 +88,28 @@ func (k kafkaSource) Worker() error {
 
 	zap.S().Infof("topic successfully subscribed [%s], waiting messages", topics)
 
+	if k.sourceSpec.SourceSpecs.Workers > 1 {
+		return newKafkaDispatcher(k, consumer).run(sigChan)
+	}
+
 	run := true
 	for run {
 		select {
 		case sig := <-sigChan:
 			run = false
 			zap.S().Infof("caught signal %v: terminating", sig)
-			if err = k.target.Flush(); err != nil {
-				return errors.Errorf("failed to flush messages [error: %v]", err.Error())
+			if err = k.flush(); err != nil {
+				return err
+			}
+
+			if _, err = consumer.Commit(); err == nil {
+				zap.S().Infof("events successfully committed")
+			}
+		case <-k.stop:
+			run = false
+			zap.S().Infof("stop requested: terminating")
+			if err = k.flush(); err != nil {
+				return err
 			}
 
 			if _, err = consumer.Commit(); err == nil {
@@ -85,14 +120,28 @@ func (k kafkaSource) Worker() error {
 			switch e := ev.(type) {
 			case kafka.AssignedPartitions:
 				zap.S().Debugf("assigned partitions [%v]", e.Partitions)
-				_ = consumer.Assign(e.Partitions)
+				partitions, err := k.position.Assign(consumer, e.Partitions)
+				if err != nil {
+					return err
+				}
+				_ = consumer.Assign(partitions)
 			case kafka.RevokedPartitions:
 				zap.S().Debugf("revoked partitions [%v]", e.Partitions)
+				k.position.Revoke(e.Partitions)
 				_ = consumer.Unassign()
 			case *kafka.Message:
-				err = k.handleEvent(e)
-				if err != nil {
-					zap.S().Debugf(err.Error())
+				if k.position.Reached(e) {
+					_ = consumer.Pause([]kafka.TopicPartition{e.TopicPartition})
+					if k.position.Finished() {
+						return k.finish(consumer)
+					}
+					continue
+				}
+
+				if err = k.handleEvent(e); err != nil {
+					if err = k.rejected(err); err != nil {
+						return err
+					}
 					continue
 				}
 
@@ -100,16 +149,21 @@ func (k kafkaSource) Worker() error {
 					continue
 				}
 
-				if err = k.target.Flush(); err != nil {
-					return errors.Errorf("failed to flush messages [error: %v]", err.Error())
+				if err = k.flush(); err != nil {
+					return err
 				}
 
 				if _, err = consumer.Commit(); err == nil {
 					zap.S().Infof("events successfully committed")
 				}
 			case kafka.PartitionEOF:
-				if err = k.target.Flush(); err != nil {
-					return errors.Errorf("failed to flush messages [error: %v]", err.Error())
+				k.position.EOF(kafka.TopicPartition(e))
+				if k.position.Finished() {
+					return k.finish(consumer)
+				}
+
+				if err = k.flush(); err != nil {
+					return err
 				}
 
 				if _, err = consumer.Commit(); err == nil {
@@ -124,13 +178,137 @@ func (k kafkaSource) Worker() error {
 	return nil
 }
 
+// finish flushes and commits once every partition reached stopAt. The offsets
+// stored by the poll may be past the events reaching stopAt, the position each
+// partition stopped at is committed last.
+func (k kafkaSource) finish(consumer *kafka.Consumer) error {
+	zap.S().Infof("every partition reached stopAt: terminating")
+
+	if err := k.flush(); err != nil {
+		return err
+	}
+
+	if _, err := consumer.Commit(); err != nil {
+		if kafkaErr, ok := err.(kafka.Error); !ok || kafkaErr.Code() != kafka.ErrNoOffset {
+			return errors.Errorf("failed to commit offsets: %s", err.Error())
+		}
+	}
+
+	if stopped := k.position.Stopped(); len(stopped) > 0 {
+		if _, err := consumer.CommitOffsets(stopped); err != nil {
+			return errors.Errorf("failed to commit offsets: %s", err.Error())
+		}
+	}
+
+	zap.S().Infof("events successfully committed")
+
+	return nil
+}
+
+// withTopic writes the topic the event was consumed from to the field.
+func withTopic(payload map[string]interface{}, field string, topic string) map[string]interface{} {
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+
+	payload[field] = topic
+	return payload
+}
+
+// kafkaTopics lists the topics subscribed, the pattern is subscribed as a
+// regular expression, which librdkafka expects to start with "^".
+func kafkaTopics(topic string, pattern string) ([]string, error) {
+	topics := make([]string, 0)
+	for _, name := range strings.Split(topic, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			topics = append(topics, name)
+		}
+	}
+
+	if pattern != "" {
+		if !strings.HasPrefix(pattern, "^") {
+			pattern = "^" + pattern
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, errors.Errorf("invalid topicPattern %s: %s", pattern, err.Error())
+		}
+
+		topics = append(topics, pattern)
+	}
+
+	if len(topics) == 0 {
+		return nil, errors.Errorf("kafka source: topic or topicPattern not defined")
+	}
+
+	return topics, nil
+}
+
+func (k kafkaSource) Stop() {
+	k.stopOnce.Do(func() { close(k.stop) })
+}
+
 func (k *kafkaSource) handleEvent(msg *kafka.Message) error {
-	zap.S().Debugf("processing event [key: %s, value %s]", msg.Key, msg.Value)
+	zap.S().Debugf("processing event [key: %s, value %s]", msg.Key, logging.Payload(msg.Value))
+
+	topic := ""
+	if msg.TopicPartition.Topic != nil {
+		topic = *msg.TopicPartition.Topic
+	}
 
-	payload, err := k.codec.Deserialize(msg.Value)
+	return HandleMessage(k.sourceSpec, k.codec, k.target, k.dlq, string(msg.Key), msg.Value, topic)
+}
+
+// rejected logs an event the target and the dlq did not take. Without a dlq
+// the event is skipped, a dlq failure is returned so the worker stops before
+// committing the offset of the event.
+func (k kafkaSource) rejected(err error) error {
+	if k.dlq == nil {
+		zap.S().Errorf("failed to handle event: %s", err.Error())
+		return nil
+	}
+
+	zap.S().Errorf("failed to send event to dlq: %s", err.Error())
+
+	return err
+}
+
+// HandleMessage decodes a message value and attaches the event to the target,
+// sending it to the dlq when either fails. The topic is written to the
+// topicField of the source when both are set.
+func HandleMessage(sourceSpec specs.Source,
+	codec interfaces2.CodecInterface,
+	target interfaces2.TargetInterface,
+	deadLetters interfaces2.TargetInterface,
+	key string,
+	value []byte,
+	topic string) error {
+	payload, err := codec.Deserialize(value)
 	if err != nil {
+		return dlq.Send(deadLetters, dlq.NewEnvelope(dlq.DecodeError, sourceSpec.Type, key, err).WithRaw(value))
+	}
+
+	if sourceSpec.SourceSpecs.TopicField != "" && topic != "" {
+		payload = withTopic(payload, sourceSpec.SourceSpecs.TopicField, topic)
+	}
+
+	if err = target.Attach(key, payload); err != nil {
+		return dlq.Send(deadLetters, dlq.NewEnvelope(dlq.TargetError, sourceSpec.Type, key, err).WithPayload(payload))
+	}
+
+	return nil
+}
+
+// flush sends the dead-lettered events before the target ones, so offsets
+// are only committed once both are stored.
+func (k kafkaSource) flush() error {
+	if err := dlq.Flush(k.dlq); err != nil {
 		return err
 	}
 
-	return k.target.Attach(string(msg.Key), payload)
+	if err := k.target.Flush(); err != nil {
+		return errors.Errorf("failed to flush messages [error: %v]", err.Error())
+	}
+
+	return nil
 }
@@ -0,0 +1,352 @@
+package source
+
+import (
+	"hash/fnv"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	KafkaDispatchByPartition = "partition"
+	KafkaDispatchByKey       = "key"
+
+	kafkaWorkerQueueSize = 1000
+)
+
+// kafkaDispatcher spreads the events polled across the workers, events of the
+// same partition, or the same key, always go to the same worker so they are
+// attached in order. A flush waits for the events being attached and commits
+// for every partition the offsets flushed.
+type kafkaDispatcher struct {
+	source   kafkaSource
+	consumer *kafka.Consumer
+	queues   []chan *kafka.Message
+	workers  sync.WaitGroup
+	inflight sync.WaitGroup
+
+	// attaching is held for reading while an event is attached and for
+	// writing while the target is flushed.
+	attaching sync.RWMutex
+	offsets   *offsetTracker
+
+	errOnce sync.Once
+	err     error
+	failed  chan struct{}
+}
+
+func newKafkaDispatcher(source kafkaSource, consumer *kafka.Consumer) *kafkaDispatcher {
+	queues := make([]chan *kafka.Message, source.sourceSpec.SourceSpecs.Workers)
+	for i := range queues {
+		queues[i] = make(chan *kafka.Message, kafkaWorkerQueueSize)
+	}
+
+	return &kafkaDispatcher{
+		source:   source,
+		consumer: consumer,
+		queues:   queues,
+		offsets:  newOffsetTracker(),
+		failed:   make(chan struct{}),
+	}
+}
+
+func (d *kafkaDispatcher) run(sigChan chan os.Signal) error {
+	for i, queue := range d.queues {
+		d.workers.Add(1)
+		go d.work(i, queue)
+	}
+
+	zap.S().Infof("processing events with %d workers dispatched by %s", len(d.queues), d.dispatchBy())
+
+	defer d.shutdown()
+
+	for {
+		select {
+		case sig := <-sigChan:
+			zap.S().Infof("caught signal %v: terminating", sig)
+			return d.drain()
+		case <-d.source.stop:
+			zap.S().Infof("stop requested: terminating")
+			return d.drain()
+		case <-d.failed:
+			d.drain()
+			return d.err
+		default:
+			ev := d.consumer.Poll(d.source.sourceSpec.SourceSpecs.TimeoutMs)
+			switch e := ev.(type) {
+			case kafka.AssignedPartitions:
+				zap.S().Debugf("assigned partitions [%v]", e.Partitions)
+				partitions, err := d.source.position.Assign(d.consumer, e.Partitions)
+				if err != nil {
+					return err
+				}
+				_ = d.consumer.Assign(partitions)
+			case kafka.RevokedPartitions:
+				zap.S().Debugf("revoked partitions [%v]", e.Partitions)
+				d.source.position.Revoke(e.Partitions)
+				// the events of the revoked partitions are flushed and committed
+				// before another consumer of the group receives them
+				d.inflight.Wait()
+				if err := d.flush(); err != nil {
+					return err
+				}
+				d.offsets.Reset()
+				_ = d.consumer.Unassign()
+			case *kafka.Message:
+				if d.source.position.Reached(e) {
+					_ = d.consumer.Pause([]kafka.TopicPartition{e.TopicPartition})
+					if d.source.position.Finished() {
+						zap.S().Infof("every partition reached stopAt: terminating")
+						return d.drain()
+					}
+					continue
+				}
+
+				d.dispatch(e)
+			case kafka.PartitionEOF:
+				d.source.position.EOF(kafka.TopicPartition(e))
+				if err := d.flush(); err != nil {
+					return err
+				}
+
+				if d.source.position.Finished() {
+					zap.S().Infof("every partition reached stopAt: terminating")
+					return d.drain()
+				}
+			case kafka.Error:
+				zap.S().Debugf(e.Error())
+			}
+		}
+	}
+}
+
+func (d *kafkaDispatcher) dispatch(msg *kafka.Message) {
+	d.offsets.Dispatched(msg.TopicPartition)
+	d.inflight.Add(1)
+
+	select {
+	case d.queues[d.worker(msg)] <- msg:
+	case <-d.failed:
+		d.inflight.Done()
+	}
+}
+
+func (d *kafkaDispatcher) worker(msg *kafka.Message) int {
+	if d.dispatchBy() == KafkaDispatchByKey {
+		hash := fnv.New32a()
+		_, _ = hash.Write(msg.Key)
+		return int(hash.Sum32() % uint32(len(d.queues)))
+	}
+
+	return int(msg.TopicPartition.Partition) % len(d.queues)
+}
+
+func (d *kafkaDispatcher) dispatchBy() string {
+	if d.source.sourceSpec.SourceSpecs.DispatchBy == "" {
+		return KafkaDispatchByPartition
+	}
+
+	return d.source.sourceSpec.SourceSpecs.DispatchBy
+}
+
+func (d *kafkaDispatcher) work(id int, queue chan *kafka.Message) {
+	defer d.workers.Done()
+
+	for msg := range queue {
+		d.attaching.RLock()
+		if err := d.source.handleEvent(msg); err != nil {
+			if err = d.source.rejected(err); err != nil {
+				// the offset stays pending, so it is not committed
+				d.attaching.RUnlock()
+				d.inflight.Done()
+				d.fail(errors.Errorf("worker %d: %s", id, err.Error()))
+				continue
+			}
+		}
+		d.offsets.Done(msg.TopicPartition)
+		canFlush := d.source.target.CanFlush()
+		d.attaching.RUnlock()
+		d.inflight.Done()
+
+		if !canFlush {
+			continue
+		}
+
+		if err := d.flush(); err != nil {
+			d.fail(err)
+		}
+	}
+}
+
+// flush sends the events attached to the target and commits the offsets of
+// every partition up to the first event not attached yet.
+func (d *kafkaDispatcher) flush() error {
+	d.attaching.Lock()
+	defer d.attaching.Unlock()
+
+	if err := d.source.flush(); err != nil {
+		return err
+	}
+
+	offsets := d.offsets.Committable()
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	if _, err := d.consumer.CommitOffsets(offsets); err != nil {
+		zap.S().Warnf("failed to commit offsets: %s", err.Error())
+		return nil
+	}
+
+	d.offsets.Committed(offsets)
+	zap.S().Infof("events successfully committed")
+
+	return nil
+}
+
+func (d *kafkaDispatcher) fail(err error) {
+	d.errOnce.Do(func() {
+		d.err = err
+		close(d.failed)
+	})
+}
+
+// drain waits for the events dispatched and flushes them.
+func (d *kafkaDispatcher) drain() error {
+	d.shutdown()
+	return d.flush()
+}
+
+func (d *kafkaDispatcher) shutdown() {
+	d.errOnce.Do(func() {
+		close(d.failed)
+	})
+
+	for _, queue := range d.queues {
+		if queue != nil {
+			close(queue)
+		}
+	}
+
+	d.workers.Wait()
+
+	for i := range d.queues {
+		d.queues[i] = nil
+	}
+}
+
+// offsetTracker follows, per partition, the events dispatched in offset order
+// and which of them were attached, an offset is committable once every event
+// before it was attached.
+type offsetTracker struct {
+	sync.Mutex
+	partitions map[partitionKey]*partitionOffsets
+}
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+type partitionOffsets struct {
+	pending   []kafka.Offset
+	done      map[kafka.Offset]bool
+	attached  kafka.Offset
+	committed kafka.Offset
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: map[partitionKey]*partitionOffsets{}}
+}
+
+func (o *offsetTracker) Dispatched(tp kafka.TopicPartition) {
+	o.Lock()
+	defer o.Unlock()
+
+	p := o.partition(tp)
+	p.pending = append(p.pending, tp.Offset)
+}
+
+func (o *offsetTracker) Done(tp kafka.TopicPartition) {
+	o.Lock()
+	defer o.Unlock()
+
+	p := o.partition(tp)
+	p.done[tp.Offset] = true
+	for len(p.pending) > 0 && p.done[p.pending[0]] {
+		delete(p.done, p.pending[0])
+		p.attached = p.pending[0] + 1
+		p.pending = p.pending[1:]
+	}
+}
+
+// Committable lists the next offset to consume of the partitions with events
+// attached since the last commit.
+func (o *offsetTracker) Committable() []kafka.TopicPartition {
+	o.Lock()
+	defer o.Unlock()
+
+	offsets := make([]kafka.TopicPartition, 0)
+	for key, p := range o.partitions {
+		if p.attached > p.committed {
+			topic := key.topic
+			offsets = append(offsets, kafka.TopicPartition{Topic: &topic, Partition: key.partition, Offset: p.attached})
+		}
+	}
+
+	sort.Slice(offsets, func(i, j int) bool {
+		if *offsets[i].Topic != *offsets[j].Topic {
+			return *offsets[i].Topic < *offsets[j].Topic
+		}
+		return offsets[i].Partition < offsets[j].Partition
+	})
+
+	return offsets
+}
+
+func (o *offsetTracker) Committed(offsets []kafka.TopicPartition) {
+	o.Lock()
+	defer o.Unlock()
+
+	for _, tp := range offsets {
+		if p, ok := o.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]; ok && tp.Offset > p.committed {
+			p.committed = tp.Offset
+		}
+	}
+}
+
+// Reset forgets every partition, once they were revoked.
+func (o *offsetTracker) Reset() {
+	o.Lock()
+	defer o.Unlock()
+
+	o.partitions = map[partitionKey]*partitionOffsets{}
+}
+
+func (o *offsetTracker) partition(tp kafka.TopicPartition) *partitionOffsets {
+	key := topicPartitionKey(tp)
+	p, ok := o.partitions[key]
+	if !ok {
+		p = &partitionOffsets{done: map[kafka.Offset]bool{}}
+		o.partitions[key] = p
+	}
+
+	return p
+}
+
+// validateKafkaWorkers accepts workers left unset, 0, as a single worker.
+func validateKafkaWorkers(workers int, dispatchBy string) error {
+	if workers < 0 {
+		return errors.Errorf("kafka source: workers must not be negative, got %d, leave it unset for a single worker", workers)
+	}
+
+	if dispatchBy != "" && dispatchBy != KafkaDispatchByPartition && dispatchBy != KafkaDispatchByKey {
+		return errors.Errorf("kafka source: invalid dispatchBy %s, expected one of: %s, %s", dispatchBy, KafkaDispatchByPartition, KafkaDispatchByKey)
+	}
+
+	return nil
+}
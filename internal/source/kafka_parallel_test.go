@@ -0,0 +1,108 @@
+package source
+
+import (
+	"strings"
+	"testing"
+
+	codec "draethos.io.com/internal/codec"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/pkg/errors"
+)
+
+func TestShouldCommitOffsetsOnlyUpToFirstPendingEvent(t *testing.T) {
+	topic := "orders"
+	event := func(partition int32, offset kafka.Offset) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: offset}
+	}
+
+	tracker := newOffsetTracker()
+	for offset := kafka.Offset(10); offset < 13; offset++ {
+		tracker.Dispatched(event(0, offset))
+	}
+	tracker.Dispatched(event(1, 5))
+
+	tracker.Done(event(0, 11))
+	tracker.Done(event(1, 5))
+
+	offsets := tracker.Committable()
+	if len(offsets) != 1 || offsets[0].Partition != 1 || offsets[0].Offset != 6 {
+		t.Fatalf("expected only partition 1 to be committable, got %v", offsets)
+	}
+	tracker.Committed(offsets)
+
+	tracker.Done(event(0, 10))
+	offsets = tracker.Committable()
+	if len(offsets) != 1 || offsets[0].Partition != 0 || offsets[0].Offset != 12 {
+		t.Fatalf("expected partition 0 up to offset 12, got %v", offsets)
+	}
+}
+
+func TestShouldDispatchEventsOfPartitionOrKeyToSameWorker(t *testing.T) {
+	topic := "orders"
+	dispatcher := &kafkaDispatcher{queues: make([]chan *kafka.Message, 4)}
+
+	first := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 6}, Key: []byte("a")}
+	second := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 6}, Key: []byte("b")}
+	if dispatcher.worker(first) != 2 || dispatcher.worker(second) != 2 {
+		t.Errorf("expected partition 6 on worker 2")
+	}
+
+	dispatcher.source.sourceSpec = specs.Source{SourceSpecs: specs.SourceSpecs{DispatchBy: KafkaDispatchByKey}}
+	other := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 1}, Key: []byte("a")}
+	if dispatcher.worker(first) != dispatcher.worker(other) {
+		t.Errorf("expected key a on the same worker whatever the partition")
+	}
+
+	if err := validateKafkaWorkers(2, "round-robin"); err == nil {
+		t.Errorf("expected an invalid dispatchBy to be rejected")
+	}
+
+	if err := validateKafkaWorkers(0, ""); err != nil {
+		t.Errorf("expected unset workers to be accepted, got %v", err)
+	}
+
+	if err := validateKafkaWorkers(-1, ""); err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Errorf("expected negative workers to be rejected, got %v", err)
+	}
+}
+
+// failingTarget rejects every event attached.
+type failingTarget struct {
+	collectTarget
+}
+
+func (f *failingTarget) CanFlush() bool { return false }
+
+func (f *failingTarget) Attach(string, map[string]interface{}) error {
+	return errors.Errorf("unavailable")
+}
+
+func TestShouldStopWorkersWhenDlqFails(t *testing.T) {
+	topic := "orders"
+	msg := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 10}, Value: []byte(`{"id":1}`)}
+
+	for _, dlq := range []interfaces.TargetInterface{nil, &failingTarget{}} {
+		source := kafkaSource{sourceSpec: specs.Source{Type: "kafka"}, target: &failingTarget{}, dlq: dlq, codec: codec.NewJsonCodec()}
+
+		dispatcher := newKafkaDispatcher(source, nil)
+		dispatcher.offsets.Dispatched(msg.TopicPartition)
+		dispatcher.inflight.Add(1)
+		dispatcher.workers.Add(1)
+
+		queue := make(chan *kafka.Message, 1)
+		queue <- msg
+		close(queue)
+		dispatcher.work(0, queue)
+
+		committable := dispatcher.offsets.Committable()
+		if dlq == nil && (dispatcher.err != nil || len(committable) != 1) {
+			t.Errorf("expected event without dlq to be skipped, got error %v and offsets %v", dispatcher.err, committable)
+		}
+
+		if dlq != nil && (dispatcher.err == nil || len(committable) != 0) {
+			t.Errorf("expected dlq failure to stop workers before committing, got error %v and offsets %v", dispatcher.err, committable)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package source
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	KafkaStartEarliest = "earliest"
+	KafkaStartLatest   = "latest"
+
+	kafkaOffsetsForTimesTimeoutMs = 10000
+)
+
+// kafkaPosition moves the partitions to the start position informed the first
+// time they are assigned, later assignments resume from the committed
+// offsets, and tells when every partition reached the stop time.
+type kafkaPosition struct {
+	sync.Mutex
+	start      kafka.Offset
+	startAt    time.Time
+	stopAt     time.Time
+	offsets    map[string]kafka.Offset
+	positioned map[partitionKey]bool
+	assigned   map[partitionKey]bool
+	stopped    map[partitionKey]kafka.Offset
+}
+
+func newKafkaPosition(sourceSpecs specs.SourceSpecs, now time.Time) (*kafkaPosition, error) {
+	position := &kafkaPosition{
+		offsets:    map[string]kafka.Offset{},
+		positioned: map[partitionKey]bool{},
+		assigned:   map[partitionKey]bool{},
+		stopped:    map[partitionKey]kafka.Offset{},
+	}
+
+	switch startAt := strings.TrimSpace(sourceSpecs.StartAt); startAt {
+	case "":
+	case KafkaStartEarliest:
+		position.start = kafka.OffsetBeginning
+	case KafkaStartLatest:
+		position.start = kafka.OffsetEnd
+	default:
+		parsed, err := specs.ParseTime(startAt, now)
+		if err != nil {
+			return nil, errors.Errorf("kafka source: startAt: %s", err.Error())
+		}
+		position.startAt = parsed
+	}
+
+	if sourceSpecs.StopAt != "" {
+		parsed, err := specs.ParseTime(sourceSpecs.StopAt, now)
+		if err != nil {
+			return nil, errors.Errorf("kafka source: stopAt: %s", err.Error())
+		}
+		position.stopAt = parsed
+	}
+
+	for key, offset := range sourceSpecs.Offsets {
+		partition := key
+		if index := strings.LastIndex(key, ":"); index >= 0 {
+			partition = key[index+1:]
+		}
+
+		if _, err := strconv.ParseInt(partition, 10, 32); err != nil {
+			return nil, errors.Errorf("kafka source: invalid offsets key %s, expected a partition such as 0 or topic:partition such as orders:0", key)
+		}
+
+		if offset < 0 {
+			return nil, errors.Errorf("kafka source: invalid offset %d for partition %s", offset, key)
+		}
+
+		position.offsets[key] = kafka.Offset(offset)
+	}
+
+	return position, nil
+}
+
+// Assign returns the partitions with the offsets they start from, partitions
+// assigned before, or without a start position, keep the committed offset.
+func (p *kafkaPosition) Assign(consumer *kafka.Consumer, partitions []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	assigned := make([]kafka.TopicPartition, len(partitions))
+	lookups := make([]kafka.TopicPartition, 0)
+	lookupIndexes := make([]int, 0)
+	for i, tp := range partitions {
+		key := topicPartitionKey(tp)
+		p.assigned[key] = true
+		assigned[i] = tp
+
+		if p.positioned[key] {
+			continue
+		}
+		p.positioned[key] = true
+
+		switch offset, ok := p.offset(key); {
+		case ok:
+			assigned[i].Offset = offset
+		case p.start != 0:
+			assigned[i].Offset = p.start
+		case !p.startAt.IsZero():
+			lookup := tp
+			lookup.Offset = kafka.Offset(p.startAt.UnixNano() / int64(time.Millisecond))
+			lookups = append(lookups, lookup)
+			lookupIndexes = append(lookupIndexes, i)
+		}
+	}
+
+	if len(lookups) > 0 {
+		resolved, err := consumer.OffsetsForTimes(lookups, kafkaOffsetsForTimesTimeoutMs)
+		if err != nil {
+			return nil, errors.Errorf("failed to resolve offsets at %s: %s", p.startAt.Format(time.RFC3339), err.Error())
+		}
+
+		for i, tp := range resolved {
+			// partitions without events after the time start from their end
+			offset := tp.Offset
+			if tp.Error != nil || offset < 0 {
+				offset = kafka.OffsetEnd
+			}
+			assigned[lookupIndexes[i]].Offset = offset
+		}
+	}
+
+	for _, tp := range assigned {
+		if tp.Offset != kafka.OffsetInvalid {
+			zap.S().Infof("partition %s[%d] starting at offset %s", topicName(tp), tp.Partition, tp.Offset)
+		}
+	}
+
+	return assigned, nil
+}
+
+func (p *kafkaPosition) Revoke(partitions []kafka.TopicPartition) {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, tp := range partitions {
+		delete(p.assigned, topicPartitionKey(tp))
+	}
+}
+
+// Reached tells whether the event is past the stop time, or from a partition
+// already stopped.
+func (p *kafkaPosition) Reached(msg *kafka.Message) bool {
+	if p.stopAt.IsZero() {
+		return false
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	key := topicPartitionKey(msg.TopicPartition)
+	if _, ok := p.stopped[key]; ok {
+		return true
+	}
+
+	if msg.TimestampType == kafka.TimestampNotAvailable || msg.Timestamp.Before(p.stopAt) {
+		return false
+	}
+
+	p.stopped[key] = msg.TopicPartition.Offset
+	zap.S().Infof("partition %s[%d] reached stopAt %s at offset %s", key.topic, key.partition, p.stopAt.Format(time.RFC3339), msg.TopicPartition.Offset)
+
+	return true
+}
+
+// EOF stops the partition when it has no more events and the stop time has
+// already passed.
+func (p *kafkaPosition) EOF(tp kafka.TopicPartition) {
+	if p.stopAt.IsZero() || time.Now().Before(p.stopAt) {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	key := topicPartitionKey(tp)
+	if _, ok := p.stopped[key]; !ok {
+		p.stopped[key] = tp.Offset
+		zap.S().Infof("partition %s[%d] reached its end before stopAt %s", key.topic, key.partition, p.stopAt.Format(time.RFC3339))
+	}
+}
+
+// Finished tells whether every partition assigned stopped.
+func (p *kafkaPosition) Finished() bool {
+	if p.stopAt.IsZero() {
+		return false
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if len(p.assigned) == 0 {
+		return false
+	}
+
+	for key := range p.assigned {
+		if _, ok := p.stopped[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Stopped lists the next offset to be consumed of the partitions assigned
+// that stopped.
+func (p *kafkaPosition) Stopped() []kafka.TopicPartition {
+	p.Lock()
+	defer p.Unlock()
+
+	positions := make([]kafka.TopicPartition, 0, len(p.stopped))
+	for key, offset := range p.stopped {
+		if !p.assigned[key] {
+			continue
+		}
+
+		topic := key.topic
+		positions = append(positions, kafka.TopicPartition{Topic: &topic, Partition: key.partition, Offset: offset})
+	}
+
+	return positions
+}
+
+func (p *kafkaPosition) offset(key partitionKey) (kafka.Offset, bool) {
+	if offset, ok := p.offsets[fmt.Sprintf("%s:%d", key.topic, key.partition)]; ok {
+		return offset, true
+	}
+
+	offset, ok := p.offsets[strconv.Itoa(int(key.partition))]
+	return offset, ok
+}
+
+func topicPartitionKey(tp kafka.TopicPartition) partitionKey {
+	return partitionKey{topic: topicName(tp), partition: tp.Partition}
+}
+
+func topicName(tp kafka.TopicPartition) string {
+	if tp.Topic == nil {
+		return ""
+	}
+
+	return *tp.Topic
+}
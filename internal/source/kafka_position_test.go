@@ -0,0 +1,97 @@
+package source
+
+import (
+	"testing"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+func TestShouldStartPartitionsOnlyOnFirstAssignment(t *testing.T) {
+	topic := "orders"
+	position, err := newKafkaPosition(specs.SourceSpecs{StartAt: "earliest", Offsets: map[string]int64{"orders:1": 1200}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partitions := []kafka.TopicPartition{
+		{Topic: &topic, Partition: 0, Offset: kafka.OffsetInvalid},
+		{Topic: &topic, Partition: 1, Offset: kafka.OffsetInvalid},
+	}
+
+	assigned, err := position.Assign(nil, partitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assigned[0].Offset != kafka.OffsetBeginning || assigned[1].Offset != 1200 {
+		t.Errorf("unexpected offsets: %v", assigned)
+	}
+
+	position.Revoke(partitions)
+	if assigned, _ = position.Assign(nil, partitions); assigned[0].Offset != kafka.OffsetInvalid || assigned[1].Offset != kafka.OffsetInvalid {
+		t.Errorf("expected committed offsets once reassigned, got %v", assigned)
+	}
+}
+
+func TestShouldFinishOnceEveryPartitionReachedStopAt(t *testing.T) {
+	topic := "orders"
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	position, err := newKafkaPosition(specs.SourceSpecs{StartAt: "2026-10-01T00:00:00Z", StopAt: "2h"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !position.startAt.Equal(now.Add(-12*time.Hour)) || !position.stopAt.Equal(now.Add(-2*time.Hour)) {
+		t.Fatalf("unexpected window: %s to %s", position.startAt, position.stopAt)
+	}
+
+	position.assigned[partitionKey{topic: topic, partition: 0}] = true
+	position.assigned[partitionKey{topic: topic, partition: 1}] = true
+
+	message := func(partition int32, offset kafka.Offset, timestamp time.Time) *kafka.Message {
+		return &kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: offset},
+			Timestamp:      timestamp,
+			TimestampType:  kafka.TimestampCreateTime,
+		}
+	}
+
+	if position.Reached(message(0, 10, now.Add(-3*time.Hour))) {
+		t.Errorf("expected event before stopAt to be processed")
+	}
+
+	if !position.Reached(message(0, 11, now.Add(-time.Hour))) || !position.Reached(message(0, 12, now.Add(-3*time.Hour))) {
+		t.Errorf("expected partition 0 to be stopped")
+	}
+
+	if position.Finished() {
+		t.Errorf("expected partition 1 to be running")
+	}
+
+	position.EOF(kafka.TopicPartition{Topic: &topic, Partition: 1, Offset: 40})
+	stopped := position.Stopped()
+	if !position.Finished() || len(stopped) != 2 {
+		t.Errorf("expected every partition to be stopped, got %v", stopped)
+	}
+
+	for _, tp := range stopped {
+		if tp.Partition == 0 && tp.Offset != 11 || tp.Partition == 1 && tp.Offset != 40 {
+			t.Errorf("unexpected stop position %v", tp)
+		}
+	}
+}
+
+func TestShouldRejectInvalidPositions(t *testing.T) {
+	for _, sourceSpecs := range []specs.SourceSpecs{
+		{StartAt: "yesterday"},
+		{StopAt: "2026-13-01"},
+		{Offsets: map[string]int64{"orders": 10}},
+		{Offsets: map[string]int64{"0": -1}},
+	} {
+		if _, err := newKafkaPosition(sourceSpecs, time.Now()); err == nil {
+			t.Errorf("expected %+v to be rejected", sourceSpecs)
+		}
+	}
+}
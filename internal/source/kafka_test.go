@@ -0,0 +1,36 @@
+package source
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShouldSubscribeTopicsAndPattern(t *testing.T) {
+	topics, err := kafkaTopics("orders, payments", "orders\\..*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(topics, []string{"orders", "payments", "^orders\\..*"}) {
+		t.Errorf("unexpected topics: %v", topics)
+	}
+
+	if _, err = kafkaTopics("", ""); err == nil {
+		t.Errorf("expected an error without topic and pattern")
+	}
+
+	if _, err = kafkaTopics("", "^orders.(*"); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+}
+
+func TestShouldWriteOriginatingTopic(t *testing.T) {
+	payload := withTopic(map[string]interface{}{"id": 1}, "topic", "orders.eu")
+	if payload["topic"] != "orders.eu" || payload["id"] != 1 {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+
+	if payload = withTopic(nil, "topic", "orders.eu"); payload["topic"] != "orders.eu" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+}
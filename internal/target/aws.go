@@ -0,0 +1,84 @@
+package target
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	AwsDefaultRegion  = "us-east-1"
+	AwsDefaultProfile = "default"
+)
+
+type awsConfigurations struct {
+	Region         string `config:"aws.region"`
+	Profile        string `config:"aws.profile"`
+	AccessKey      string `config:"aws.access.key"`
+	SecretKey      string `config:"aws.secret.key"`
+	CredentialFile string `config:"aws.credential.file"`
+}
+
+func newAwsConfigurations(configurations map[string]interface{}) (awsConfigurations, error) {
+	awsConfig := awsConfigurations{
+		Region:  AwsDefaultRegion,
+		Profile: AwsDefaultProfile,
+	}
+
+	if err := specs.DecodeConfigurations(configurations, &awsConfig); err != nil {
+		return awsConfig, err
+	}
+
+	return awsConfig, nil
+}
+
+// NewAwsSession prefers static keys, then the shared credential file and
+// finally the default aws credential chain.
+func NewAwsSession(configurations map[string]interface{}) (*session.Session, error) {
+	awsConfig, err := newAwsConfigurations(configurations)
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *credentials.Credentials
+	if awsConfig.CredentialFile != "" {
+		if _, err := os.Stat(awsConfig.CredentialFile); err != nil {
+			zap.S().Debugf("aws_credentials_file %s not found", awsConfig.CredentialFile)
+		} else {
+			cred = credentials.NewSharedCredentials(awsConfig.CredentialFile, awsConfig.Profile)
+		}
+	}
+
+	if awsConfig.AccessKey != "" && awsConfig.SecretKey != "" {
+		cred = credentials.NewStaticCredentials(awsConfig.AccessKey, awsConfig.SecretKey, "")
+	}
+
+	sess, err := session.NewSession(
+		&aws.Config{
+			Region:      aws.String(awsConfig.Region),
+			Credentials: cred,
+		})
+	if err != nil {
+		return nil, errors.Errorf("failed to create aws session: %s", err.Error())
+	}
+
+	return sess, nil
+}
+
+// compactPayload removes the indentation of json payloads, payloads of any
+// other codec are queued as they are.
+func compactPayload(payload []byte) []byte {
+	buffer := new(bytes.Buffer)
+	if err := json.Compact(buffer, payload); err != nil {
+		return payload
+	}
+
+	return buffer.Bytes()
+}
@@ -0,0 +1,156 @@
+package target
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	PgSqlCreateTableTemplate = "CREATE TABLE IF NOT EXISTS %s (\n%s,\n  PRIMARY KEY (%s)\n);\n"
+	MySqlCreateTableTemplate = "CREATE TABLE IF NOT EXISTS %s (\n%s,\n  PRIMARY KEY (%s)\n);\n"
+	KeyColumnDefinition      = "varchar(90) NOT NULL"
+)
+
+var (
+	datePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]))`),
+	}
+
+	dateTimePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T(0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]).(0[0-9]\d|3[01]))`),
+		regexp.MustCompile(`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T(0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]))`),
+		regexp.MustCompile(`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]) (0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]).(0[0-9]\d|3[01]))`),
+		regexp.MustCompile(`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]) (0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]))`),
+	}
+)
+
+// Column is a field seen in an event, Value is used to pick the column type
+// the same way the sql targets do when the column is created on demand.
+type Column struct {
+	Name  string
+	Value interface{}
+}
+
+// PgsqlColumnDefinition returns the type and constraints used by the pgsql
+// target when it adds a column for the value.
+func PgsqlColumnDefinition(value interface{}, key bool) string {
+	varcharDefault, numberDefault := columnDefaults(key)
+
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("INT %s", numberDefault)
+	case float32, float64:
+		// decoded json and csv numbers, whole or not, stored without rounding
+		return fmt.Sprintf("NUMERIC %s", numberDefault)
+	case bool:
+		return "BOOL NOT NULL DEFAULT false"
+	case map[string]interface{}, []interface{}:
+		return "JSONB NULL"
+	}
+
+	return fmt.Sprintf("%s %s", stringColumnType(value, "DATE", "TIMESTAMP"), varcharDefault)
+}
+
+// MysqlColumnDefinition returns the type and constraints used by the mysql
+// target when it adds a column for the value.
+func MysqlColumnDefinition(value interface{}, key bool) string {
+	varcharDefault, numberDefault := columnDefaults(key)
+
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("INT %s", numberDefault)
+	case float32, float64:
+		return fmt.Sprintf("DOUBLE %s", numberDefault)
+	case bool:
+		return "BOOL NOT NULL DEFAULT false"
+	case map[string]interface{}, []interface{}:
+		return "JSON NULL"
+	}
+
+	return fmt.Sprintf("%s %s", stringColumnType(value, "DATETIME", "DATETIME"), varcharDefault)
+}
+
+// PgsqlCreateTable renders the table the pgsql target builds incrementally
+// for the columns informed.
+func PgsqlCreateTable(table string, keyColumn string, columns []Column) string {
+	return createTable(PgSqlCreateTableTemplate, "%s", `"%s"`, table, keyColumn, columns, PgsqlColumnDefinition)
+}
+
+// MysqlCreateTable renders the table the mysql target builds incrementally
+// for the columns informed.
+func MysqlCreateTable(table string, keyColumn string, columns []Column) string {
+	return createTable(MySqlCreateTableTemplate, "`%s`", "`%s`", table, keyColumn, columns, MysqlColumnDefinition)
+}
+
+func createTable(template string, keyQuote string, quote string, table string, keyColumn string, columns []Column,
+	definition func(value interface{}, key bool) string) string {
+	key := fmt.Sprintf(keyQuote, keyColumn)
+	lines := []string{fmt.Sprintf("  %s %s", key, KeyColumnDefinition)}
+	for _, column := range columns {
+		if column.Name == keyColumn {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s %s", fmt.Sprintf(quote, column.Name), definition(column.Value, false)))
+	}
+
+	return fmt.Sprintf(template, table, strings.Join(lines, ",\n"), key)
+}
+
+func columnDefaults(key bool) (string, string) {
+	if key {
+		return "NOT NULL", "NOT NULL"
+	}
+
+	return "NULL", "NOT NULL DEFAULT 0"
+}
+
+func stringColumnType(value interface{}, dateType string, dateTimeType string) string {
+	switch {
+	case fieldIsText(value):
+		return "TEXT"
+	case fieldIsDateTime(value):
+		return dateTimeType
+	case fieldIsDate(value):
+		return dateType
+	}
+
+	return "VARCHAR(255)"
+}
+
+func fieldIsText(value interface{}) bool {
+	v, _ := value.(string)
+
+	return len(v) > 255
+}
+
+func fieldIsDate(value interface{}) bool {
+	return matchAny(datePatterns, value)
+}
+
+// sqlLiteral renders numbers and booleans as they are written in sql, the
+// second value tells whether the value is one of them.
+func sqlLiteral(value interface{}) (string, bool) {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return fmt.Sprintf("%v", value), true
+	}
+
+	return "", false
+}
+
+func fieldIsDateTime(value interface{}) bool {
+	return matchAny(dateTimePatterns, value)
+}
+
+func matchAny(patterns []*regexp.Regexp, value interface{}) bool {
+	v, _ := value.(string)
+	for _, pattern := range patterns {
+		if pattern.MatchString(v) {
+			return true
+		}
+	}
+
+	return false
+}
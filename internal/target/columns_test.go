@@ -0,0 +1,85 @@
+package target
+
+import (
+	"container/list"
+	"strings"
+	"testing"
+
+	"draethos.io.com/pkg/streams/specs"
+)
+
+func TestShouldRenderNumbersAndBooleansAsSqlLiterals(t *testing.T) {
+	event := map[string]interface{}{
+		"id":      "o-1",
+		"count":   int64(-3),
+		"counter": uint64(18446744073709551615),
+		"ratio":   1.5,
+		"small":   float32(0.25),
+		"paid":    true,
+	}
+	expected := map[string]string{
+		"count":   "-3",
+		"counter": "18446744073709551615",
+		"ratio":   "1.5",
+		"small":   "0.25",
+		"paid":    "true",
+	}
+
+	databaseSpecs := specs.DatabaseTargetSpecs{Table: "orders", KeyColumnName: "id"}
+
+	// columns already known are not looked up on the database
+	mysql := &mysqlTarget{specs: databaseSpecs, columns: map[string][]string{}}
+	for column := range event {
+		mysql.columns["orders"] = append(mysql.columns["orders"], column)
+	}
+
+	values, err := mysql.buildCommands(list.Element{Value: databaseRow{table: "orders", data: event}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for column, literal := range expected {
+		if values[column] != literal {
+			t.Errorf("mysql %s: expected %s, got %s", column, literal, values[column])
+		}
+	}
+
+	pgsql := &pgsqlTarget{specs: databaseSpecs, columns: map[string]map[string]bool{"orders": {}}}
+	for column := range event {
+		pgsql.columns["orders"][column] = true
+	}
+
+	var commands strings.Builder
+	if err = pgsql.buildCommands(&commands, list.Element{Value: databaseRow{table: "orders", data: event}}); err != nil {
+		t.Fatal(err)
+	}
+
+	insert := commands.String()
+	for column, literal := range expected {
+		if !strings.Contains(insert, literal) {
+			t.Errorf("pgsql %s: expected %s in %s", column, literal, insert)
+		}
+	}
+}
+
+func TestShouldKeepDecimalsOfFloatColumns(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		pgsql string
+		mysql string
+	}{
+		{12.5, "NUMERIC NOT NULL DEFAULT 0", "DOUBLE NOT NULL DEFAULT 0"},
+		{float32(0.125), "NUMERIC NOT NULL DEFAULT 0", "DOUBLE NOT NULL DEFAULT 0"},
+		{int64(12), "INT NOT NULL DEFAULT 0", "INT NOT NULL DEFAULT 0"},
+	}
+
+	for _, test := range tests {
+		if definition := PgsqlColumnDefinition(test.value, false); definition != test.pgsql {
+			t.Errorf("pgsql %v: expected %s, got %s", test.value, test.pgsql, definition)
+		}
+
+		if definition := MysqlColumnDefinition(test.value, false); definition != test.mysql {
+			t.Errorf("mysql %v: expected %s, got %s", test.value, test.mysql, definition)
+		}
+	}
+}
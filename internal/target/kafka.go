@@ -6,6 +6,7 @@ import (
 	"draethos.io.com/pkg/streams/specs"
 	"fmt"
 	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"sync"
 )
@@ -13,6 +14,7 @@ import (
 type kafkaTarget struct {
 	sync.Mutex
 	targetSpec specs.Target
+	specs      specs.KafkaTargetSpecs
 	codec      interfaces.CodecInterface
 	configMap  kafka.ConfigMap
 	producer   *kafka.Producer
@@ -20,7 +22,12 @@ type kafkaTarget struct {
 }
 
 func NewKafkaTarget(targetSpec specs.Target, codec interfaces.CodecInterface) (*kafkaTarget, error) {
-	return &kafkaTarget{targetSpec: targetSpec, codec: codec, configMap: kafka.ConfigMap{
+	targetSpecs, err := targetSpec.TargetSpecs.Kafka()
+	if err != nil {
+		return nil, errors.Errorf("kafka target: %s", err.Error())
+	}
+
+	return &kafkaTarget{targetSpec: targetSpec, specs: targetSpecs, codec: codec, configMap: kafka.ConfigMap{
 		"message.send.max.retries": 10000000,
 		"enable.idempotence":       true,
 	}, queue: list.New()}, nil
@@ -50,7 +57,7 @@ func (k *kafkaTarget) Attach(_ string, data map[string]interface{}) error {
 }
 
 func (k *kafkaTarget) CanFlush() bool {
-	return k.queue.Len() >= k.targetSpec.TargetSpecs.BatchSize
+	return k.queue.Len() >= k.specs.BatchSize
 }
 
 func (k *kafkaTarget) Flush() error {
@@ -80,7 +87,7 @@ func (k *kafkaTarget) Flush() error {
 
 		err = k.producer.Produce(&kafka.Message{
 			TopicPartition: kafka.TopicPartition{
-				Topic:     &k.targetSpec.TargetSpecs.Topic,
+				Topic:     &k.specs.Topic,
 				Partition: kafka.PartitionAny,
 			},
 			Value: content,
@@ -98,6 +105,10 @@ func (k *kafkaTarget) Flush() error {
 }
 
 func (k *kafkaTarget) Close() error {
+	if k.producer == nil {
+		return nil
+	}
+
 	k.producer.Close()
 	return nil
 }
@@ -7,8 +7,6 @@ import (
 	"draethos.io.com/internal/interfaces"
 	"encoding/json"
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,18 +18,11 @@ import (
 )
 
 const (
-	MySqlInsertTemplate                      = "REPLACE INTO %s (%s) values %s;\n"
-	MySqlAlterTableAddPrimaryKeyTemplate     = "CREATE TABLE IF NOT EXISTS %s (%s varchar(90) NOT NULL, PRIMARY KEY (%s));\n"
-	MySqlAlterTableAddUniqueKeyColumn        = "ALTER TABLE %s ADD UNIQUE(%s);\n"
-	MySqlAlterTableAddColumnVarcharTemplate  = "ALTER TABLE %s ADD COLUMN %s VARCHAR(255) %s;\n"
-	MySqlAlterTableAddColumnTextTemplate     = "ALTER TABLE %s ADD COLUMN %s TEXT %s;\n"
-	MySqlAlterTableAddColumnIntTemplate      = "ALTER TABLE %s ADD COLUMN %s INT %s;\n"
-	MySqlAlterTableAddColumnDateTemplate     = "ALTER TABLE %s ADD COLUMN %s DATETIME %s;\n"
-	MySqlAlterTableAddColumnDateTimeTemplate = "ALTER TABLE %s ADD COLUMN %s DATETIME %s;\n"
-	MySqlAlterTableAddColumnNumericTemplate  = "ALTER TABLE %s ADD COLUMN %s NUMERIC(12,2) %s;\n"
-	MySqlAlterTableAddColumnBoolTemplate     = "ALTER TABLE %s ADD COLUMN %s BOOL NOT NULL DEFAULT false;\n"
-	MySqlAlterTableAddColumnJsonbTemplate    = "ALTER TABLE %s ADD COLUMN %s JSON NULL;\n"
-	MySqlVerifyHasColumn                     = "SELECT count(1) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME='%s' AND COLUMN_NAME='%s'"
+	MySqlInsertTemplate                  = "REPLACE INTO %s (%s) values %s;\n"
+	MySqlAlterTableAddPrimaryKeyTemplate = "CREATE TABLE IF NOT EXISTS %s (%s varchar(90) NOT NULL, PRIMARY KEY (%s));\n"
+	MySqlAlterTableAddUniqueKeyColumn    = "ALTER TABLE %s ADD UNIQUE(%s);\n"
+	MySqlAlterTableAddColumnTemplate     = "ALTER TABLE %s ADD COLUMN %s %s;\n"
+	MySqlVerifyHasColumn                 = "SELECT count(1) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME='%s' AND COLUMN_NAME='%s'"
 )
 
 type mysqlTarget struct {
@@ -39,43 +30,60 @@ type mysqlTarget struct {
 	targetSpec specs.Target
 	codec      interfaces.CodecInterface
 	queue      *list.List
-	columns    []string
+	columns    map[string][]string
 	db         *sql.DB
+	config     mysqlConfigurations
+	specs      specs.DatabaseTargetSpecs
+}
+
+type mysqlConfigurations struct {
+	Host     string `config:"host"`
+	Port     int    `config:"port"`
+	User     string `config:"user"`
+	Password string `config:"password"`
 }
 
 func NewMysqlTarget(targetSpec specs.Target, codec interfaces.CodecInterface) (*mysqlTarget, error) {
+	config := mysqlConfigurations{Port: 3306}
+	if err := specs.DecodeConfigurations(targetSpec.TargetSpecs.Configurations, &config); err != nil {
+		return nil, errors.Errorf("mysql target: %s", err.Error())
+	}
+
+	targetSpecs, err := targetSpec.TargetSpecs.Database()
+	if err != nil {
+		return nil, errors.Errorf("mysql target: %s", err.Error())
+	}
+
 	return &mysqlTarget{
+		config:     config,
+		specs:      targetSpecs,
 		targetSpec: targetSpec,
 		codec:      codec,
 		queue:      list.New(),
-		columns:    make([]string, 0),
+		columns:    map[string][]string{},
 	}, nil
 }
 
 func (p *mysqlTarget) Initialize() error {
-	if _, ok := p.targetSpec.TargetSpecs.Configurations["host"].(string); !ok {
+	if p.config.Host == "" {
 		return errors.Errorf("target host not defined")
 	}
 
-	if _, ok := p.targetSpec.TargetSpecs.Configurations["user"].(string); !ok {
+	if p.config.User == "" {
 		return errors.Errorf("target user not defined")
 	}
 
-	if _, ok := p.targetSpec.TargetSpecs.Configurations["password"].(string); !ok {
+	if p.config.Password == "" {
 		return errors.Errorf("target password not defined")
 	}
 
-	if p.targetSpec.TargetSpecs.KeyColumnName == "" {
-		p.targetSpec.TargetSpecs.KeyColumnName = "id"
-	}
-
 	db, err := sql.Open("mysql",
 		fmt.Sprintf("%v:%v@tcp(%v:%v)/%v",
-			p.targetSpec.TargetSpecs.Configurations["user"],
-			p.targetSpec.TargetSpecs.Configurations["password"],
-			p.targetSpec.TargetSpecs.Configurations["host"],
-			p.targetSpec.TargetSpecs.Configurations["port"],
-			p.targetSpec.TargetSpecs.Database))
+			p.config.User,
+			p.config.Password,
+			p.config.Host,
+			p.config.Port,
+			p.specs.Database))
 
 	if err != nil {
 		return errors.Errorf("failed to connect target pgsql: %s", err.Error())
@@ -83,20 +91,27 @@ func (p *mysqlTarget) Initialize() error {
 
 	p.db = db
 
+	// tables named after the topic are created along with their first events
+	if strings.Contains(p.specs.Table, TopicPlaceholder) {
+		return nil
+	}
+
 	if _, err := p.db.Exec(
 		fmt.Sprintf(
 			MySqlAlterTableAddPrimaryKeyTemplate,
-			p.targetSpec.TargetSpecs.Table,
-			p.targetSpec.TargetSpecs.KeyColumnName,
-			p.targetSpec.TargetSpecs.KeyColumnName)); err != nil {
+			p.specs.Table,
+			p.specs.KeyColumnName,
+			p.specs.KeyColumnName)); err != nil {
 		return errors.Errorf("failed to initialize table %s: %s",
-			p.targetSpec.TargetSpecs.Table,
+			p.specs.Table,
 			err.Error())
 	}
 
 	zap.S().Infof("initialize target table %s with primary key %s",
-		p.targetSpec.TargetSpecs.Table,
-		p.targetSpec.TargetSpecs.KeyColumnName)
+		p.specs.Table,
+		p.specs.KeyColumnName)
+
+	p.columns[p.specs.Table] = make([]string, 0)
 
 	return nil
 }
@@ -105,11 +120,16 @@ func (p *mysqlTarget) Attach(key string, data map[string]interface{}) error {
 	p.Lock()
 	defer p.Unlock()
 
+	topic, err := eventTopic(p.specs.Table, p.specs.TopicField, data)
+	if err != nil {
+		return err
+	}
+
 	if key != "" {
-		data[p.targetSpec.TargetSpecs.KeyColumnName] = key
+		data[p.specs.KeyColumnName] = key
 	}
 
-	p.queue.PushBack(data)
+	p.queue.PushBack(databaseRow{table: topicTable(p.specs.Table, topic), data: data})
 
 	return nil
 }
@@ -118,7 +138,7 @@ func (p *mysqlTarget) CanFlush() bool {
 	p.Lock()
 	defer p.Unlock()
 
-	return p.queue.Len() >= p.targetSpec.TargetSpecs.BatchSize
+	return p.queue.Len() >= p.specs.BatchSize
 }
 
 func (p *mysqlTarget) Flush() error {
@@ -131,43 +151,54 @@ func (p *mysqlTarget) Flush() error {
 
 	zap.S().Infof("flush %v events", p.queue.Len())
 
-	rows := make([]map[string]string, 0)
+	tables := make([]string, 0)
+	rows := make(map[string][]map[string]string)
 	elementLen := p.queue.Len()
 	for i := 0; i <= elementLen; i++ {
 		if element := p.queue.Front(); element != nil {
-			columns, _ := p.buildCommands(*element)
+			columns, err := p.buildCommands(*element)
 			p.queue.Remove(element)
-			rows = append(rows, columns)
+			if err != nil {
+				return err
+			}
+
+			table := element.Value.(databaseRow).table
+			if _, ok := rows[table]; !ok {
+				tables = append(tables, table)
+			}
+			rows[table] = append(rows[table], columns)
 		}
 	}
 
-	inserts := make([]string, 0)
-	for _, row := range rows {
-		values := make([]string, 0)
-		for _, field := range p.columns {
-			if row[field] == "" {
-				row[field] = "NULL"
+	for _, table := range tables {
+		inserts := make([]string, 0)
+		for _, row := range rows[table] {
+			values := make([]string, 0)
+			for _, field := range p.columns[table] {
+				if row[field] == "" {
+					row[field] = "NULL"
+				}
+
+				values = append(values, row[field])
 			}
 
-			values = append(values, row[field])
+			inserts = append(inserts, fmt.Sprintf("(%s)", strings.Join(values, ",")))
 		}
 
-		inserts = append(inserts, fmt.Sprintf("(%s)", strings.Join(values, ",")))
-	}
-
-	if _, err := p.db.Exec(fmt.Sprintf(
-		MySqlInsertTemplate,
-		p.targetSpec.TargetSpecs.Table,
-		strings.Join(p.columns, ","),
-		strings.Join(inserts, ","))); err != nil {
-		return err
+		if _, err := p.db.Exec(fmt.Sprintf(
+			MySqlInsertTemplate,
+			table,
+			strings.Join(p.columns[table], ","),
+			strings.Join(inserts, ","))); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (p *mysqlTarget) containColumn(key string) *int {
-	for k, v := range p.columns {
+func (p *mysqlTarget) containColumn(table string, key string) *int {
+	for k, v := range p.columns[table] {
 		if key == v {
 			return &k
 		}
@@ -176,11 +207,11 @@ func (p *mysqlTarget) containColumn(key string) *int {
 	return nil
 }
 
-func (p *mysqlTarget) hasColumn(column string) (bool, error) {
+func (p *mysqlTarget) hasColumn(table string, column string) (bool, error) {
 	var qtdRows = 0
 	err := p.db.QueryRow(fmt.Sprintf(
 		MySqlVerifyHasColumn,
-		p.targetSpec.TargetSpecs.Table, column)).Scan(&qtdRows)
+		table, column)).Scan(&qtdRows)
 	if err != nil {
 		return false, err
 	}
@@ -192,237 +223,63 @@ func (p *mysqlTarget) hasColumn(column string) (bool, error) {
 	return false, nil
 }
 
-func (p *mysqlTarget) fieldIsText(value interface{}) bool {
-	v, _ := value.(string)
-
-	return len(v) > 255
-}
-
-func (p *mysqlTarget) fieldIsDate(value interface{}) bool {
-	for _, pattern := range []string{
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]))`,
-	} {
-		r, _ := regexp.Compile(pattern)
-		v, _ := value.(string)
-
-		if r.MatchString(v) {
-			return true
-		}
+func (p *mysqlTarget) buildCommands(element list.Element) (map[string]string, error) {
+	row, ok := element.Value.(databaseRow)
+	if !ok {
+		return nil, errors.Errorf("failed to convert content list")
 	}
 
-	return false
-}
-
-func (p *mysqlTarget) fieldIsDateTime(value interface{}) bool {
-	for _, pattern := range []string{
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T(0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]).(0[0-9]\d|3[01]))`,
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T(0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]))`,
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]) (0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]).(0[0-9]\d|3[01]))`,
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]) (0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]))`,
-	} {
-		r, _ := regexp.Compile(pattern)
-		v, _ := value.(string)
-
-		if r.MatchString(v) {
-			return true
+	if _, ok := p.columns[row.table]; !ok {
+		if _, err := p.db.Exec(fmt.Sprintf(
+			MySqlAlterTableAddPrimaryKeyTemplate,
+			row.table,
+			p.specs.KeyColumnName,
+			p.specs.KeyColumnName)); err != nil {
+			return nil, errors.Errorf("failed to initialize table %s: %s", row.table, err.Error())
 		}
-	}
 
-	return false
-}
-
-func (p *mysqlTarget) buildCommands(element list.Element) (map[string]string, error) {
-	content, ok := element.Value.(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("failed to convert content list")
+		zap.S().Infof("initialize target table %s with primary key %s", row.table, p.specs.KeyColumnName)
 	}
 
 	values := make(map[string]string, 0)
-	if index := p.containColumn(p.targetSpec.TargetSpecs.KeyColumnName); index == nil {
-		p.columns = append(p.columns, p.targetSpec.TargetSpecs.KeyColumnName)
+	if index := p.containColumn(row.table, p.specs.KeyColumnName); index == nil {
+		p.columns[row.table] = append(p.columns[row.table], p.specs.KeyColumnName)
 	}
 
-	for k, v := range content {
-		if index := p.containColumn(k); index == nil {
-			fieldVarcharDefault := "NULL"
-			fieldNumberDefault := "NOT NULL DEFAULT 0"
-			if k == p.targetSpec.TargetSpecs.KeyColumnName {
-				fieldVarcharDefault = "NOT NULL"
-				fieldNumberDefault = "NOT NULL"
-			}
-
-			switch v.(type) {
-			case int:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnIntTemplate, p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnIntTemplate,
-						p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case int8:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnIntTemplate, p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnIntTemplate,
-						p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case int16:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnIntTemplate, p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnIntTemplate,
-						p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case int32:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnIntTemplate, p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnIntTemplate,
-						p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case int64:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnIntTemplate, p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnIntTemplate,
-						p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case float32:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnNumericTemplate, p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnNumericTemplate,
-						p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case float64:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnNumericTemplate, p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnNumericTemplate,
-						p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case bool:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnBoolTemplate, p.targetSpec.TargetSpecs.Table, k)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnBoolTemplate,
-						p.targetSpec.TargetSpecs.Table, k)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case map[string]interface{}:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					zap.S().Debugf(MySqlAlterTableAddColumnJsonbTemplate, p.targetSpec.TargetSpecs.Table, k)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnJsonbTemplate,
-						p.targetSpec.TargetSpecs.Table, k)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			case []interface{}:
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						MySqlAlterTableAddColumnJsonbTemplate,
-						p.targetSpec.TargetSpecs.Table, k)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
-				}
-			default:
-				alterTableAddColumnTemplate := MySqlAlterTableAddColumnVarcharTemplate
-
-				if p.fieldIsDate(v) {
-					alterTableAddColumnTemplate = MySqlAlterTableAddColumnDateTemplate
-				}
-
-				if p.fieldIsDateTime(v) {
-					alterTableAddColumnTemplate = MySqlAlterTableAddColumnDateTimeTemplate
-				}
-
-				if p.fieldIsText(v) {
-					alterTableAddColumnTemplate = MySqlAlterTableAddColumnTextTemplate
-				}
-
-				if exists, _ := p.hasColumn(k); !exists {
-					zap.S().Infof("column %s not found, running build script...", k)
-					if _, err := p.db.Exec(fmt.Sprintf(
-						alterTableAddColumnTemplate,
-						p.targetSpec.TargetSpecs.Table, k, fieldVarcharDefault)); err != nil {
-						zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
-					}
+	for k, v := range row.data {
+		if index := p.containColumn(row.table, k); index == nil {
+			if exists, _ := p.hasColumn(row.table, k); !exists {
+				definition := MysqlColumnDefinition(v, k == p.specs.KeyColumnName)
+
+				zap.S().Infof("column %s not found, running build script...", k)
+				zap.S().Debugf(MySqlAlterTableAddColumnTemplate, row.table, k, definition)
+				if _, err := p.db.Exec(fmt.Sprintf(
+					MySqlAlterTableAddColumnTemplate,
+					row.table, k, definition)); err != nil {
+					zap.S().Warnf("failed to create column %s: %s\n", k, err.Error())
 				}
 			}
 
-			if k == p.targetSpec.TargetSpecs.KeyColumnName {
-				if exists, _ := p.hasColumn(k); !exists {
+			if k == p.specs.KeyColumnName {
+				if exists, _ := p.hasColumn(row.table, k); !exists {
 					zap.S().Infof("column %s not found, running build script...", k)
 					if _, err := p.db.Exec(fmt.Sprintf(
 						MySqlAlterTableAddUniqueKeyColumn,
-						p.targetSpec.TargetSpecs.Table, k)); err != nil {
+						row.table, k)); err != nil {
 						zap.S().Warnf("failed to create unique key %s: %s\n", k, err.Error())
 					}
 				}
 			}
 
-			p.columns = append(p.columns, k)
+			p.columns[row.table] = append(p.columns[row.table], k)
+		}
+
+		if literal, ok := sqlLiteral(v); ok {
+			values[k] = literal
+			continue
 		}
 
 		switch v.(type) {
-		case int:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values[k] = fmt.Sprintf("%v", data)
-		case int8:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values[k] = fmt.Sprintf("%v", data)
-		case int16:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values[k] = fmt.Sprintf("%v", data)
-		case int32:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values[k] = fmt.Sprintf("%v", data)
-		case int64:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values[k] = fmt.Sprintf("%v", data)
-		case float32:
-			value, _ := v.(string)
-			data, _ := strconv.ParseFloat(value, 64)
-			values[k] = fmt.Sprintf("%v", data)
-		case float64:
-			value, _ := v.(string)
-			data, _ := strconv.ParseFloat(value, 64)
-			values[k] = fmt.Sprintf("%v", data)
-		case bool:
-			value, _ := v.(string)
-			data, _ := strconv.ParseBool(value)
-			values[k] = fmt.Sprintf("%v", data)
 		case nil:
 			values[k] = fmt.Sprintf("%v", "NULL")
 		case map[string]interface{}:
@@ -438,26 +295,30 @@ func (p *mysqlTarget) buildCommands(element list.Element) (map[string]string, er
 
 			values[k] = fmt.Sprintf("'%v'", value)
 
-			if p.fieldIsDateTime(v) {
+			if fieldIsDateTime(v) {
 				values[k] = strings.ReplaceAll(fmt.Sprintf("'%v'", v), "T", " ")
 			}
 		}
 	}
 
 	hasKey := false
-	for _, col := range p.columns {
-		if col == p.targetSpec.TargetSpecs.KeyColumnName {
+	for _, col := range p.columns[row.table] {
+		if col == p.specs.KeyColumnName {
 			hasKey = true
 		}
 	}
 
-	if !hasKey || values[p.targetSpec.TargetSpecs.KeyColumnName] == "" {
-		values[p.targetSpec.TargetSpecs.KeyColumnName] = fmt.Sprintf("'%x'", md5.Sum([]byte(time.Now().String())))
+	if !hasKey || values[p.specs.KeyColumnName] == "" {
+		values[p.specs.KeyColumnName] = fmt.Sprintf("'%x'", md5.Sum([]byte(time.Now().String())))
 	}
 
 	return values, nil
 }
 
 func (p *mysqlTarget) Close() error {
+	if p.db == nil {
+		return nil
+	}
+
 	return p.db.Close()
 }
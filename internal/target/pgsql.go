@@ -7,8 +7,6 @@ import (
 	"draethos.io.com/internal/interfaces"
 	"encoding/json"
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -19,17 +17,10 @@ import (
 )
 
 const (
-	PgSqlInsertTemplate                       = "INSERT INTO %s (%s) values (%s) ON CONFLICT (%s) DO NOTHING;\n"
-	PgSqlAlterTableAddPrimaryKeyTemplate      = "CREATE TABLE IF NOT EXISTS %s (%s varchar(90) NOT NULL, PRIMARY KEY (%s));\n"
-	PgSqlAlterTableAddUniqueKeyColumn         = "ALTER TABLE %s ADD UNIQUE(%s);\n"
-	PgSqlAlterTableAddColumnVarcharTemplate   = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" VARCHAR(255) %s;\n"
-	PgSqlAlterTableAddColumnTextTemplate      = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" TEXT %s;\n"
-	PgSqlAlterTableAddColumnIntTemplate       = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" INT %s;\n"
-	PgSqlAlterTableAddColumnDateTemplate      = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" DATE %s;\n"
-	PgSqlAlterTableAddColumnTimestampTemplate = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" TIMESTAMP %s;\n"
-	PgSqlAlterTableAddColumnNumericTemplate   = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" NUMERIC(12,2) %s;\n"
-	PgSqlAlterTableAddColumnBoolTemplate      = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" BOOL NOT NULL DEFAULT false;\n"
-	PgSqlAlterTableAddColumnJsonbTemplate     = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" JSONB NULL;\n"
+	PgSqlInsertTemplate                  = "INSERT INTO %s (%s) values (%s) ON CONFLICT (%s) DO NOTHING;\n"
+	PgSqlAlterTableAddPrimaryKeyTemplate = "CREATE TABLE IF NOT EXISTS %s (%s varchar(90) NOT NULL, PRIMARY KEY (%s));\n"
+	PgSqlAlterTableAddUniqueKeyColumn    = "ALTER TABLE %s ADD UNIQUE(%s);\n"
+	PgSqlAlterTableAddColumnTemplate     = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" %s;\n"
 )
 
 type pgsqlTarget struct {
@@ -37,48 +28,66 @@ type pgsqlTarget struct {
 	targetSpec specs.Target
 	codec      interfaces.CodecInterface
 	queue      *list.List
-	columns    map[string]bool
+	columns    map[string]map[string]bool
 	db         *sql.DB
+	config     pgsqlConfigurations
+	specs      specs.DatabaseTargetSpecs
+}
+
+type pgsqlConfigurations struct {
+	Host     string `config:"host"`
+	Port     int    `config:"port"`
+	User     string `config:"user"`
+	Password string `config:"password"`
+	SslMode  string `config:"sslmode"`
 }
 
 func NewPgsqlTarget(targetSpec specs.Target, codec interfaces.CodecInterface) (*pgsqlTarget, error) {
+	config := pgsqlConfigurations{Port: 5432}
+	if err := specs.DecodeConfigurations(targetSpec.TargetSpecs.Configurations, &config); err != nil {
+		return nil, errors.Errorf("pgsql target: %s", err.Error())
+	}
+
+	targetSpecs, err := targetSpec.TargetSpecs.Database()
+	if err != nil {
+		return nil, errors.Errorf("pgsql target: %s", err.Error())
+	}
+
 	return &pgsqlTarget{
+		config:     config,
+		specs:      targetSpecs,
 		targetSpec: targetSpec,
 		codec:      codec,
 		queue:      list.New(),
-		columns:    map[string]bool{},
+		columns:    map[string]map[string]bool{},
 	}, nil
 }
 
 func (p *pgsqlTarget) Initialize() error {
-	if _, ok := p.targetSpec.TargetSpecs.Configurations["host"].(string); !ok {
+	if p.config.Host == "" {
 		return errors.Errorf("target host not defined")
 	}
 
-	if _, ok := p.targetSpec.TargetSpecs.Configurations["user"].(string); !ok {
+	if p.config.User == "" {
 		return errors.Errorf("target user not defined")
 	}
 
-	if _, ok := p.targetSpec.TargetSpecs.Configurations["password"].(string); !ok {
+	if p.config.Password == "" {
 		return errors.Errorf("target password not defined")
 	}
 
-	if _, ok := p.targetSpec.TargetSpecs.Configurations["sslmode"].(string); !ok {
+	if p.config.SslMode == "" {
 		return errors.Errorf("target sslmode not defined")
 	}
 
-	if p.targetSpec.TargetSpecs.KeyColumnName == "" {
-		p.targetSpec.TargetSpecs.KeyColumnName = "id"
-	}
-
 	db, err := sql.Open("postgres",
 		fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			p.targetSpec.TargetSpecs.Configurations["host"],
-			p.targetSpec.TargetSpecs.Configurations["port"],
-			p.targetSpec.TargetSpecs.Configurations["user"],
-			p.targetSpec.TargetSpecs.Configurations["password"],
-			p.targetSpec.TargetSpecs.Database,
-			p.targetSpec.TargetSpecs.Configurations["sslmode"]))
+			p.config.Host,
+			p.config.Port,
+			p.config.User,
+			p.config.Password,
+			p.specs.Database,
+			p.config.SslMode))
 
 	if err != nil {
 		return errors.Errorf("failed to connect target pgsql: %s", err.Error())
@@ -86,20 +95,27 @@ func (p *pgsqlTarget) Initialize() error {
 
 	p.db = db
 
+	// tables named after the topic are created along with their first events
+	if strings.Contains(p.specs.Table, TopicPlaceholder) {
+		return nil
+	}
+
 	if _, err := p.db.Exec(
 		fmt.Sprintf(
 			PgSqlAlterTableAddPrimaryKeyTemplate,
-			p.targetSpec.TargetSpecs.Table,
-			p.targetSpec.TargetSpecs.KeyColumnName,
-			p.targetSpec.TargetSpecs.KeyColumnName)); err != nil {
+			p.specs.Table,
+			p.specs.KeyColumnName,
+			p.specs.KeyColumnName)); err != nil {
 		return errors.Errorf("failed to initialize table %s: %s",
-			p.targetSpec.TargetSpecs.Table,
+			p.specs.Table,
 			err.Error())
 	}
 
 	zap.S().Infof("initialize target table %s with primary key %s",
-		p.targetSpec.TargetSpecs.Table,
-		p.targetSpec.TargetSpecs.KeyColumnName)
+		p.specs.Table,
+		p.specs.KeyColumnName)
+
+	p.columns[p.specs.Table] = map[string]bool{}
 
 	return nil
 }
@@ -108,11 +124,16 @@ func (p *pgsqlTarget) Attach(key string, data map[string]interface{}) error {
 	p.Lock()
 	defer p.Unlock()
 
+	topic, err := eventTopic(p.specs.Table, p.specs.TopicField, data)
+	if err != nil {
+		return err
+	}
+
 	if key != "" {
-		data[p.targetSpec.TargetSpecs.KeyColumnName] = key
+		data[p.specs.KeyColumnName] = key
 	}
 
-	p.queue.PushBack(data)
+	p.queue.PushBack(databaseRow{table: topicTable(p.specs.Table, topic), data: data})
 
 	return nil
 }
@@ -121,7 +142,7 @@ func (p *pgsqlTarget) CanFlush() bool {
 	p.Lock()
 	defer p.Unlock()
 
-	return p.queue.Len() >= p.targetSpec.TargetSpecs.BatchSize
+	return p.queue.Len() >= p.specs.BatchSize
 }
 
 func (p *pgsqlTarget) Flush() error {
@@ -151,127 +172,48 @@ func (p *pgsqlTarget) Flush() error {
 }
 
 func (p *pgsqlTarget) buildCommands(bufferRx *strings.Builder, element list.Element) error {
-	content, ok := element.Value.(map[string]interface{})
+	row, ok := element.Value.(databaseRow)
 	if !ok {
 		return errors.Errorf("failed to convert content list")
 	}
 
-	columns := make([]string, 0)
-	values := make([]string, 0)
-	for k, v := range content {
-		if _, ok := p.columns[k]; !ok {
-			fieldVarcharDefault := "NULL"
-			fieldNumberDefault := "NOT NULL DEFAULT 0"
-			if k == p.targetSpec.TargetSpecs.KeyColumnName {
-				fieldVarcharDefault = "NOT NULL"
-				fieldNumberDefault = "NOT NULL"
-			}
-
-			switch v.(type) {
-			case int:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnIntTemplate,
-					p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault))
-			case int8:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnIntTemplate,
-					p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault))
-			case int16:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnIntTemplate,
-					p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault))
-			case int32:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnIntTemplate,
-					p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault))
-			case int64:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnIntTemplate,
-					p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault))
-			case float32:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnNumericTemplate,
-					p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault))
-			case float64:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnIntTemplate,
-					p.targetSpec.TargetSpecs.Table, k, fieldNumberDefault))
-			case bool:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnBoolTemplate,
-					p.targetSpec.TargetSpecs.Table, k))
-			case map[string]interface{}:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnJsonbTemplate,
-					p.targetSpec.TargetSpecs.Table, k))
-			case []interface{}:
-				bufferRx.WriteString(fmt.Sprintf(
-					PgSqlAlterTableAddColumnJsonbTemplate,
-					p.targetSpec.TargetSpecs.Table, k))
-			default:
-				alterTableAddColumnTemplate := PgSqlAlterTableAddColumnVarcharTemplate
-
-				if p.fieldIsDate(v) {
-					alterTableAddColumnTemplate = PgSqlAlterTableAddColumnDateTemplate
-				}
-
-				if p.fieldIsDateTime(v) {
-					alterTableAddColumnTemplate = PgSqlAlterTableAddColumnTimestampTemplate
-				}
+	tableColumns, ok := p.columns[row.table]
+	if !ok {
+		bufferRx.WriteString(fmt.Sprintf(
+			PgSqlAlterTableAddPrimaryKeyTemplate,
+			row.table,
+			p.specs.KeyColumnName,
+			p.specs.KeyColumnName))
 
-				if p.fieldIsText(v) {
-					alterTableAddColumnTemplate = PgSqlAlterTableAddColumnTextTemplate
-				}
+		tableColumns = map[string]bool{}
+		p.columns[row.table] = tableColumns
+	}
 
-				bufferRx.WriteString(fmt.Sprintf(
-					alterTableAddColumnTemplate,
-					p.targetSpec.TargetSpecs.Table, k, fieldVarcharDefault))
-			}
+	columns := make([]string, 0)
+	values := make([]string, 0)
+	for k, v := range row.data {
+		if _, ok := tableColumns[k]; !ok {
+			bufferRx.WriteString(fmt.Sprintf(
+				PgSqlAlterTableAddColumnTemplate,
+				row.table, k, PgsqlColumnDefinition(v, k == p.specs.KeyColumnName)))
 
-			if k == p.targetSpec.TargetSpecs.KeyColumnName {
+			if k == p.specs.KeyColumnName {
 				bufferRx.WriteString(fmt.Sprintf(
 					PgSqlAlterTableAddUniqueKeyColumn,
-					p.targetSpec.TargetSpecs.Table, k))
+					row.table, k))
 			}
 
-			p.columns[k] = true
+			tableColumns[k] = true
 		}
 
 		columns = append(columns, k)
 
+		if literal, ok := sqlLiteral(v); ok {
+			values = append(values, literal)
+			continue
+		}
+
 		switch v.(type) {
-		case int:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values = append(values, fmt.Sprintf("%v", data))
-		case int8:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values = append(values, fmt.Sprintf("%v", data))
-		case int16:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values = append(values, fmt.Sprintf("%v", data))
-		case int32:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values = append(values, fmt.Sprintf("%v", data))
-		case int64:
-			value, _ := v.(string)
-			data, _ := strconv.ParseInt(value, 0, 64)
-			values = append(values, fmt.Sprintf("%v", data))
-		case float32:
-			value, _ := v.(string)
-			data, _ := strconv.ParseFloat(value, 64)
-			values = append(values, fmt.Sprintf("%v", data))
-		case float64:
-			value, _ := v.(string)
-			data, _ := strconv.ParseFloat(value, 64)
-			values = append(values, fmt.Sprintf("%v", data))
-		case bool:
-			value, _ := v.(string)
-			data, _ := strconv.ParseBool(value)
-			values = append(values, fmt.Sprintf("%v", data))
 		case nil:
 			values = append(values, "null")
 		case map[string]interface{}:
@@ -285,7 +227,7 @@ func (p *pgsqlTarget) buildCommands(bufferRx *strings.Builder, element list.Elem
 			value = strings.ReplaceAll(value, "'", `''`)
 			value = fmt.Sprintf("'%v'", value)
 
-			if p.fieldIsDateTime(v) {
+			if fieldIsDateTime(v) {
 				value = strings.ReplaceAll(fmt.Sprintf("'%v'", v), "T", " ")
 			}
 
@@ -295,13 +237,13 @@ func (p *pgsqlTarget) buildCommands(bufferRx *strings.Builder, element list.Elem
 
 	hasKey := false
 	for _, v := range columns {
-		if v == p.targetSpec.TargetSpecs.KeyColumnName {
+		if v == p.specs.KeyColumnName {
 			hasKey = true
 		}
 	}
 
 	if !hasKey {
-		columns = append(columns, p.targetSpec.TargetSpecs.KeyColumnName)
+		columns = append(columns, p.specs.KeyColumnName)
 		values = append(values, fmt.Sprintf("'%x'", md5.Sum([]byte(strings.Join(values, ",")))))
 	}
 
@@ -311,53 +253,18 @@ func (p *pgsqlTarget) buildCommands(bufferRx *strings.Builder, element list.Elem
 
 	bufferRx.WriteString(fmt.Sprintf(
 		PgSqlInsertTemplate,
-		p.targetSpec.TargetSpecs.Table,
+		row.table,
 		strings.Join(columns, ","),
 		strings.Join(values, ","),
-		p.targetSpec.TargetSpecs.KeyColumnName))
+		p.specs.KeyColumnName))
 
 	return nil
 }
 
 func (p *pgsqlTarget) Close() error {
-	return p.db.Close()
-}
-
-func (p *pgsqlTarget) fieldIsText(value interface{}) bool {
-	v, _ := value.(string)
-
-	return len(v) > 255
-}
-
-func (p *pgsqlTarget) fieldIsDate(value interface{}) bool {
-	for _, pattern := range []string{
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]))`,
-	} {
-		r, _ := regexp.Compile(pattern)
-		v, _ := value.(string)
-
-		if r.MatchString(v) {
-			return true
-		}
-	}
-
-	return false
-}
-
-func (p *pgsqlTarget) fieldIsDateTime(value interface{}) bool {
-	for _, pattern := range []string{
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T(0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]).(0[0-9]\d|3[01]))`,
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T(0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]))`,
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]) (0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]).(0[0-9]\d|3[01]))`,
-		`([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]) (0[0-9]|[24]\d|3[01]):(0[0-9]|[59]\d|3[01]):(0[0-9]|[59]\d|3[01]))`,
-	} {
-		r, _ := regexp.Compile(pattern)
-		v, _ := value.(string)
-
-		if r.MatchString(v) {
-			return true
-		}
+	if p.db == nil {
+		return nil
 	}
 
-	return false
+	return p.db.Close()
 }
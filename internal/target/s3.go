@@ -8,7 +8,6 @@ import (
 	interfaces2 "draethos.io.com/internal/interfaces"
 	"encoding/json"
 	"fmt"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -17,7 +16,6 @@ import (
 
 	"draethos.io.com/pkg/streams/specs"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -26,80 +24,59 @@ import (
 )
 
 const (
-	S3DefaultAWSRegion      = "us-east-1"
-	S3DefaultProfile        = "default"
-	s3bufferSizeDefault int = 1048576
-	S3LineBreakDefault      = "\n"
+	s3bufferSizeDefault   int = 1048576
+	S3LineBreakDefault        = "\n"
+	S3DefaultExtension        = "jsonl"
+	S3DefaultContentType      = "application/octet-stream"
+	S3GzipContentEncoding     = "gzip"
+	s3GzipSuffix              = ".gz"
 )
 
 type s3Target struct {
 	sync.Mutex
 	session    *session.Session
 	targetSpec specs.Target
+	specs      specs.S3TargetSpecs
 	codec      interfaces2.CodecInterface
 	fileName   string
 	queue      *list.List
 	bufferLen  uint64
 }
 
-func NewS3Target(targetSpec specs.Target, codec interfaces2.CodecInterface) (interfaces2.TargetInterface, error) {
-	return &s3Target{targetSpec: targetSpec, codec: codec, queue: list.New()}, nil
+// s3Line is a serialized event along with the topic naming its prefix.
+type s3Line struct {
+	topic   string
+	content []byte
 }
 
-func (g *s3Target) Initialize() error {
-	var cred *credentials.Credentials
-	var region = S3DefaultAWSRegion
-	var profile = S3DefaultProfile
-	var accessKey = ""
-	var secretKey = ""
-	var token = ""
-
-	if g.targetSpec.TargetSpecs.Bucket == "" {
-		return errors.Errorf("bucket not defined")
-	}
-
-	if g.targetSpec.TargetSpecs.LineBreak == "" {
-		g.targetSpec.TargetSpecs.LineBreak = S3LineBreakDefault
-	}
-
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.region"].(string); ok {
-		region = value
-	}
-
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.profile"].(string); ok {
-		profile = value
-	}
-
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.access.key"].(string); ok {
-		accessKey = value
-	}
+// s3File is the content uploaded for the events of a topic.
+type s3File struct {
+	topic           string
+	extension       string
+	contentType     string
+	contentEncoding *string
+	content         []byte
+}
 
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.secret.key"].(string); ok {
-		secretKey = value
+func NewS3Target(targetSpec specs.Target, codec interfaces2.CodecInterface) (interfaces2.TargetInterface, error) {
+	targetSpecs, err := targetSpec.TargetSpecs.S3()
+	if err != nil {
+		return nil, errors.Errorf("s3 target: %s", err.Error())
 	}
 
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.credential.file"].(string); ok {
-		_, err := os.Stat(value)
-		if err != nil {
-			zap.S().Debugf("aws_credentials_file %s not found", value)
-		}
+	return &s3Target{targetSpec: targetSpec, specs: targetSpecs, codec: codec, queue: list.New()}, nil
+}
 
-		if err == nil {
-			cred = credentials.NewSharedCredentials(value, profile)
-		}
+func (g *s3Target) Initialize() error {
+	if g.specs.Bucket == "" {
+		return errors.Errorf("bucket not defined")
 	}
 
-	if accessKey != "" && secretKey != "" {
-		cred = credentials.NewStaticCredentials(accessKey, secretKey, token)
+	sess, err := NewAwsSession(g.targetSpec.TargetSpecs.Configurations)
+	if err != nil {
+		return errors.Errorf("s3 target: %s", err.Error())
 	}
 
-	sess := session.Must(session.NewSession(
-		&aws.Config{
-			Region:      aws.String(region),
-			Credentials: cred,
-		}),
-	)
-
 	g.session = sess
 
 	uploader := s3manager.NewUploader(g.session)
@@ -108,8 +85,8 @@ func (g *s3Target) Initialize() error {
 		return errors.Errorf("failed to access s3: %s", err.Error())
 	}
 
-	if _, err := s3manager.GetBucketRegion(context.TODO(), sess, g.targetSpec.TargetSpecs.Bucket, region); err != nil {
-		return errors.Errorf("bucket %s not found: %s", g.targetSpec.TargetSpecs.Bucket, err.Error())
+	if _, err := s3manager.GetBucketRegion(context.TODO(), sess, g.specs.Bucket, aws.StringValue(sess.Config.Region)); err != nil {
+		return errors.Errorf("bucket %s not found: %s", g.specs.Bucket, err.Error())
 	}
 
 	return nil
@@ -119,22 +96,33 @@ func (g *s3Target) Attach(key string, content map[string]interface{}) error {
 	g.Lock()
 	defer g.Unlock()
 
-	payload, err := g.codec.Serialize(content)
+	topic, err := eventTopic(g.specs.Prefix, g.specs.TopicField, content)
+	if err != nil {
+		return err
+	}
+
+	// encodings apply to the whole file, not to every event
+	codec := g.codec
+	if encoded, ok := codec.(interfaces2.EncodedCodecInterface); ok {
+		codec = encoded.Codec()
+	}
+
+	payload, err := codec.Serialize(content)
 	if err != nil {
 		return errors.Errorf("failed to serialize payload: %s", err.Error())
 	}
 
+	// codecs other than json are written as they were serialized
 	buffer := new(bytes.Buffer)
-	if err := json.Compact(buffer, payload); err != nil {
-		zap.S().Warnf("failed to compact json: %s", err.Error())
+	if err := json.Compact(buffer, payload); err == nil {
+		payload = buffer.Bytes()
 	}
 
-	payload = buffer.Bytes()
-	g.bufferLen += uint64(buffer.Len())
-	g.bufferLen += uint64(len([]byte(g.targetSpec.TargetSpecs.LineBreak)))
-	g.queue.PushBack(payload)
+	g.bufferLen += uint64(len(payload))
+	g.bufferLen += uint64(len([]byte(g.specs.LineBreak)))
+	g.queue.PushBack(s3Line{topic: topic, content: payload})
 
-	zap.S().Debugf("buffer length: %s", lenReadable(g.bufferLen, 2))
+	zap.S().Debugf("buffer length: %s", LenReadable(g.bufferLen, 2))
 
 	return nil
 }
@@ -143,12 +131,12 @@ func (g *s3Target) CanFlush() bool {
 	g.Lock()
 	defer g.Unlock()
 
-	if g.targetSpec.TargetSpecs.BufferSize > 0 {
-		return g.bufferLen >= g.targetSpec.TargetSpecs.BufferSize
+	if g.specs.BufferSize > 0 {
+		return g.bufferLen >= g.specs.BufferSize
 	}
 
-	if g.targetSpec.TargetSpecs.BatchSize > 0 {
-		return g.queue.Len() >= g.targetSpec.TargetSpecs.BatchSize
+	if g.specs.BatchSize > 0 {
+		return g.queue.Len() >= g.specs.BatchSize
 	}
 
 	return true
@@ -158,54 +146,136 @@ func (g *s3Target) Flush() error {
 	g.Lock()
 	defer g.Unlock()
 
-	var fileName = fmt.Sprintf("%s%x.jsonl", g.prefixFormatter(), md5.Sum([]byte(time.Now().String())))
-	uploader := s3manager.NewUploader(g.session)
-
 	if g.bufferLen == 0 {
 		return nil
 	}
 
-	var bufferRx strings.Builder
+	files, err := g.files()
+	if err != nil {
+		return err
+	}
+
+	g.bufferLen = 0
+
+	uploader := s3manager.NewUploader(g.session)
+	for _, file := range files {
+		var fileName = fmt.Sprintf("%s%x.%s", g.prefixFormatter(file.topic), md5.Sum([]byte(time.Now().String())), file.extension)
+
+		zap.S().Debugf("upload file: %s, bytes length: %s", fileName, LenReadable(uint64(len(file.content)), 2))
+
+		start := time.Now()
+		result, err := uploader.Upload(&s3manager.UploadInput{
+			Bucket:          &g.specs.Bucket,
+			Key:             &fileName,
+			Body:            bytes.NewReader(file.content),
+			ContentType:     aws.String(file.contentType),
+			ContentEncoding: file.contentEncoding,
+		})
+
+		elapsed := time.Since(start)
+		if err != nil {
+			return errors.Errorf("failed to upload file %s, elapsed time: %s, error: %s", fileName, elapsed, err.Error())
+		}
+
+		zap.S().Infof("uploaded file %s, elapsed time: %s", result.Location, elapsed)
+	}
+
+	return nil
+}
+
+// files empties the queue into a file per topic, in the order the topics were
+// first seen. Prefixes without %{topic} get a single file.
+func (g *s3Target) files() ([]s3File, error) {
+	topics := make([]string, 0)
+	lines := make(map[string][][]byte)
+
 	elementLen := g.queue.Len()
 	for i := 0; i <= elementLen; i++ {
 		if element := g.queue.Front(); element != nil {
-			if content, ok := element.Value.([]byte); ok {
-				bufferRx.WriteString(fmt.Sprintf("%s%s", content, g.targetSpec.TargetSpecs.LineBreak))
+			if line, ok := element.Value.(s3Line); ok {
+				if _, seen := lines[line.topic]; !seen {
+					topics = append(topics, line.topic)
+				}
+				lines[line.topic] = append(lines[line.topic], line.content)
 			}
 			g.queue.Remove(element)
 		}
 	}
 
-	zap.S().Debugf("upload file: %s.jsonl, bytes length: %s", fileName, lenReadable(g.bufferLen, 2))
+	files := make([]s3File, 0, len(topics))
+	for _, topic := range topics {
+		file, err := g.file(lines[topic])
+		if err != nil {
+			return nil, err
+		}
 
-	g.bufferLen = 0
+		file.topic = topic
+		files = append(files, file)
+	}
 
-	start := time.Now()
-	result, err := uploader.Upload(&s3manager.UploadInput{
-		Bucket:          &g.targetSpec.TargetSpecs.Bucket,
-		Key:             &fileName,
-		Body:            strings.NewReader(bufferRx.String()),
-		ContentEncoding: aws.String("application/json"),
-	})
+	return files, nil
+}
+
+// file joins the lines into the content of a file, along with its extension
+// and the headers it is uploaded with. Files encoded only with gzip keep the
+// content type of the codec and are uploaded with the gzip content encoding,
+// files with other encodings are uploaded as binary.
+func (g *s3Target) file(lines [][]byte) (s3File, error) {
+	extension, header := S3DefaultExtension, []byte(nil)
+	if codec, ok := g.codec.(interfaces2.FileCodecInterface); ok {
+		extension, header = codec.Extension(), codec.Header()
+	}
 
-	elapsed := time.Since(start)
+	contentType := S3DefaultContentType
+	codec := g.codec
+	if encoded, ok := codec.(interfaces2.EncodedCodecInterface); ok {
+		codec = encoded.Codec()
+	}
+	if typed, ok := codec.(interfaces2.ContentTypeInterface); ok {
+		contentType = typed.ContentType()
+	}
+
+	var buffer bytes.Buffer
+	if len(header) > 0 {
+		buffer.WriteString(fmt.Sprintf("%s%s", header, g.specs.LineBreak))
+	}
+
+	for _, line := range lines {
+		buffer.WriteString(fmt.Sprintf("%s%s", line, g.specs.LineBreak))
+	}
+
+	encoded, ok := g.codec.(interfaces2.EncodedCodecInterface)
+	if !ok {
+		return s3File{extension: extension, contentType: contentType, content: buffer.Bytes()}, nil
+	}
+
+	if _, ok := g.codec.(interfaces2.FileCodecInterface); !ok {
+		extension += encoded.Suffix()
+	}
+
+	content, err := encoded.Encode(buffer.Bytes())
 	if err != nil {
-		return errors.Errorf("failed to upload file %s, elapsed time: %s, error: %s", fileName, elapsed, err.Error())
+		return s3File{}, errors.Errorf("failed to encode file: %s", err.Error())
 	}
 
-	zap.S().Infof("uploaded file %s, elapsed time: %s", result.Location, elapsed)
+	var contentEncoding *string
+	if encoded.Suffix() == s3GzipSuffix {
+		contentEncoding = aws.String(S3GzipContentEncoding)
+	} else {
+		contentType = S3DefaultContentType
+	}
 
-	return nil
+	return s3File{extension: extension, contentType: contentType, contentEncoding: contentEncoding, content: content}, nil
 }
 
 func (g *s3Target) Close() error {
 	return nil
 }
 
-func (g *s3Target) prefixFormatter() string {
+func (g *s3Target) prefixFormatter(topic string) string {
 	var formatterPrefix = regexp.MustCompile(`^\%{\S+\}$`)
 
-	prefixFormatter := g.targetSpec.TargetSpecs.Prefix
+	prefixFormatter := strings.ReplaceAll(g.specs.Prefix, TopicPlaceholder, topic)
 
 	if formatterPrefix.MatchString("%{YEAR}") {
 		prefixFormatter = strings.Replace(prefixFormatter, "%{YEAR}", time.Now().Format("2006"), 5)
@@ -241,7 +311,8 @@ const (
 	KB = 1000
 )
 
-func lenReadable(length uint64, decimals int) (out string) {
+// LenReadable formats a length in bytes with the unit that fits it.
+func LenReadable(length uint64, decimals int) (out string) {
 	var unit string
 	var i int
 	var remainder int
@@ -0,0 +1,92 @@
+package target
+
+import (
+	"container/list"
+	"testing"
+
+	codec "draethos.io.com/internal/codec"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestShouldEncodeWholeS3File(t *testing.T) {
+	csv, err := codec.NewCsvCodec(map[string]interface{}{"columns": []interface{}{"id", "name"}, "header": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		codec           interfaces.CodecInterface
+		extension       string
+		contentType     string
+		contentEncoding string
+		content         string
+	}{
+		{codec.NewJsonCodec(), "jsonl", "application/json", "", "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n"},
+		{codec.NewChainCodec([]interfaces.EncodingInterface{codec.NewGzipEncoding()}, codec.NewJsonCodec()), "jsonl.gz", "application/json", "gzip", "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n"},
+		{codec.NewChainCodec([]interfaces.EncodingInterface{codec.NewGzipEncoding()}, csv), "csv.gz", "text/csv", "gzip", "id,name\n1,a\n2,b\n"},
+	}
+
+	for _, test := range tests {
+		target := &s3Target{
+			targetSpec: specs.Target{Type: "s3"},
+			specs:      specs.S3TargetSpecs{LineBreak: S3LineBreakDefault},
+			codec:      test.codec,
+			queue:      list.New(),
+		}
+
+		for _, event := range []map[string]interface{}{{"id": 1, "name": "a"}, {"id": 2, "name": "b"}} {
+			if err := target.Attach("key", event); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		files, err := target.files()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) != 1 {
+			t.Fatalf("expected a single file, got %d", len(files))
+		}
+
+		extension, content := files[0].extension, files[0].content
+
+		if _, ok := test.codec.(interfaces.EncodedCodecInterface); ok {
+			if content, err = codec.NewGzipEncoding().Decode(content); err != nil {
+				t.Fatalf("%s: expected the whole file to be compressed: %v", extension, err)
+			}
+		}
+
+		if extension != test.extension || string(content) != test.content {
+			t.Errorf("expected %s file %q, got %s file %q", test.extension, test.content, extension, content)
+		}
+
+		if files[0].contentType != test.contentType || aws.StringValue(files[0].contentEncoding) != test.contentEncoding {
+			t.Errorf("%s: expected content type %q and encoding %q, got %q and %q",
+				extension, test.contentType, test.contentEncoding, files[0].contentType, aws.StringValue(files[0].contentEncoding))
+		}
+
+		if target.queue.Len() != 0 {
+			t.Errorf("%s: expected queue to be empty, got %d events", extension, target.queue.Len())
+		}
+	}
+}
+
+func TestShouldUploadFilesWithOtherEncodingsAsBinary(t *testing.T) {
+	target := &s3Target{
+		specs: specs.S3TargetSpecs{LineBreak: S3LineBreakDefault},
+		codec: codec.NewChainCodec([]interfaces.EncodingInterface{codec.NewZstdEncoding()}, codec.NewJsonCodec()),
+		queue: list.New(),
+	}
+
+	file, err := target.file([][]byte{[]byte(`{"id":1}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.contentType != S3DefaultContentType || file.contentEncoding != nil {
+		t.Errorf("expected content type %s without encoding, got %s and %v", S3DefaultContentType, file.contentType, file.contentEncoding)
+	}
+}
@@ -0,0 +1,58 @@
+package target
+
+import (
+	"container/list"
+	"reflect"
+	"testing"
+
+	codec "draethos.io.com/internal/codec"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+)
+
+type snsClientStub struct {
+	snsiface.SNSAPI
+	messages []string
+}
+
+func (s *snsClientStub) Publish(input *sns.PublishInput) (*sns.PublishOutput, error) {
+	s.messages = append(s.messages, *input.Message)
+
+	return &sns.PublishOutput{}, nil
+}
+
+func TestShouldPublishEncodedChainsToSns(t *testing.T) {
+	chains := map[string]interfaces.CodecInterface{
+		"base64|msgpack":   codec.NewChainCodec([]interfaces.EncodingInterface{codec.NewBase64Encoding()}, codec.NewMsgpackCodec()),
+		"base64|gzip|json": codec.NewChainCodec([]interfaces.EncodingInterface{codec.NewBase64Encoding(), codec.NewGzipEncoding()}, codec.NewJsonCodec()),
+	}
+
+	event := map[string]interface{}{"id": "d-1", "active": true}
+	for name, chain := range chains {
+		client := &snsClientStub{}
+		target := &snsTarget{
+			targetSpec: specs.Target{Type: "sns"},
+			specs:      specs.SnsTargetSpecs{TopicArn: "arn:aws:sns:us-east-1:000000000000:events"},
+			codec:      chain,
+			queue:      list.New(),
+			client:     client,
+		}
+
+		sendThrough(t, target, event)
+
+		if len(client.messages) != 1 {
+			t.Fatalf("%s: expected 1 message, got %d", name, len(client.messages))
+		}
+
+		decoded, err := chain.Deserialize([]byte(client.messages[0]))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(decoded, event) {
+			t.Errorf("%s: expected %#v, got %#v", name, event, decoded)
+		}
+	}
+}
@@ -1,26 +1,20 @@
 package target
 
 import (
-	"bytes"
 	"container/list"
 	interfaces2 "draethos.io.com/internal/interfaces"
-	"encoding/json"
-	"os"
 	"sync"
 	"time"
 
 	"draethos.io.com/pkg/streams/specs"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
 const (
-	SqsDefaultAWSRegion      = "us-east-1"
-	SqsDefaultProfile        = "default"
 	SqsbufferSizeDefault int = 1048576
 	SqsLineBreakDefault      = "\n"
 )
@@ -28,7 +22,9 @@ const (
 type sqsTarget struct {
 	sync.Mutex
 	session    *session.Session
+	client     sqsiface.SQSAPI
 	targetSpec specs.Target
+	specs      specs.SqsTargetSpecs
 	codec      interfaces2.CodecInterface
 	fileName   string
 	queue      *list.List
@@ -36,62 +32,28 @@ type sqsTarget struct {
 }
 
 func NewSqsTarget(targetSpec specs.Target, codec interfaces2.CodecInterface) (interfaces2.TargetInterface, error) {
-	return &sqsTarget{targetSpec: targetSpec, codec: codec, queue: list.New()}, nil
+	targetSpecs, err := targetSpec.TargetSpecs.Sqs()
+	if err != nil {
+		return nil, errors.Errorf("sqs target: %s", err.Error())
+	}
+
+	return &sqsTarget{targetSpec: targetSpec, specs: targetSpecs, codec: codec, queue: list.New()}, nil
 }
 
 func (g *sqsTarget) Initialize() error {
-	var cred *credentials.Credentials
-	var region = SqsDefaultAWSRegion
-	var profile = SqsDefaultProfile
-	var accessKey = ""
-	var secretKey = ""
-	var token = ""
-
-	if g.targetSpec.TargetSpecs.QueueUrl == "" {
+	if g.specs.QueueUrl == "" {
 		return errors.Errorf("queueUrl not defined")
 	}
 
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.region"].(string); ok {
-		region = value
-	}
-
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.profile"].(string); ok {
-		profile = value
-	}
-
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.access.key"].(string); ok {
-		accessKey = value
-	}
-
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.secret.key"].(string); ok {
-		secretKey = value
-	}
-
-	if value, ok := g.targetSpec.TargetSpecs.Configurations["aws.credential.file"].(string); ok {
-		_, err := os.Stat(value)
-		if err != nil {
-			zap.S().Debugf("aws_credentials_file %s not found", value)
-		}
-
-		if err == nil {
-			cred = credentials.NewSharedCredentials(value, profile)
-		}
-	}
-
-	if accessKey != "" && secretKey != "" {
-		cred = credentials.NewStaticCredentials(accessKey, secretKey, token)
+	sess, err := NewAwsSession(g.targetSpec.TargetSpecs.Configurations)
+	if err != nil {
+		return errors.Errorf("sqs target: %s", err.Error())
 	}
 
-	sess := session.Must(session.NewSession(
-		&aws.Config{
-			Region:      aws.String(region),
-			Credentials: cred,
-		}),
-	)
-
 	g.session = sess
 
 	queue := sqs.New(g.session)
+	g.client = queue
 
 	maxResults := int64(1)
 	if _, err := queue.ListQueues(&sqs.ListQueuesInput{
@@ -100,26 +62,26 @@ func (g *sqsTarget) Initialize() error {
 		return errors.Errorf("failed to access sqs: %s", err.Error())
 	}
 
-	if g.targetSpec.TargetSpecs.QueueUrl != "" {
+	if g.specs.QueueUrl != "" {
 		if _, err := queue.GetQueueAttributes(&sqs.GetQueueAttributesInput{
-			QueueUrl: &g.targetSpec.TargetSpecs.QueueUrl,
+			QueueUrl: &g.specs.QueueUrl,
 		}); err != nil {
-			return errors.Errorf("queue %s not found: %s", g.targetSpec.TargetSpecs.Queue, err.Error())
+			return errors.Errorf("queue %s not found: %s", g.specs.Queue, err.Error())
 		}
 
 		return nil
 	}
 
-	if g.targetSpec.TargetSpecs.Queue != "" {
+	if g.specs.Queue != "" {
 		queueOutPut, err := queue.GetQueueUrl(&sqs.GetQueueUrlInput{
-			QueueName: &g.targetSpec.TargetSpecs.Queue,
+			QueueName: &g.specs.Queue,
 		})
 
 		if err != nil {
-			return errors.Errorf("queue %s not found: %s", g.targetSpec.TargetSpecs.Queue, err.Error())
+			return errors.Errorf("queue %s not found: %s", g.specs.Queue, err.Error())
 		}
 
-		g.targetSpec.TargetSpecs.QueueUrl = *queueOutPut.QueueUrl
+		g.specs.QueueUrl = *queueOutPut.QueueUrl
 	}
 
 	return nil
@@ -134,17 +96,11 @@ func (g *sqsTarget) Attach(_ string, data map[string]interface{}) error {
 		return errors.Errorf("failed to serialize payload: %s", err.Error())
 	}
 
-	buffer := new(bytes.Buffer)
-	if err := json.Compact(buffer, payload); err != nil {
-		zap.S().Warnf("failed to compact json: %s", err.Error())
-	}
+	payload = compactPayload(payload)
+	g.bufferLen += uint64(len(payload))
+	g.queue.PushBack(string(payload))
 
-	payload = buffer.Bytes()
-	g.bufferLen += uint64(buffer.Len())
-	g.bufferLen += uint64(len([]byte(g.targetSpec.TargetSpecs.LineBreak)))
-	g.queue.PushBack(payload)
-
-	zap.S().Debugf("buffer length: %s", lenReadable(g.bufferLen, 2))
+	zap.S().Debugf("buffer length: %s", LenReadable(g.bufferLen, 2))
 
 	return nil
 }
@@ -153,12 +109,12 @@ func (g *sqsTarget) CanFlush() bool {
 	g.Lock()
 	defer g.Unlock()
 
-	if g.targetSpec.TargetSpecs.BufferSize > 0 {
-		return g.bufferLen >= g.targetSpec.TargetSpecs.BufferSize
+	if g.specs.BufferSize > 0 {
+		return g.bufferLen >= g.specs.BufferSize
 	}
 
-	if g.targetSpec.TargetSpecs.BatchSize > 0 {
-		return g.queue.Len() >= g.targetSpec.TargetSpecs.BatchSize
+	if g.specs.BatchSize > 0 {
+		return g.queue.Len() >= g.specs.BatchSize
 	}
 
 	return true
@@ -170,15 +126,13 @@ func (g *sqsTarget) Flush() error {
 
 	start := time.Now()
 
-	queue := sqs.New(g.session)
-
 	entries := make([]*sqs.SendMessageBatchRequestEntry, 0)
 	elementLen := g.queue.Len()
 	for i := 0; i <= elementLen; i++ {
 		if element := g.queue.Front(); element != nil {
 			if content, ok := element.Value.(string); ok {
 				entries = append(entries, &sqs.SendMessageBatchRequestEntry{
-					DelaySeconds: &g.targetSpec.TargetSpecs.DelaySeconds,
+					DelaySeconds: &g.specs.DelaySeconds,
 					MessageBody:  &content,
 				})
 			}
@@ -186,10 +140,14 @@ func (g *sqsTarget) Flush() error {
 		}
 	}
 
+	if len(entries) == 0 {
+		return nil
+	}
+
 	elapsed := time.Since(start)
-	if _, err := queue.SendMessageBatch(&sqs.SendMessageBatchInput{
+	if _, err := g.client.SendMessageBatch(&sqs.SendMessageBatchInput{
 		Entries:  entries,
-		QueueUrl: &g.targetSpec.TargetSpecs.QueueUrl,
+		QueueUrl: &g.specs.QueueUrl,
 	}); err != nil {
 		return errors.Errorf("failed to send events, elapsed time: %s, error: %s", elapsed, err.Error())
 	}
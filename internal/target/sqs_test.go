@@ -0,0 +1,156 @@
+package target
+
+import (
+	"container/list"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	codec "draethos.io.com/internal/codec"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/pkg/streams/specs"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type sqsClientStub struct {
+	sqsiface.SQSAPI
+	bodies []string
+}
+
+func (s *sqsClientStub) SendMessageBatch(input *sqs.SendMessageBatchInput) (*sqs.SendMessageBatchOutput, error) {
+	for _, entry := range input.Entries {
+		s.bodies = append(s.bodies, *entry.MessageBody)
+	}
+
+	return &sqs.SendMessageBatchOutput{}, nil
+}
+
+func newSqsTargetStub(t *testing.T, codec interfaces.CodecInterface) (*sqsTarget, *sqsClientStub) {
+	t.Helper()
+
+	client := &sqsClientStub{}
+	target := &sqsTarget{
+		targetSpec: specs.Target{Type: "sqs"},
+		specs:      specs.SqsTargetSpecs{QueueUrl: "https://sqs.us-east-1.amazonaws.com/000000000000/events"},
+		codec:      codec,
+		queue:      list.New(),
+		client:     client,
+	}
+
+	return target, client
+}
+
+// sendThrough attaches the events and flushes them at once.
+func sendThrough(t *testing.T, target interfaces.TargetInterface, events ...map[string]interface{}) {
+	t.Helper()
+
+	for _, event := range events {
+		if err := target.Attach("key", event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := target.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShouldSendCompactedJsonToSqs(t *testing.T) {
+	target, client := newSqsTargetStub(t, codec.NewJsonCodec())
+	sendThrough(t, target, map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2})
+
+	expected := []string{`{"id":1}`, `{"id":2}`}
+	if !reflect.DeepEqual(client.bodies, expected) {
+		t.Errorf("expected %q, got %q", expected, client.bodies)
+	}
+
+	if target.queue.Len() != 0 {
+		t.Errorf("expected queue to be empty, got %d events", target.queue.Len())
+	}
+}
+
+func TestShouldSendMsgpackToSqsAsBase64(t *testing.T) {
+	chain := codec.NewChainCodec([]interfaces.EncodingInterface{codec.NewBase64Encoding()}, codec.NewMsgpackCodec())
+	target, client := newSqsTargetStub(t, chain)
+
+	event := map[string]interface{}{"id": int64(7), "name": "sensor"}
+	sendThrough(t, target, event)
+
+	if len(client.bodies) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(client.bodies))
+	}
+
+	decoded, err := chain.Deserialize([]byte(client.bodies[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, event) {
+		t.Errorf("expected %#v, got %#v", event, decoded)
+	}
+}
+
+func TestShouldNotSendEmptySqsBatches(t *testing.T) {
+	target, client := newSqsTargetStub(t, codec.NewJsonCodec())
+	if err := target.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.bodies != nil {
+		t.Errorf("expected no message, got %q", client.bodies)
+	}
+}
+
+func TestShouldSendProtobufToSqsAsBase64(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("sensor/v1/reading.proto"),
+		Package: proto.String("sensor.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Reading"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("id"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: optional},
+				{Name: proto.String("value"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_DOUBLE.Enum(), Label: optional},
+			},
+		}},
+	}}}
+
+	content, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "reading.pb")
+	if err = ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	protobuf, err := codec.NewProtobufCodec(map[string]interface{}{"descriptor.file": path, "message": "sensor.v1.Reading"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := codec.NewChainCodec([]interfaces.EncodingInterface{codec.NewBase64Encoding()}, protobuf)
+	target, client := newSqsTargetStub(t, chain)
+
+	event := map[string]interface{}{"id": "s-1", "value": 21.5}
+	sendThrough(t, target, event)
+
+	if len(client.bodies) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(client.bodies))
+	}
+
+	decoded, err := chain.Deserialize([]byte(client.bodies[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, event) {
+		t.Errorf("expected %#v, got %#v", event, decoded)
+	}
+}
@@ -0,0 +1,44 @@
+package target
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TopicPlaceholder is replaced by the topic an event was consumed from, as
+// written by the kafka source to its topicField.
+const TopicPlaceholder = "%{topic}"
+
+// identifierInvalid matches characters not allowed in unquoted sql
+// identifiers, such as the dots of orders.created.
+var identifierInvalid = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// eventTopic returns the topic of the event when the value refers to
+// %{topic}, and an empty topic otherwise.
+func eventTopic(value string, topicField string, data map[string]interface{}) (string, error) {
+	if !strings.Contains(value, TopicPlaceholder) {
+		return "", nil
+	}
+
+	topic, ok := data[topicField].(string)
+	if !ok || topic == "" {
+		return "", errors.Errorf("event field %s holding the topic for %s not found", topicField, TopicPlaceholder)
+	}
+
+	return topic, nil
+}
+
+// topicTable replaces %{topic} in the table name, characters not allowed in
+// table names become underscores.
+func topicTable(table string, topic string) string {
+	return strings.ReplaceAll(table, TopicPlaceholder, identifierInvalid.ReplaceAllString(topic, "_"))
+}
+
+// databaseRow is an event queued by the sql targets along with the table it
+// is written to.
+type databaseRow struct {
+	table string
+	data  map[string]interface{}
+}
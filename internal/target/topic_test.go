@@ -0,0 +1,80 @@
+package target
+
+import (
+	"container/list"
+	"strings"
+	"testing"
+
+	codec "draethos.io.com/internal/codec"
+	"draethos.io.com/pkg/streams/specs"
+)
+
+func TestShouldWriteEventsToTableOfTheirTopic(t *testing.T) {
+	pgsql := &pgsqlTarget{
+		specs:   specs.DatabaseTargetSpecs{Table: "events_%{topic}", KeyColumnName: "id", TopicField: "topic"},
+		queue:   list.New(),
+		columns: map[string]map[string]bool{},
+	}
+
+	if err := pgsql.Attach("o-1", map[string]interface{}{"topic": "orders.created", "amount": 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pgsql.Attach("o-2", map[string]interface{}{"amount": 10}); err == nil || !strings.Contains(err.Error(), "event field topic") {
+		t.Errorf("expected missing topic error, got %v", err)
+	}
+
+	var commands strings.Builder
+	if err := pgsql.buildCommands(&commands, *pgsql.queue.Front()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, command := range []string{
+		"CREATE TABLE IF NOT EXISTS events_orders_created",
+		"ALTER TABLE events_orders_created ADD COLUMN IF NOT EXISTS \"amount\"",
+		"INSERT INTO events_orders_created",
+	} {
+		if !strings.Contains(commands.String(), command) {
+			t.Errorf("expected %q in %s", command, commands.String())
+		}
+	}
+}
+
+func TestShouldUploadFilePerTopic(t *testing.T) {
+	target := &s3Target{
+		specs: specs.S3TargetSpecs{Prefix: "raw/%{topic}/", LineBreak: S3LineBreakDefault, TopicField: "source"},
+		codec: codec.NewJsonCodec(),
+		queue: list.New(),
+	}
+
+	for _, event := range []map[string]interface{}{
+		{"id": 1, "source": "orders"},
+		{"id": 2, "source": "payments"},
+		{"id": 3, "source": "orders"},
+	} {
+		if err := target.Attach("key", event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := target.files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"raw/orders/":   "{\"id\":1,\"source\":\"orders\"}\n{\"id\":3,\"source\":\"orders\"}\n",
+		"raw/payments/": "{\"id\":2,\"source\":\"payments\"}\n",
+	}
+
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(files))
+	}
+
+	for _, file := range files {
+		prefix := target.prefixFormatter(file.topic)
+		if string(file.content) != expected[prefix] {
+			t.Errorf("expected %s file %q, got %q", prefix, expected[prefix], file.content)
+		}
+	}
+}
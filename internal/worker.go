@@ -1,14 +1,19 @@
 package internal
 
 import (
+	"context"
 	context2 "draethos.io.com/internal/context"
+	"draethos.io.com/internal/interfaces"
+	"draethos.io.com/internal/logging"
 	"fmt"
 	"net/http"
+	"reflect"
 	"time"
 
 	"draethos.io.com/pkg/streams/specs"
 	"github.com/gorilla/mux"
 	"github.com/heptiolabs/healthcheck"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
@@ -21,7 +26,11 @@ type worker struct {
 	Err           error
 	configBuilder ConfigBuilder
 	router        *mux.Router
+	server        *http.Server
 	configSpec    specs.Stream
+	source        interfaces.SourceInterface
+	target        *reloadableTarget
+	dlq           *reloadableTarget
 }
 
 func NewWorker(configSpec specs.Stream,
@@ -29,64 +38,216 @@ func NewWorker(configSpec specs.Stream,
 	return &worker{
 		configSpec:    configSpec,
 		configBuilder: configBuilder,
-		router:        &mux.Router{},
 	}
 }
 
 func (s *worker) Start() error {
+	reloads := NewReloader(s.configBuilder).Watch()
+
+	for {
+		done, err := s.run()
+		if err != nil {
+			return err
+		}
+
+		restart := false
+		for !restart {
+			select {
+			case err = <-done:
+				s.close()
+				return err
+			case stream := <-reloads:
+				restart = s.reload(stream, done)
+			}
+		}
+	}
+}
+
+func (s *worker) run() (<-chan error, error) {
 	zap.S().Infof("initializing target: %v", s.configSpec.Stream.Instance.Target.Type)
 	target, err := context2.NewTargetContext(s.configSpec.Stream.Instance.Target)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	s.target = newReloadableTarget(target)
+
 	zap.S().Infof("initializing dlq context: %v", s.configSpec.Stream.Instance.Dlq.Type)
+	s.dlq = nil
 	dlq, err := context2.NewTargetContext(s.configSpec.Stream.Instance.Dlq)
 	if err != nil {
 		zap.S().Infof("dlq not defined: %v", err.Error())
+	} else {
+		s.dlq = newReloadableTarget(dlq)
 	}
 
+	s.router = &mux.Router{}
+
 	zap.S().Infof("initializing source context: %v", s.configSpec.Stream.Instance.Source.Type)
-	source, err := context2.NewSourceContext(s.configSpec, target, dlq, s.router, s.configSpec.Stream.Port)
+	source, err := context2.NewSourceContext(s.configSpec, s.target, s.dlqTarget(), s.router, s.httpPort())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	s.source = source
+
 	s.Setup()
 
 	zap.S().Debug("initializing worker")
 
-	return source.Worker()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.source.Worker()
+	}()
+
+	return done, nil
 }
 
-func (s *worker) Setup() {
-	if s.configBuilder.GetHttpPort() != "0" {
-		s.configSpec.Stream.Port = s.configBuilder.GetHttpPort()
+// reload applies a new pipeline definition. When only the target or dlq changed
+// they are swapped in place, keeping the current pipeline when the swap fails,
+// otherwise the running source is stopped and the caller is told to start it
+// again.
+func (s *worker) reload(stream specs.Stream, done <-chan error) bool {
+	current, next := s.configSpec.Stream, stream.Stream
+	if reflect.DeepEqual(current, next) {
+		zap.S().Infof("pipeline unchanged, nothing to reload")
+		return false
+	}
+
+	// the logger carries the pipeline name and connector types as fields
+	if current.Name != next.Name || !reflect.DeepEqual(current.Logging, next.Logging) ||
+		current.Instance.Source.Type != next.Instance.Source.Type || current.Instance.Target.Type != next.Instance.Target.Type {
+		if err := logging.Configure(stream, s.configBuilder.GetFile()); err != nil {
+			zap.S().Errorf("failed to reconfigure logging, keeping current logger: %s", err.Error())
+		} else {
+			zap.S().Infof("logging reconfigured")
+		}
+	}
+
+	current.Name, next.Name = "", ""
+	current.Logging, next.Logging = specs.Logging{}, specs.Logging{}
+	if reflect.DeepEqual(current, next) {
+		s.configSpec = stream
+		return false
+	}
+
+	current.Instance.Target, next.Instance.Target = specs.Target{}, specs.Target{}
+	current.Instance.Dlq, next.Instance.Dlq = specs.Target{}, specs.Target{}
+	if reflect.DeepEqual(current, next) && (s.dlq == nil) == (stream.Stream.Instance.Dlq.Type == "") {
+		if err := s.swapTargets(stream); err != nil {
+			zap.S().Errorf("failed to reload targets, keeping current pipeline: %s", err.Error())
+			return false
+		}
+
+		s.configSpec = stream
+		return false
+	}
+
+	zap.S().Infof("pipeline changed, restarting source %s", s.configSpec.Stream.Instance.Source.Type)
+
+	s.source.Stop()
+	if err := <-done; err != nil {
+		zap.S().Errorf("failed to stop source: %s", err.Error())
+	}
+
+	s.close()
+	s.configSpec = stream
+
+	return true
+}
+
+// swapTargets replaces the target and the dlq that changed, both or none.
+func (s *worker) swapTargets(stream specs.Stream) error {
+	swaps := make([]targetSwap, 0, 2)
+
+	if !reflect.DeepEqual(s.configSpec.Stream.Instance.Target, stream.Stream.Instance.Target) {
+		zap.S().Infof("target changed, swapping target %s", stream.Stream.Instance.Target.Type)
+		target, err := context2.NewTargetContext(stream.Stream.Instance.Target)
+		if err != nil {
+			return errors.Errorf("failed to create target %s: %s", stream.Stream.Instance.Target.Type, err.Error())
+		}
+
+		swaps = append(swaps, targetSwap{current: s.target, next: target})
+	}
+
+	if s.dlq != nil && !reflect.DeepEqual(s.configSpec.Stream.Instance.Dlq, stream.Stream.Instance.Dlq) {
+		zap.S().Infof("dlq changed, swapping dlq %s", stream.Stream.Instance.Dlq.Type)
+		dlq, err := context2.NewTargetContext(stream.Stream.Instance.Dlq)
+		if err != nil {
+			return errors.Errorf("failed to create dlq %s: %s", stream.Stream.Instance.Dlq.Type, err.Error())
+		}
+
+		swaps = append(swaps, targetSwap{current: s.dlq, next: dlq})
+	}
+
+	return swapTargets(swaps)
+}
+
+func (s *worker) close() {
+	if s.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		if err := s.server.Shutdown(ctx); err != nil {
+			zap.S().Warnf("failed to shutdown server: %s", err.Error())
+		}
+
+		s.server = nil
+	}
+
+	if err := s.target.Close(); err != nil {
+		zap.S().Warnf("failed to close target: %s", err.Error())
 	}
 
+	if s.dlq != nil {
+		if err := s.dlq.Close(); err != nil {
+			zap.S().Warnf("failed to close dlq: %s", err.Error())
+		}
+	}
+}
+
+func (s *worker) dlqTarget() interfaces.TargetInterface {
+	if s.dlq == nil {
+		return nil
+	}
+
+	return s.dlq
+}
+
+func (s *worker) httpPort() string {
+	if port := s.configBuilder.GetHttpPort(); port != "" && port != "0" {
+		return port
+	}
+
+	return s.configSpec.Stream.Port
+}
+
+func (s *worker) Setup() {
+	port := s.httpPort()
+
 	if s.configBuilder.IsEnabledLiveness() {
 		s.initializeHealthCheck(s.configSpec.Stream.HealthCheck.Endpoint)
 		zap.S().Debugf("initialize endpoint liveness: http://localhost:%s%s",
-			s.configSpec.Stream.Port,
+			port,
 			s.configSpec.Stream.HealthCheck.Endpoint)
 	}
 
 	if s.configBuilder.IsEnabledMetrics() {
 		s.initializePrometheus(s.configSpec.Stream.Metrics.Endpoint)
 		zap.S().Debugf("initialize endpoint prometheus: http://localhost:%s%s",
-			s.configSpec.Stream.Port,
+			port,
 			s.configSpec.Stream.Metrics.Endpoint)
 	}
 
 	if s.configSpec.Stream.Instance.Source.Type != context2.HttpSource {
-		srv := &http.Server{
+		s.server = &http.Server{
 			Handler:      s.router,
-			Addr:         fmt.Sprintf("0.0.0.0:%s", s.configSpec.Stream.Port),
+			Addr:         fmt.Sprintf("0.0.0.0:%s", port),
 			WriteTimeout: 15 * time.Second,
 			ReadTimeout:  15 * time.Second,
 		}
 
-		go srv.ListenAndServe()
+		go s.server.ListenAndServe()
 	}
 }
 
@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"draethos.io.com/pkg/streams/specs"
+)
+
+type stubSource struct {
+	stopped int
+}
+
+func (s *stubSource) Worker() error { return nil }
+func (s *stubSource) Stop()         { s.stopped++ }
+
+type stubTarget struct {
+	flushes int
+	closed  bool
+}
+
+func (s *stubTarget) Initialize() error                           { return nil }
+func (s *stubTarget) Attach(string, map[string]interface{}) error { return nil }
+func (s *stubTarget) CanFlush() bool                              { return false }
+func (s *stubTarget) Close() error                                { s.closed = true; return nil }
+
+func (s *stubTarget) Flush() error {
+	s.flushes++
+	return nil
+}
+
+func newStubWorker(t *testing.T, content string) (*worker, *stubSource, *stubTarget) {
+	source, target := &stubSource{}, &stubTarget{}
+
+	return &worker{
+		configSpec:    buildStream(t, content),
+		configBuilder: NewConfigBuilder(),
+		source:        source,
+		target:        newReloadableTarget(target),
+	}, source, target
+}
+
+func buildStream(t *testing.T, content string) specs.Stream {
+	pipeline := writeLayeringFile(t, t.TempDir(), "pipeline.yaml", content)
+
+	stream, err := NewConfigBuilder().SetFile(pipeline).Build()
+	if err != nil {
+		t.Fatalf("failed to build pipeline: %v", err)
+	}
+
+	return *stream
+}
+
+func TestShouldSwapTargetWhenOnlyTargetChanged(t *testing.T) {
+	worker, source, target := newStubWorker(t, WorkerPipelineTest)
+
+	done := make(chan error, 1)
+	restart := worker.reload(buildStream(t, strings.Replace(WorkerPipelineTest, "topic: orders", "topic: payments", 1)), done)
+	if restart {
+		t.Fatalf("expected target swap, got source restart")
+	}
+
+	if source.stopped != 0 {
+		t.Errorf("expected source to keep running, stopped %d times", source.stopped)
+	}
+
+	if target.flushes != 1 || !target.closed {
+		t.Errorf("expected current target flushed and closed, got %d flushes, closed %v", target.flushes, target.closed)
+	}
+
+	if worker.target.target == target {
+		t.Errorf("expected target to be replaced")
+	}
+	defer worker.target.Close()
+
+	topic := worker.configSpec.Stream.Instance.Target.TargetSpecs.Connector["topic"]
+	if topic != "payments" {
+		t.Errorf("expected pipeline with topic payments, got %v", topic)
+	}
+}
+
+func TestShouldKeepTargetsWhenDlqSwapFails(t *testing.T) {
+	worker, source, target := newStubWorker(t, WorkerDlqPipelineTest)
+	dlq := &stubTarget{}
+	worker.dlq = newReloadableTarget(dlq)
+
+	current := worker.configSpec
+	next := strings.Replace(WorkerDlqPipelineTest, "topic: orders", "topic: payments", 1)
+	next = strings.Replace(next, "compression.codec: gzip", "compression.codec: unknown", 1)
+
+	done := make(chan error, 1)
+	if worker.reload(buildStream(t, next), done) {
+		t.Fatalf("expected current pipeline to be kept, got source restart")
+	}
+
+	if source.stopped != 0 {
+		t.Errorf("expected source to keep running, stopped %d times", source.stopped)
+	}
+
+	if worker.target.target != target || worker.dlq.target != dlq {
+		t.Errorf("expected target and dlq to be kept")
+	}
+
+	if target.flushes != 0 || target.closed || dlq.flushes != 0 || dlq.closed {
+		t.Errorf("expected current targets untouched, got %+v and %+v", target, dlq)
+	}
+
+	if !reflect.DeepEqual(worker.configSpec, current) {
+		t.Errorf("expected current pipeline definition to be kept")
+	}
+}
+
+func TestShouldRestartSourceWhenSourceChanged(t *testing.T) {
+	worker, source, target := newStubWorker(t, WorkerPipelineTest)
+
+	done := make(chan error, 1)
+	done <- nil
+
+	restart := worker.reload(buildStream(t, strings.Replace(WorkerPipelineTest, "rate: 1", "rate: 2", 1)), done)
+	if !restart {
+		t.Fatalf("expected source restart")
+	}
+
+	if source.stopped != 1 || !target.closed {
+		t.Errorf("expected source stopped and target closed, got %d stops, closed %v", source.stopped, target.closed)
+	}
+
+	if rate := worker.configSpec.Stream.Instance.Source.SourceSpecs.Configurations["rate"]; rate != 2 {
+		t.Errorf("expected pipeline with rate 2, got %v", rate)
+	}
+}
+
+func TestShouldKeepPipelineWhenReloadedFileIsInvalid(t *testing.T) {
+	pipeline := writeLayeringFile(t, t.TempDir(), "pipeline.yaml", WorkerPipelineTest)
+	streams := NewReloader(NewConfigBuilder().SetFile(pipeline)).Watch()
+
+	if err := ioutil.WriteFile(pipeline, []byte("stream: ["), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", pipeline, err)
+	}
+
+	reload := func() {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			t.Fatalf("failed to send SIGHUP: %v", err)
+		}
+	}
+
+	reload()
+	select {
+	case stream := <-streams:
+		t.Fatalf("expected invalid file to be ignored, got %+v", stream)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	writeLayeringFile(t, filepath.Dir(pipeline), "pipeline.yaml", strings.Replace(WorkerPipelineTest, "rate: 1", "rate: 2", 1))
+
+	reload()
+	select {
+	case stream := <-streams:
+		if rate := stream.Stream.Instance.Source.SourceSpecs.Configurations["rate"]; rate != 2 {
+			t.Errorf("expected pipeline with rate 2, got %v", rate)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected valid file to be reloaded")
+	}
+}
+
+const WorkerPipelineTest = `stream:
+  name: orders
+  instance:
+    source:
+      type: generator
+      specs:
+        template:
+          id: "{{sequence}}"
+        configurations:
+          rate: 1
+    target:
+      type: kafka
+      specs:
+        topic: orders
+        batchSize: 100
+        configurations:
+          bootstrap.servers: localhost:9092
+`
+
+const WorkerDlqPipelineTest = WorkerPipelineTest + `    dlq:
+      type: kafka
+      specs:
+        topic: orders.dlq
+        configurations:
+          bootstrap.servers: localhost:9092
+          compression.codec: gzip
+`
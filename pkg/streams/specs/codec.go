@@ -0,0 +1,50 @@
+package specs
+
+import "strings"
+
+// CodecSeparator splits the layers of a codec chain, such as gzip|base64|json.
+const CodecSeparator = "|"
+
+// Codec names the codec of a source or target, optionally preceded by the
+// encodings payloads go through, written as a chain such as gzip|json or as a
+// list of names.
+type Codec string
+
+func (c *Codec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var chain []string
+	if err := unmarshal(&chain); err == nil {
+		*c = Codec(strings.Join(chain, CodecSeparator))
+		return nil
+	}
+
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+
+	*c = Codec(value)
+	return nil
+}
+
+// Chain lists the names of the chain in the order payloads are read, the
+// codec being the last one.
+func (c Codec) Chain() []string {
+	chain := make([]string, 0)
+	for _, name := range strings.Split(string(c), CodecSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			chain = append(chain, name)
+		}
+	}
+
+	return chain
+}
+
+// Format is the codec that ends the chain, empty when none is defined.
+func (c Codec) Format() string {
+	chain := c.Chain()
+	if len(chain) == 0 {
+		return ""
+	}
+
+	return chain[len(chain)-1]
+}
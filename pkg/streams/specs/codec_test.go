@@ -0,0 +1,31 @@
+package specs
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestShouldReadCodecChains(t *testing.T) {
+	var source Source
+	if err := yaml.Unmarshal([]byte("codec:\n  - gzip\n  - base64\n  - json\n"), &source); err != nil {
+		t.Fatal(err)
+	}
+
+	if source.Codec != "gzip|base64|json" {
+		t.Errorf("expected list joined as a chain, got %q", source.Codec)
+	}
+
+	if err := yaml.Unmarshal([]byte("codec: ' zstd | csv '\n"), &source); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(source.Codec.Chain(), []string{"zstd", "csv"}) || source.Codec.Format() != "csv" {
+		t.Errorf("unexpected chain %v ending with %q", source.Codec.Chain(), source.Codec.Format())
+	}
+
+	if Codec("").Format() != "" {
+		t.Errorf("expected empty codec to have no format")
+	}
+}
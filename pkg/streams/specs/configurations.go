@@ -0,0 +1,265 @@
+package specs
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const ConfigurationTag = "config"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// DecodeConfigurations copies the configurations map into the struct pointed by
+// out, using the "config" tag of each field as key. Fields keep their current
+// value when the key is not defined, so defaults must be set before decoding.
+// Durations accept strings such as "15s" or integers interpreted as seconds,
+// numbers and booleans accept their string representation.
+func DecodeConfigurations(configurations map[string]interface{}, out interface{}) error {
+	return decodeTagged(configurations, out, "configuration")
+}
+
+// DecodeSpecs decodes the specs of a connector the same way configurations
+// are decoded.
+func DecodeSpecs(specs map[string]interface{}, out interface{}) error {
+	return decodeTagged(specs, out, "spec")
+}
+
+func decodeTagged(configurations map[string]interface{}, out interface{}, name string) error {
+	value := reflect.ValueOf(out)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("%ss must be decoded into a struct pointer, got %T", name, out)
+	}
+
+	value = value.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		key := field.Tag.Get(ConfigurationTag)
+		if key == "" || key == "-" {
+			continue
+		}
+
+		raw, ok := configurations[key]
+		if !ok || raw == nil {
+			continue
+		}
+
+		if err := decodeValue(raw, value.Field(i)); err != nil {
+			return errors.Errorf("invalid %s %s: %s", name, key, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func decodeValue(raw interface{}, out reflect.Value) error {
+	if out.Type() == durationType {
+		duration, err := toDuration(raw)
+		if err != nil {
+			return err
+		}
+
+		out.SetInt(int64(duration))
+		return nil
+	}
+
+	switch out.Kind() {
+	case reflect.String:
+		switch v := raw.(type) {
+		case string:
+			out.SetString(v)
+		case int, int64, uint64, float64, bool:
+			out.SetString(fmt.Sprintf("%v", v))
+		default:
+			return errors.Errorf("expected string, got %T", raw)
+		}
+	case reflect.Bool:
+		switch v := raw.(type) {
+		case bool:
+			out.SetBool(v)
+		case string:
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return errors.Errorf("expected boolean, got %q", v)
+			}
+			out.SetBool(parsed)
+		default:
+			return errors.Errorf("expected boolean, got %T", raw)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, err := toInt64(raw)
+		if err != nil {
+			return err
+		}
+
+		if out.OverflowInt(integer) {
+			return errors.Errorf("value %d out of range", integer)
+		}
+		out.SetInt(integer)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		integer, err := toInt64(raw)
+		if err != nil {
+			return err
+		}
+
+		if integer < 0 || out.OverflowUint(uint64(integer)) {
+			return errors.Errorf("value %d out of range", integer)
+		}
+		out.SetUint(uint64(integer))
+	case reflect.Float32, reflect.Float64:
+		number, err := toFloat64(raw)
+		if err != nil {
+			return err
+		}
+		out.SetFloat(number)
+	case reflect.Slice:
+		if out.Type().Elem().Kind() != reflect.String {
+			return errors.Errorf("unsupported list of %s", out.Type().Elem().Kind())
+		}
+
+		items, err := toStrings(raw)
+		if err != nil {
+			return err
+		}
+		out.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		if out.Type().Key().Kind() != reflect.String {
+			return errors.Errorf("unsupported map of %s", out.Type().Key().Kind())
+		}
+
+		entries, ok := toMap(raw)
+		if !ok {
+			return errors.Errorf("expected object, got %T", raw)
+		}
+
+		decoded := reflect.MakeMapWithSize(out.Type(), len(entries))
+		for k, v := range entries {
+			item := reflect.New(out.Type().Elem()).Elem()
+			if err := decodeValue(v, item); err != nil {
+				return errors.Errorf("%s: %s", k, err.Error())
+			}
+			decoded.SetMapIndex(reflect.ValueOf(k), item)
+		}
+		out.Set(decoded)
+	case reflect.Interface:
+		out.Set(reflect.ValueOf(raw))
+	default:
+		return errors.Errorf("unsupported type %s", out.Kind())
+	}
+
+	return nil
+}
+
+func toDuration(raw interface{}) (time.Duration, error) {
+	switch v := raw.(type) {
+	case string:
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Duration(seconds) * time.Second, nil
+		}
+
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, errors.Errorf("expected duration such as \"15s\", got %q", v)
+		}
+
+		return duration, nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	case uint64:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	}
+
+	return 0, errors.Errorf("expected duration, got %T", raw)
+}
+
+func toInt64(raw interface{}) (int64, error) {
+	switch v := raw.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint64:
+		return int64(v), nil
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, errors.Errorf("expected integer, got %v", v)
+		}
+		return int64(v), nil
+	case string:
+		integer, err := strconv.ParseInt(strings.TrimSpace(v), 0, 64)
+		if err != nil {
+			return 0, errors.Errorf("expected integer, got %q", v)
+		}
+		return integer, nil
+	}
+
+	return 0, errors.Errorf("expected integer, got %T", raw)
+}
+
+func toFloat64(raw interface{}) (float64, error) {
+	switch v := raw.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, errors.Errorf("expected number, got %q", v)
+		}
+		return number, nil
+	}
+
+	return 0, errors.Errorf("expected number, got %T", raw)
+}
+
+func toStrings(raw interface{}) ([]string, error) {
+	switch v := raw.(type) {
+	case string:
+		items := make([]string, 0)
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+		return items, nil
+	case []string:
+		return v, nil
+	}
+
+	return nil, errors.Errorf("expected list, got %T", raw)
+}
+
+func toMap(raw interface{}) (map[string]interface{}, bool) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		entries := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			entries[fmt.Sprintf("%v", key)] = value
+		}
+		return entries, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,60 @@
+package specs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type configurationsTest struct {
+	Host         string        `config:"host"`
+	Port         int           `config:"port"`
+	Enabled      bool          `config:"enabled"`
+	WriteTimeout time.Duration `config:"writeTimeout"`
+	ReadTimeout  time.Duration `config:"readTimeout"`
+	IdleTimeout  time.Duration `config:"idleTimeout"`
+	Columns      []string      `config:"columns"`
+}
+
+func TestShouldDecodeConfigurationsWithDefaults(t *testing.T) {
+	config := configurationsTest{Port: 5432, IdleTimeout: time.Minute}
+
+	err := DecodeConfigurations(map[string]interface{}{
+		"host":         "127.0.0.1",
+		"enabled":      "true",
+		"writeTimeout": 5,
+		"readTimeout":  "1m30s",
+		"columns":      []interface{}{"id", "name"},
+		"ignored":      "value",
+	}, &config)
+
+	if err != nil {
+		t.Fatalf("failed to decode configurations: %v", err)
+	}
+
+	if config.Host != "127.0.0.1" || config.Port != 5432 || !config.Enabled {
+		t.Errorf("failed to decode scalars: %+v", config)
+	}
+
+	if config.WriteTimeout != 5*time.Second || config.ReadTimeout != 90*time.Second || config.IdleTimeout != time.Minute {
+		t.Errorf("failed to decode durations: %+v", config)
+	}
+
+	if strings.Join(config.Columns, ",") != "id,name" {
+		t.Errorf("failed to decode list: %+v", config.Columns)
+	}
+}
+
+func TestShouldNameOffendingConfigurationKey(t *testing.T) {
+	var config configurationsTest
+
+	err := DecodeConfigurations(map[string]interface{}{"readTimeout": "soon"}, &config)
+	if err == nil || !strings.Contains(err.Error(), "invalid configuration readTimeout") {
+		t.Errorf("expected error naming readTimeout, got: %v", err)
+	}
+
+	err = DecodeConfigurations(map[string]interface{}{"port": "abc"}, &config)
+	if err == nil || !strings.Contains(err.Error(), "invalid configuration port") {
+		t.Errorf("expected error naming port, got: %v", err)
+	}
+}
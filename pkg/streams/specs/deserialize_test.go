@@ -34,7 +34,12 @@ func TestShouldDeserializeYamlWithSuccessful(t *testing.T) {
 		t.Errorf("failed to deserialize [Instances.Target.Type]")
 	}
 
-	if data.Stream.Instance.Target.TargetSpecs.Bucket != "topic_test_1" {
+	s3Specs, err := data.Stream.Instance.Target.TargetSpecs.S3()
+	if err != nil {
+		t.Errorf("failed to decode s3 specs: %v", err)
+	}
+
+	if s3Specs.Bucket != "topic_test_1" {
 		t.Errorf("failed to deserialize [Instances.Target.TargetSpecs.Bucket]")
 	}
 
@@ -42,11 +47,11 @@ func TestShouldDeserializeYamlWithSuccessful(t *testing.T) {
 		t.Errorf("failed to deserialize [Instances.Target.TargetSpecs.Bucket]")
 	}
 
-	if data.Stream.Instance.Target.TargetSpecs.Prefix != "/topic_test_1/year=%{YEAR}/month=%{MONTH}/day=%{DAY}/hour=%{HOUR}/" {
+	if s3Specs.Prefix != "/topic_test_1/year=%{YEAR}/month=%{MONTH}/day=%{DAY}/hour=%{HOUR}/" {
 		t.Errorf("failed to deserialize [Instances.Target.TargetSpecs.Bucket]")
 	}
 
-	if data.Stream.Instance.Target.TargetSpecs.BatchSize != 1000 {
+	if s3Specs.BatchSize != 1000 {
 		t.Errorf("failed to deserialize [Instances.Target.TargetSpecs.Bucket]")
 	}
 }
@@ -58,34 +63,34 @@ const (
     endpoint: /health
   metrics:
     endpoint: /metrics
-  instances:
-    - source:
-        type: kafka
-        specs:
-          topic: topic_test_1
-          configurations:
-            groupId: '${KAFKA_GROUP_ID}'
-            bootstrapServers: '${KAFKA_BOOTSTRAP_SERVERS}'
-            autoOffsetReset: 'beginning'
-            autoCreate: true
-            numPartitions: 5
-            numReplicationFactor: 1
-      target:
-        type: gcloudstorage
-        specs:
-          bucket: topic_test_1
-          prefix: '/topic_test_1/year=%{YEAR}/month=%{MONTH}/day=%{DAY}/hour=%{HOUR}/'
-          codec: jsonl
-          batchSize: 1000
-          flushInMilliseconds: 100000
-      dlq:
-        type: kafka
-        specs:
-          topic: topic_test_1_dlq
-          configurations:
-            bootstrapServers: '${KAFKA_BOOTSTRAP_SERVERS}'
-            autoCreate: true
-            numPartitions: 5
-            numReplicationFactor: 1
+  instance:
+    source:
+      type: kafka
+      specs:
+        topic: topic_test_1
+        configurations:
+          groupId: '${KAFKA_GROUP_ID}'
+          bootstrapServers: '${KAFKA_BOOTSTRAP_SERVERS}'
+          autoOffsetReset: 'beginning'
+          autoCreate: true
+          numPartitions: 5
+          numReplicationFactor: 1
+    target:
+      type: gcloudstorage
+      specs:
+        bucket: topic_test_1
+        prefix: '/topic_test_1/year=%{YEAR}/month=%{MONTH}/day=%{DAY}/hour=%{HOUR}/'
+        codec: jsonl
+        batchSize: 1000
+        flushInMilliseconds: 100000
+    dlq:
+      type: kafka
+      specs:
+        topic: topic_test_1_dlq
+        configurations:
+          bootstrapServers: '${KAFKA_BOOTSTRAP_SERVERS}'
+          autoCreate: true
+          numPartitions: 5
+          numReplicationFactor: 1
 `
 )
@@ -5,9 +5,11 @@ type Stream struct {
 }
 
 type Base struct {
+	Name        string      `yaml:"name,omitempty"`
 	Port        string      `yaml:"port"`
 	HealthCheck HealthCheck `yaml:"healthCheck"`
 	Metrics     Metrics     `yaml:"metrics"`
+	Logging     Logging     `yaml:"logging,omitempty"`
 	Instance    Instance    `yaml:"instance"`
 }
 
@@ -18,9 +20,10 @@ type Instance struct {
 }
 
 type Source struct {
-	Type        string      `yaml:"type,omitempty"`
-	Codec       string      `yaml:"codec,omitempty"`
-	SourceSpecs SourceSpecs `yaml:"specs,omitempty"`
+	Type                string                 `yaml:"type,omitempty"`
+	Codec               Codec                  `yaml:"codec,omitempty"`
+	CodecConfigurations map[string]interface{} `yaml:"codecConfigurations,omitempty"`
+	SourceSpecs         SourceSpecs            `yaml:"specs,omitempty"`
 }
 
 type Target struct {
@@ -30,30 +33,28 @@ type Target struct {
 
 type SourceSpecs struct {
 	Topic          string                 `yaml:"topic,omitempty"`
+	TopicPattern   string                 `yaml:"topicPattern,omitempty"`
+	TopicField     string                 `yaml:"topicField,omitempty"`
 	TimeoutMs      int                    `yaml:"timeoutMs,omitempty"`
+	Workers        int                    `yaml:"workers,omitempty"`
+	DispatchBy     string                 `yaml:"dispatchBy,omitempty"`
+	StartAt        string                 `yaml:"startAt,omitempty"`
+	StopAt         string                 `yaml:"stopAt,omitempty"`
+	Offsets        map[string]int64       `yaml:"offsets,omitempty"`
 	Endpoint       string                 `yaml:"endpoint,omitempty"`
 	Method         string                 `yaml:"method,omitempty"`
 	Path           string                 `yaml:"path,omitempty"`
+	Template       map[string]interface{} `yaml:"template,omitempty"`
 	Configurations map[string]interface{} `yaml:"configurations,omitempty"`
 }
 
+// TargetSpecs holds the specs shared by every target, the other keys belong to
+// the target type and are decoded into its own specs, such as S3TargetSpecs.
 type TargetSpecs struct {
-	Database            string                 `yaml:"database,omitempty"`
-	Table               string                 `yaml:"table,omitempty"`
-	KeyColumnName       string                 `yaml:"keyColumnName,omitempty"`
-	Topic               string                 `yaml:"topic,omitempty"`
-	Queue               string                 `yaml:"queue,omitempty"`
-	QueueUrl            string                 `yaml:"queueUrl,omitempty"`
-	TopicArn            string                 `yaml:"topicArn,omitempty"`
-	Bucket              string                 `yaml:"bucket,omitempty"`
-	Prefix              string                 `yaml:"prefix,omitempty"`
-	Codec               string                 `yaml:"codec,omitempty"`
-	BatchSize           int                    `yaml:"batchSize,omitempty"`
-	BufferSize          uint64                 `yaml:"bufferSize,omitempty"`
-	LineBreak           string                 `yaml:"lineBreak,omitempty"`
-	FlushInMilliseconds int                    `yaml:"flushInMilliseconds,omitempty"`
-	DelaySeconds        int64                  `yaml:"delaySeconds,omitempty"`
+	Codec               Codec                  `yaml:"codec,omitempty"`
+	CodecConfigurations map[string]interface{} `yaml:"codecConfigurations,omitempty"`
 	Configurations      map[string]interface{} `yaml:"configurations,omitempty"`
+	Connector           map[string]interface{} `yaml:",inline"`
 }
 
 type HealthCheck struct {
@@ -63,3 +64,16 @@ type HealthCheck struct {
 type Metrics struct {
 	Endpoint string `yaml:"endpoint,omitempty"`
 }
+
+type Logging struct {
+	Level    string            `yaml:"level,omitempty"`
+	Format   string            `yaml:"format,omitempty"`
+	Payload  string            `yaml:"payload,omitempty"`
+	Sampling Sampling          `yaml:"sampling,omitempty"`
+	Fields   map[string]string `yaml:"fields,omitempty"`
+}
+
+type Sampling struct {
+	Initial    int `yaml:"initial,omitempty"`
+	Thereafter int `yaml:"thereafter,omitempty"`
+}
@@ -0,0 +1,114 @@
+package specs
+
+const (
+	LineBreakDefault     = "\n"
+	KeyColumnNameDefault = "id"
+	TopicFieldDefault    = "topic"
+)
+
+type KafkaTargetSpecs struct {
+	Topic     string `config:"topic"`
+	BatchSize int    `config:"batchSize"`
+}
+
+type S3TargetSpecs struct {
+	Bucket     string `config:"bucket"`
+	Prefix     string `config:"prefix"`
+	BatchSize  int    `config:"batchSize"`
+	BufferSize uint64 `config:"bufferSize"`
+	LineBreak  string `config:"lineBreak"`
+	TopicField string `config:"topicField"`
+}
+
+type SqsTargetSpecs struct {
+	Queue        string `config:"queue"`
+	QueueUrl     string `config:"queueUrl"`
+	BatchSize    int    `config:"batchSize"`
+	BufferSize   uint64 `config:"bufferSize"`
+	DelaySeconds int64  `config:"delaySeconds"`
+}
+
+type SnsTargetSpecs struct {
+	TopicArn   string `config:"topicArn"`
+	BatchSize  int    `config:"batchSize"`
+	BufferSize uint64 `config:"bufferSize"`
+}
+
+// DatabaseTargetSpecs are the specs of the pgsql and mysql targets.
+type DatabaseTargetSpecs struct {
+	Database      string `config:"database"`
+	Table         string `config:"table"`
+	KeyColumnName string `config:"keyColumnName"`
+	BatchSize     int    `config:"batchSize"`
+	TopicField    string `config:"topicField"`
+}
+
+// Kafka decodes the specs of the kafka target.
+func (t TargetSpecs) Kafka() (KafkaTargetSpecs, error) {
+	var specs KafkaTargetSpecs
+	if err := DecodeSpecs(t.Connector, &specs); err != nil {
+		return specs, err
+	}
+
+	return specs, nil
+}
+
+// S3 decodes the specs of the s3 target, files are joined by line breaks
+// unless lineBreak is set and %{topic} is read from the topic field unless
+// topicField is set.
+func (t TargetSpecs) S3() (S3TargetSpecs, error) {
+	specs := S3TargetSpecs{LineBreak: LineBreakDefault, TopicField: TopicFieldDefault}
+	if err := DecodeSpecs(t.Connector, &specs); err != nil {
+		return specs, err
+	}
+
+	if specs.LineBreak == "" {
+		specs.LineBreak = LineBreakDefault
+	}
+
+	if specs.TopicField == "" {
+		specs.TopicField = TopicFieldDefault
+	}
+
+	return specs, nil
+}
+
+// Sqs decodes the specs of the sqs target.
+func (t TargetSpecs) Sqs() (SqsTargetSpecs, error) {
+	var specs SqsTargetSpecs
+	if err := DecodeSpecs(t.Connector, &specs); err != nil {
+		return specs, err
+	}
+
+	return specs, nil
+}
+
+// Sns decodes the specs of the sns target.
+func (t TargetSpecs) Sns() (SnsTargetSpecs, error) {
+	var specs SnsTargetSpecs
+	if err := DecodeSpecs(t.Connector, &specs); err != nil {
+		return specs, err
+	}
+
+	return specs, nil
+}
+
+// Database decodes the specs of the pgsql and mysql targets, keys are stored
+// on the id column unless keyColumnName is set and %{topic} is read from the
+// topic field unless topicField is set.
+func (t TargetSpecs) Database() (DatabaseTargetSpecs, error) {
+	specs := DatabaseTargetSpecs{KeyColumnName: KeyColumnNameDefault, TopicField: TopicFieldDefault}
+	if err := DecodeSpecs(t.Connector, &specs); err != nil {
+		return specs, err
+	}
+
+	if specs.KeyColumnName == "" {
+		specs.KeyColumnName = KeyColumnNameDefault
+	}
+
+	if specs.TopicField == "" {
+		specs.TopicField = TopicFieldDefault
+	}
+
+	return specs, nil
+}
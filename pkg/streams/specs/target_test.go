@@ -0,0 +1,50 @@
+package specs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShouldDecodeTargetSpecsByType(t *testing.T) {
+	stream, err := StreamDeserialize([]byte(`stream:
+  instance:
+    target:
+      type: pgsql
+      specs:
+        database: draethos
+        table: orders
+        batchSize: "500"
+        codec: json
+        configurations:
+          host: localhost
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targetSpecs := stream.Stream.Instance.Target.TargetSpecs
+	if targetSpecs.Codec != "json" || targetSpecs.Configurations["host"] != "localhost" {
+		t.Errorf("failed to decode shared specs: %+v", targetSpecs)
+	}
+
+	database, err := targetSpecs.Database()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if database.Database != "draethos" || database.Table != "orders" || database.BatchSize != 500 || database.KeyColumnName != KeyColumnNameDefault {
+		t.Errorf("failed to decode database specs: %+v", database)
+	}
+
+	s3, err := TargetSpecs{}.S3()
+	if err != nil || s3.LineBreak != LineBreakDefault {
+		t.Errorf("expected default line break, got %q, %v", s3.LineBreak, err)
+	}
+}
+
+func TestShouldNameOffendingSpecKey(t *testing.T) {
+	_, err := TargetSpecs{Connector: map[string]interface{}{"delaySeconds": "soon"}}.Sqs()
+	if err == nil || !strings.Contains(err.Error(), "invalid spec delaySeconds") {
+		t.Errorf("expected error naming delaySeconds, got: %v", err)
+	}
+}
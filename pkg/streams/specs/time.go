@@ -0,0 +1,29 @@
+package specs
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ParseTime reads a point in time written as RFC3339, such as
+// "2006-01-02T15:04:05Z", as "now", or as a duration before now such as "2h"
+// or "now-2h".
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "now" {
+		return now, nil
+	}
+
+	if duration, err := time.ParseDuration(strings.TrimPrefix(value, "now-")); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid time %q, expected RFC3339 such as 2006-01-02T15:04:05Z or a duration ago such as 2h", value)
+	}
+
+	return parsed, nil
+}